@@ -2,4 +2,4 @@
 
 docker build -t observer_gateway:latest .
 
-# may want 'go get github.com/s-vvardenfell/observer/tracer, util, other pkgs'
\ No newline at end of file
+# sibling modules are replaced with ../ dirs, run 'GOWORK=off go mod vendor' after changing them
\ No newline at end of file
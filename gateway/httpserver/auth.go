@@ -0,0 +1,318 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	ScopeBooksRead  = "books:read"
+	ScopeBooksWrite = "books:write"
+	// ScopeAdmin allows operating the gateway itself, e.g. replacing exchange rates
+	ScopeAdmin = "admin"
+)
+
+// Principal is an authenticated client
+type Principal struct {
+	Subject string
+	Method  string // api_key or jwt
+	Scopes  []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type principalKey struct{}
+
+// principalContextKey is echo.Context key of principal subject, used in access logs
+const principalContextKey = "principal"
+
+// authResultContextKey is echo.Context key of authResult of the request
+const authResultContextKey = "auth.result"
+
+// authResult is kept for the request so that the rate limiter and the scope
+// checks after it verify credentials once
+type authResult struct {
+	auth      *Authenticator
+	principal *Principal
+	err       error
+}
+
+// PrincipalSubject returns principal of the request for logging, empty if there is none
+func PrincipalSubject(ctx echo.Context) string {
+	subject, _ := ctx.Get(principalContextKey).(string)
+	return subject
+}
+
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns nil for unauthenticated requests
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// AuthConfig lists accepted credentials, a JWT is checked with the key matching its alg
+type AuthConfig struct {
+	// ApiKeys maps X-Api-Key value to its principal
+	ApiKeys map[string]Principal
+	// HS256Secret verifies HS256 tokens, nil disables them
+	HS256Secret []byte
+	// RS256PublicKey verifies RS256 tokens, nil disables them
+	RS256PublicKey *rsa.PublicKey
+	// Issuer and Audience are checked when not empty
+	Issuer   string
+	Audience string
+}
+
+// jwtClaims carries scopes OAuth 2 style, as a space separated string
+type jwtClaims struct {
+	jwt.StandardClaims
+	Scope string `json:"scope"`
+}
+
+// Authenticator checks API keys and bearer JWTs and puts Principal in request context
+type Authenticator struct {
+	apiKeys  map[[sha256.Size]byte]Principal
+	config   AuthConfig
+	parser   *jwt.Parser
+	disabled bool
+	logger   *zerolog.Logger
+}
+
+func NewAuthenticator(config AuthConfig, logger *zerolog.Logger) *Authenticator {
+	auth := &Authenticator{
+		apiKeys: make(map[[sha256.Size]byte]Principal, len(config.ApiKeys)),
+		config:  config,
+		logger:  logger,
+	}
+
+	// keys are looked up by hash so lookup time does not depend on key bytes
+	for key, principal := range config.ApiKeys {
+		principal.Method = "api_key"
+		auth.apiKeys[sha256.Sum256([]byte(key))] = principal
+	}
+
+	methods := []string{}
+	if len(config.HS256Secret) > 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if config.RS256PublicKey != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	auth.parser = &jwt.Parser{ValidMethods: methods}
+
+	return auth
+}
+
+// NewDisabledAuthenticator lets every request through, for local development only
+func NewDisabledAuthenticator() *Authenticator {
+	return &Authenticator{disabled: true}
+}
+
+// Require returns route middleware rejecting requests without a principal
+// having scope, 401 for missing or bad credentials and 403 for missing scope
+func (auth *Authenticator) Require(scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			return auth.check(ctx, scope, next)
+		}
+	}
+}
+
+// RequireByMethod is Require with books:read for GET and HEAD and books:write otherwise
+func (auth *Authenticator) RequireByMethod(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		scope := ScopeBooksWrite
+		switch ctx.Request().Method {
+		case http.MethodGet, http.MethodHead:
+			scope = ScopeBooksRead
+		}
+
+		return auth.check(ctx, scope, next)
+	}
+}
+
+func (auth *Authenticator) check(ctx echo.Context, scope string, next echo.HandlerFunc) error {
+	if auth.disabled {
+		return next(ctx)
+	}
+
+	span := trace.SpanFromContext(ctx.Request().Context())
+
+	principal, err := auth.authenticateOnce(ctx)
+	if err != nil {
+		span.SetAttributes(attribute.String("auth.error", err.Error()))
+		auth.logger.Warn().Err(err).Str("path", ctx.Path()).Str("ip", ctx.RealIP()).Msg("authentication failed")
+
+		ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="observer"`)
+		apiErr := newApiError(http.StatusUnauthorized, "valid API key or bearer token is required")
+		apiErr.Internal = err
+		return apiErr
+	}
+
+	span.SetAttributes(
+		attribute.String("enduser.id", principal.Subject),
+		attribute.String("enduser.scope", strings.Join(principal.Scopes, " ")),
+		attribute.String("auth.method", principal.Method),
+	)
+
+	ctx.Set(principalContextKey, principal.Subject)
+	ctx.SetRequest(ctx.Request().WithContext(ContextWithPrincipal(ctx.Request().Context(), principal)))
+
+	if !principal.HasScope(scope) {
+		auth.logger.Warn().Str("principal", principal.Subject).Str("scope", scope).Str("path", ctx.Path()).
+			Msg("insufficient scope")
+
+		ctx.Response().Header().Set(echo.HeaderWWWAuthenticate,
+			fmt.Sprintf(`Bearer realm="observer", error="insufficient_scope", scope=%q`, scope))
+		return newApiError(http.StatusForbidden, "scope "+scope+" is required")
+	}
+
+	return next(ctx)
+}
+
+// identify is the principal of valid credentials of the request, nil if there
+// are none or auth is disabled
+func (auth *Authenticator) identify(ctx echo.Context) *Principal {
+	if auth == nil || auth.disabled {
+		return nil
+	}
+
+	principal, err := auth.authenticateOnce(ctx)
+	if err != nil {
+		return nil
+	}
+
+	return principal
+}
+
+// authenticateOnce authenticates the request of ctx, later calls of the request get the same result
+func (auth *Authenticator) authenticateOnce(ctx echo.Context) (*Principal, error) {
+	if res, ok := ctx.Get(authResultContextKey).(*authResult); ok && res.auth == auth {
+		return res.principal, res.err
+	}
+
+	principal, err := auth.authenticate(ctx.Request())
+	ctx.Set(authResultContextKey, &authResult{auth: auth, principal: principal, err: err})
+
+	return principal, err
+}
+
+func (auth *Authenticator) authenticate(req *http.Request) (*Principal, error) {
+	if key := req.Header.Get(HeaderApiKey); key != "" {
+		principal, ok := auth.apiKeys[sha256.Sum256([]byte(key))]
+		if !ok {
+			return nil, errors.New("unknown api key")
+		}
+		return &principal, nil
+	}
+
+	header := req.Header.Get(echo.HeaderAuthorization)
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, errors.New("no credentials")
+	}
+
+	return auth.parseToken(strings.TrimSpace(token))
+}
+
+func (auth *Authenticator) parseToken(raw string) (*Principal, error) {
+	claims := &jwtClaims{}
+
+	_, err := auth.parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		// parser checks alg against configured ones, keys are checked again so that
+		// a token is never verified with an empty secret
+		switch {
+		case token.Method == jwt.SigningMethodHS256 && len(auth.config.HS256Secret) > 0:
+			return auth.config.HS256Secret, nil
+		case token.Method == jwt.SigningMethodRS256 && auth.config.RS256PublicKey != nil:
+			return auth.config.RS256PublicKey, nil
+		}
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	// the parser checks exp only if it is there, a token without it would never expire
+	if claims.ExpiresAt == 0 {
+		return nil, errors.New("token has no exp claim")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("token has no sub claim")
+	}
+	if auth.config.Issuer != "" && !claims.VerifyIssuer(auth.config.Issuer, true) {
+		return nil, fmt.Errorf("unexpected token issuer %q", claims.Issuer)
+	}
+	if auth.config.Audience != "" && !claims.VerifyAudience(auth.config.Audience, true) {
+		return nil, fmt.Errorf("token is not issued for %q", auth.config.Audience)
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Method:  "jwt",
+		Scopes:  strings.Fields(claims.Scope),
+	}, nil
+}
+
+// PrincipalUnaryInterceptor forwards principal of the request context to storage
+func PrincipalUnaryInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption) error {
+	return invoker(withPrincipalMetadata(ctx), method, req, reply, cc, opts...)
+}
+
+// PrincipalStreamInterceptor is PrincipalUnaryInterceptor for streaming RPCs
+func PrincipalStreamInterceptor(
+	ctx context.Context,
+	desc *grpc.StreamDesc,
+	cc *grpc.ClientConn,
+	method string,
+	streamer grpc.Streamer,
+	opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withPrincipalMetadata(ctx), desc, cc, method, opts...)
+}
+
+// withPrincipalMetadata sets the principal keys instead of appending to them,
+// values already in the outgoing metadata do not come from the authenticator
+func withPrincipalMetadata(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Delete(storageservice.PrincipalHeader)
+	md.Delete(storageservice.PrincipalScopesHeader)
+
+	if principal := PrincipalFromContext(ctx); principal != nil {
+		md.Set(storageservice.PrincipalHeader, principal.Subject)
+		md.Set(storageservice.PrincipalScopesHeader, strings.Join(principal.Scopes, " "))
+	}
+
+	return metadata.NewOutgoingContext(ctx, md)
+}
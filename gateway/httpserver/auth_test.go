@@ -0,0 +1,150 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthenticator(t *testing.T) {
+	logger := zerolog.Nop()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("rsa.GenerateKey() error = %v", err)
+	}
+
+	secret := []byte("hs256-secret")
+	auth := NewAuthenticator(AuthConfig{
+		ApiKeys: map[string]Principal{
+			"reader-key": {Subject: "reporting", Scopes: []string{ScopeBooksRead}},
+		},
+		HS256Secret:    secret,
+		RS256PublicKey: &rsaKey.PublicKey,
+		Issuer:         "observer",
+	}, &logger)
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwtClaims) string {
+		t.Helper()
+
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString() error = %v", err)
+		}
+
+		return "Bearer " + token
+	}
+
+	claims := func(subject, scope string, expiresAt time.Time) jwtClaims {
+		c := jwtClaims{Scope: scope}
+		c.Subject, c.Issuer = subject, "observer"
+		if !expiresAt.IsZero() {
+			c.ExpiresAt = expiresAt.Unix()
+		}
+		return c
+	}
+
+	hour := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		header      string
+		value       string
+		want        int
+		wantSubject string
+	}{
+		{"no credentials", "", "", http.StatusUnauthorized, ""},
+		{"unknown api key", HeaderApiKey, "random", http.StatusUnauthorized, ""},
+		{"api key", HeaderApiKey, "reader-key", http.StatusOK, "reporting"},
+		{"not a bearer", echo.HeaderAuthorization, "Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+		{"malformed token", echo.HeaderAuthorization, "Bearer abc.def.ghi", http.StatusUnauthorized, ""},
+		{"hs256 token", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodHS256, secret, claims("alice", ScopeBooksRead, hour)), http.StatusOK, "alice"},
+		{"rs256 token", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodRS256, rsaKey, claims("bob", ScopeBooksRead+" "+ScopeBooksWrite, hour)), http.StatusOK, "bob"},
+		{"token of another key", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodRS256, otherRSAKey, claims("bob", ScopeBooksRead, hour)), http.StatusUnauthorized, ""},
+		{"wrong hs256 secret", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodHS256, []byte("other"), claims("alice", ScopeBooksRead, hour)), http.StatusUnauthorized, ""},
+		{"expired token", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodHS256, secret, claims("alice", ScopeBooksRead, time.Now().Add(-time.Minute))), http.StatusUnauthorized, ""},
+		{"token without exp", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodHS256, secret, claims("alice", ScopeBooksRead, time.Time{})), http.StatusUnauthorized, ""},
+		{"token without sub", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodHS256, secret, claims("", ScopeBooksRead, hour)), http.StatusUnauthorized, ""},
+		{"token of another issuer", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodHS256, secret, jwtClaims{Scope: ScopeBooksRead, StandardClaims: jwt.StandardClaims{
+				Subject: "alice", Issuer: "other", ExpiresAt: hour.Unix()}}), http.StatusUnauthorized, ""},
+		{"unsigned token", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claims("alice", ScopeBooksRead, hour)), http.StatusUnauthorized, ""},
+		{"missing scope", echo.HeaderAuthorization,
+			sign(jwt.SigningMethodHS256, secret, claims("alice", ScopeBooksWrite, hour)), http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.HTTPErrorHandler = (&HttpServer{logger: &logger}).ErrorHandler
+
+			// the principal reaches storage as metadata of the outgoing call
+			var forwarded metadata.MD
+			invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				forwarded, _ = metadata.FromOutgoingContext(ctx)
+				return nil
+			}
+
+			e.GET("/storage", func(ctx echo.Context) error {
+				// values put into the outgoing metadata before are replaced
+				forged := metadata.AppendToOutgoingContext(ctx.Request().Context(),
+					storageservice.PrincipalHeader, "someone-else",
+					storageservice.PrincipalScopesHeader, "admin")
+				if err := PrincipalUnaryInterceptor(forged, "", nil, nil, nil, invoker); err != nil {
+					return err
+				}
+				return ctx.NoContent(http.StatusOK)
+			}, auth.Require(ScopeBooksRead))
+
+			req := httptest.NewRequest(http.MethodGet, "/storage", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d %s, want %d", rec.Code, rec.Body, tt.want)
+			}
+
+			if tt.want == http.StatusUnauthorized && rec.Header().Get(echo.HeaderWWWAuthenticate) == "" {
+				t.Errorf("no %s header on 401", echo.HeaderWWWAuthenticate)
+			}
+
+			if tt.want != http.StatusOK {
+				return
+			}
+
+			if got := forwarded.Get(storageservice.PrincipalHeader); len(got) != 1 || got[0] != tt.wantSubject {
+				t.Errorf("forwarded %s = %v, want %s", storageservice.PrincipalHeader, got, tt.wantSubject)
+			}
+			if got := forwarded.Get(storageservice.PrincipalScopesHeader); len(got) != 1 || got[0] == "admin" {
+				t.Errorf("forwarded %s = %v, want scopes", storageservice.PrincipalScopesHeader, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+func (serv *HttpServer) GetAuthor(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"GetAuthor",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("id"),
+				Value: attribute.StringValue(id),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return newApiError(http.StatusBadRequest, "wrong id format")
+	}
+
+	resp, err := serv.storageClient.GetAuthor(distCtx, &storageservice.GetAuthorRequest{
+		Id: int32(idNum),
+	})
+
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+
+	return ctx.JSON(http.StatusOK, authorFromResponse(resp))
+}
+
+func (serv *HttpServer) ListAuthors(ctx echo.Context) error {
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"ListAuthors",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("query"),
+				Value: attribute.StringValue(ctx.QueryString()),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	req := &storageservice.ListAuthorsRequest{
+		Cursor: ctx.QueryParam("cursor"),
+	}
+
+	if ps := ctx.QueryParam("page_size"); ps != "" {
+		pageSize, err := strconv.Atoi(ps)
+		if err != nil {
+			return newApiError(http.StatusBadRequest, "wrong page_size format")
+		}
+		req.PageSize = int32(pageSize)
+	}
+
+	if name := ctx.QueryParam("name"); name != "" {
+		req.NameContains = &name
+	}
+
+	resp, err := serv.storageClient.ListAuthors(distCtx, req)
+
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+
+	authors := make([]Author, 0, len(resp.Authors))
+	for _, a := range resp.Authors {
+		authors = append(authors, authorFromResponse(a))
+	}
+
+	return ctx.JSON(http.StatusOK, AuthorList{
+		Authors:    authors,
+		NextCursor: resp.NextCursor,
+	})
+}
+
+func (serv *HttpServer) ListAuthorBooks(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"ListAuthorBooks",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("id"),
+				Value: attribute.StringValue(id),
+			},
+			attribute.KeyValue{
+				Key:   attribute.Key("query"),
+				Value: attribute.StringValue(ctx.QueryString()),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return newApiError(http.StatusBadRequest, "wrong id format")
+	}
+
+	// filter params are read too but only paging and sorting are used
+	list, err := listRequestFromQuery(ctx)
+	if err != nil {
+		return newApiError(http.StatusBadRequest, err.Error())
+	}
+
+	currency, err := currencyFromQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := serv.storageClient.ListAuthorBooks(distCtx, &storageservice.ListAuthorBooksRequest{
+		AuthorId:   int32(idNum),
+		PageSize:   list.PageSize,
+		Cursor:     list.Cursor,
+		SortBy:     list.SortBy,
+		Descending: list.Descending,
+	})
+
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+
+	books := make([]Book, 0, len(resp.Books))
+	for _, b := range resp.Books {
+		book, err := serv.quotedBook(b, currency)
+		if err != nil {
+			return err
+		}
+		books = append(books, book)
+	}
+
+	return ctx.JSON(http.StatusOK, BookList{
+		Books:      books,
+		NextCursor: resp.NextCursor,
+	})
+}
@@ -0,0 +1,279 @@
+package httpserver
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"google.golang.org/protobuf/proto"
+)
+
+// BookCache keeps storage responses of GetBookById, writes through the gateway
+// invalidate entries, writes made elsewhere are seen after the entry expires
+type BookCache interface {
+	Get(ctx context.Context, id int32) (*storageservice.GetValueResponse, bool)
+	// Generation is taken before the book is fetched from storage, Delete changes it
+	// so that Set of the book fetched before a write does not put it back
+	Generation(ctx context.Context, id int32) uint64
+	// Set keeps the book unless the entry has been deleted since gen was taken
+	Set(ctx context.Context, id int32, book *storageservice.GetValueResponse, gen uint64)
+	Delete(ctx context.Context, id int32)
+	// Enabled is false if the cache keeps nothing, invalidation needing
+	// storage requests is skipped then
+	Enabled() bool
+}
+
+// NoCache is BookCache which never hits
+type NoCache struct{}
+
+func (NoCache) Get(context.Context, int32) (*storageservice.GetValueResponse, bool) {
+	return nil, false
+}
+
+func (NoCache) Generation(context.Context, int32) uint64 { return 0 }
+
+func (NoCache) Set(context.Context, int32, *storageservice.GetValueResponse, uint64) {}
+
+func (NoCache) Delete(context.Context, int32) {}
+
+func (NoCache) Enabled() bool { return false }
+
+type lruEntry struct {
+	id        int32
+	book      *storageservice.GetValueResponse
+	expiresAt time.Time
+}
+
+// generations of LRUCache are kept per id modulo this, so that deleted ids
+// take no memory; a delete of another id in the slot only drops a Set
+const lruGenerations = 256
+
+// LRUCache is in-process BookCache evicting least recently used entries
+// when full and expired ones on access
+type LRUCache struct {
+	size        int
+	ttl         time.Duration
+	mutex       sync.Mutex
+	order       *list.List // front is most recently used
+	entries     map[int32]*list.Element
+	generations [lruGenerations]uint64
+}
+
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[int32]*list.Element, size),
+	}
+}
+
+func (c *LRUCache) Get(_ context.Context, id int32) (*storageservice.GetValueResponse, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false
+	}
+
+	c.order.MoveToFront(elem)
+	return entry.book, true
+}
+
+func (c *LRUCache) Generation(_ context.Context, id int32) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.generations[lruSlot(id)]
+}
+
+func (c *LRUCache) Set(_ context.Context, id int32, book *storageservice.GetValueResponse, gen uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// the book was fetched before a write deleted the entry
+	if c.generations[lruSlot(id)] != gen {
+		return
+	}
+
+	entry := &lruEntry{id: id, book: book, expiresAt: time.Now().Add(c.ttl)}
+
+	if elem, ok := c.entries[id]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[id] = c.order.PushFront(entry)
+
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRUCache) Delete(_ context.Context, id int32) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generations[lruSlot(id)]++
+
+	if elem, ok := c.entries[id]; ok {
+		c.remove(elem)
+	}
+}
+
+func (c *LRUCache) Enabled() bool { return true }
+
+func lruSlot(id int32) uint32 {
+	return uint32(id) % lruGenerations
+}
+
+func (c *LRUCache) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry).id)
+}
+
+// KVStore is an external key-value store such as Redis or Memcached,
+// a missing key is reported by ok == false rather than an error
+type KVStore interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// RemoteCache is BookCache over KVStore shared by gateway replicas,
+// store errors are logged and treated as misses so the cache never fails a request.
+// The gateway binary has no KVStore client and runs LRUCache, RemoteCache is for
+// programs serving HttpServer with a store of their own.
+// Generations are kept in the store next to the books; without compare-and-set
+// in KVStore a delete between the check and the write of Set is still possible,
+// such an entry is seen until it expires
+type RemoteCache struct {
+	store  KVStore
+	ttl    time.Duration
+	prefix string
+	logger *zerolog.Logger
+}
+
+func NewRemoteCache(store KVStore, ttl time.Duration, logger *zerolog.Logger) *RemoteCache {
+	return &RemoteCache{
+		store:  store,
+		ttl:    ttl,
+		prefix: "observer:book:",
+		logger: logger,
+	}
+}
+
+func (c *RemoteCache) Get(ctx context.Context, id int32) (*storageservice.GetValueResponse, bool) {
+	data, ok, err := c.store.Get(ctx, c.key(id))
+	if err != nil {
+		c.logger.Warn().Err(err).Int32("id", id).Msg("book cache get failed")
+		return nil, false
+	}
+	if !ok {
+		return nil, false
+	}
+
+	book := &storageservice.GetValueResponse{}
+	if err := proto.Unmarshal(data, book); err != nil {
+		c.logger.Warn().Err(err).Int32("id", id).Msg("malformed book cache entry")
+		return nil, false
+	}
+
+	return book, true
+}
+
+func (c *RemoteCache) Generation(ctx context.Context, id int32) uint64 {
+	gen, err := c.generation(ctx, id)
+	if err != nil {
+		c.logger.Warn().Err(err).Int32("id", id).Msg("book cache generation get failed")
+	}
+
+	return gen
+}
+
+func (c *RemoteCache) Set(ctx context.Context, id int32, book *storageservice.GetValueResponse, gen uint64) {
+	// a failed check is taken as a delete, the book is fetched again next time
+	current, err := c.generation(ctx, id)
+	if err != nil || current != gen {
+		return
+	}
+
+	data, err := proto.Marshal(book)
+	if err == nil {
+		err = c.store.Set(ctx, c.key(id), data, c.ttl)
+	}
+
+	if err != nil {
+		c.logger.Warn().Err(err).Int32("id", id).Msg("book cache set failed")
+	}
+}
+
+func (c *RemoteCache) Delete(ctx context.Context, id int32) {
+	// the generation outlives books set before it changed
+	gen := strconv.FormatInt(time.Now().UnixNano(), 10)
+	if err := c.store.Set(ctx, c.generationKey(id), []byte(gen), c.ttl); err != nil {
+		c.logger.Warn().Err(err).Int32("id", id).Msg("book cache generation set failed")
+	}
+
+	if err := c.store.Delete(ctx, c.key(id)); err != nil {
+		c.logger.Warn().Err(err).Int32("id", id).Msg("book cache delete failed")
+	}
+}
+
+func (c *RemoteCache) Enabled() bool { return true }
+
+// generation is 0 for books not deleted within ttl
+func (c *RemoteCache) generation(ctx context.Context, id int32) (uint64, error) {
+	data, ok, err := c.store.Get(ctx, c.generationKey(id))
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	return strconv.ParseUint(string(data), 10, 64)
+}
+
+func (c *RemoteCache) key(id int32) string {
+	return c.prefix + strconv.Itoa(int(id))
+}
+
+func (c *RemoteCache) generationKey(id int32) string {
+	return c.prefix + "gen:" + strconv.Itoa(int(id))
+}
+
+// invalidateAuthorBooks drops every cached book of the author as the author bio
+// is cached with each of them, books failed to be listed are seen after they expire
+func (serv *HttpServer) invalidateAuthorBooks(spanCtx, distCtx context.Context, authorID int32) {
+	if !serv.cache.Enabled() {
+		return
+	}
+
+	req := &storageservice.ListAuthorBooksRequest{AuthorId: authorID, PageSize: 100}
+	for {
+		resp, err := serv.storageClient.ListAuthorBooks(distCtx, req)
+		if err != nil {
+			serv.logger.Warn().Err(err).Int32("author_id", authorID).Msg("book cache invalidation failed")
+			return
+		}
+
+		for _, book := range resp.Books {
+			serv.cache.Delete(spanCtx, book.Id)
+		}
+
+		if resp.NextCursor == "" {
+			return
+		}
+		req.Cursor = resp.NextCursor
+	}
+}
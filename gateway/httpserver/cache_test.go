@@ -0,0 +1,362 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/zerolog"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestLRUCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(2, time.Minute)
+
+	for _, id := range []int32{1, 2} {
+		c.Set(ctx, id, &storageservice.GetValueResponse{Id: id}, c.Generation(ctx, id))
+	}
+
+	// 1 is used after 2, so 2 is evicted
+	if _, ok := c.Get(ctx, 1); !ok {
+		t.Fatalf("Get(1) missed")
+	}
+	c.Set(ctx, 3, &storageservice.GetValueResponse{Id: 3}, c.Generation(ctx, 3))
+
+	if _, ok := c.Get(ctx, 2); ok {
+		t.Errorf("Get(2) hit, want evicted")
+	}
+	for _, id := range []int32{1, 3} {
+		if book, ok := c.Get(ctx, id); !ok || book.Id != id {
+			t.Errorf("Get(%d) = %v, %t", id, book, ok)
+		}
+	}
+
+	// setting an entry again replaces it without evicting
+	c.Set(ctx, 3, &storageservice.GetValueResponse{Id: 3, Title: "New"}, c.Generation(ctx, 3))
+	if book, ok := c.Get(ctx, 3); !ok || book.Title != "New" {
+		t.Errorf("Get(3) = %v, %t, want the new entry", book, ok)
+	}
+	if _, ok := c.Get(ctx, 1); !ok {
+		t.Errorf("Get(1) missed after 3 was set again")
+	}
+
+	c.Delete(ctx, 1)
+	if _, ok := c.Get(ctx, 1); ok {
+		t.Errorf("Get(1) hit after Delete")
+	}
+}
+
+func TestLRUCacheGeneration(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(10, time.Minute)
+
+	// the book is fetched, then a write deletes the entry before it is set
+	gen := c.Generation(ctx, 1)
+	c.Delete(ctx, 1)
+	c.Set(ctx, 1, &storageservice.GetValueResponse{Id: 1, Title: "Stale"}, gen)
+
+	if book, ok := c.Get(ctx, 1); ok {
+		t.Fatalf("Get(1) = %v, want the stale book dropped", book)
+	}
+
+	// the next fetch is after the write
+	c.Set(ctx, 1, &storageservice.GetValueResponse{Id: 1, Title: "Fresh"}, c.Generation(ctx, 1))
+	if book, ok := c.Get(ctx, 1); !ok || book.Title != "Fresh" {
+		t.Fatalf("Get(1) = %v, %t, want the fresh book", book, ok)
+	}
+
+	// deletes of other ids keep generation
+	gen = c.Generation(ctx, 2)
+	c.Delete(ctx, 3)
+	c.Set(ctx, 2, &storageservice.GetValueResponse{Id: 2}, gen)
+	if _, ok := c.Get(ctx, 2); !ok {
+		t.Errorf("Get(2) missed after Delete(3)")
+	}
+
+	if !c.Enabled() || (NoCache{}).Enabled() {
+		t.Errorf("Enabled() = %t, NoCache %t", c.Enabled(), (NoCache{}).Enabled())
+	}
+}
+
+func TestLRUCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRUCache(2, 10*time.Millisecond)
+
+	c.Set(ctx, 1, &storageservice.GetValueResponse{Id: 1}, c.Generation(ctx, 1))
+	if _, ok := c.Get(ctx, 1); !ok {
+		t.Fatalf("Get(1) missed before expiry")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := c.Get(ctx, 1); ok {
+		t.Fatalf("Get(1) hit after expiry")
+	}
+	if c.order.Len() != 0 || len(c.entries) != 0 {
+		t.Errorf("expired entry is kept")
+	}
+}
+
+type fakeKVStore struct {
+	values map[string][]byte
+	ttl    time.Duration
+	err    error
+}
+
+func (s *fakeKVStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, ok := s.values[key]
+	return value, ok, s.err
+}
+
+func (s *fakeKVStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.values[key], s.ttl = value, ttl
+	return nil
+}
+
+func (s *fakeKVStore) Delete(_ context.Context, key string) error {
+	delete(s.values, key)
+	return s.err
+}
+
+func TestRemoteCache(t *testing.T) {
+	ctx := context.Background()
+	logger := zerolog.Nop()
+	store := &fakeKVStore{values: map[string][]byte{}}
+	c := NewRemoteCache(store, time.Minute, &logger)
+
+	book := &storageservice.GetValueResponse{Id: 7, Title: "Book", Author: "Author", Version: 2}
+	c.Set(ctx, 7, book, c.Generation(ctx, 7))
+
+	if _, ok := store.values["observer:book:7"]; !ok || store.ttl != time.Minute {
+		t.Fatalf("store = %v with ttl %s, want observer:book:7 for a minute", store.values, store.ttl)
+	}
+
+	got, ok := c.Get(ctx, 7)
+	if !ok || !proto.Equal(got, book) {
+		t.Fatalf("Get(7) = %v, %t, want %v", got, ok, book)
+	}
+
+	if _, ok := c.Get(ctx, 8); ok {
+		t.Errorf("Get(8) hit")
+	}
+
+	store.values["observer:book:9"] = []byte("not a book")
+	if _, ok := c.Get(ctx, 9); ok {
+		t.Errorf("Get(9) hit on malformed entry")
+	}
+
+	// store errors are misses
+	store.err = errors.New("connection refused")
+	if _, ok := c.Get(ctx, 7); ok {
+		t.Errorf("Get(7) hit on store error")
+	}
+	store.err = nil
+
+	// books fetched before a delete are not set, the generation is shared by replicas
+	gen := c.Generation(ctx, 7)
+	c.Delete(ctx, 7)
+	if _, ok := c.Get(ctx, 7); ok {
+		t.Errorf("Get(7) hit after Delete")
+	}
+	if _, ok := store.values["observer:book:gen:7"]; !ok || store.ttl != time.Minute {
+		t.Errorf("store = %v with ttl %s, want observer:book:gen:7 for a minute", store.values, store.ttl)
+	}
+
+	other := NewRemoteCache(store, time.Minute, &logger)
+	other.Set(ctx, 7, book, gen)
+	if _, ok := c.Get(ctx, 7); ok {
+		t.Errorf("Get(7) hit after Set of a book fetched before Delete")
+	}
+
+	other.Set(ctx, 7, book, other.Generation(ctx, 7))
+	if _, ok := c.Get(ctx, 7); !ok {
+		t.Errorf("Get(7) missed after Set of a book fetched after Delete")
+	}
+
+	// a generation that cannot be read drops Set
+	gen = c.Generation(ctx, 8)
+	store.values["observer:book:gen:8"] = []byte("garbage")
+	c.Set(ctx, 8, book, gen)
+	if _, ok := c.Get(ctx, 8); ok {
+		t.Errorf("Get(8) hit after Set with unreadable generation")
+	}
+}
+
+func TestCacheDropsBookFetchedBeforeWrite(t *testing.T) {
+	var e *echo.Echo
+
+	// the PATCH lands after storage has answered GET and before the gateway caches the answer
+	racing := false
+	patchDuringGet := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if racing && strings.HasSuffix(info.FullMethod, "/GetBookById") {
+			racing = false
+			mustServe(t, e, http.StatusOK, http.MethodPatch, "/storage/1", `{"title":"Patch"}`, HeaderIfMatch, "*")
+		}
+		return resp, err
+	}
+
+	logger := zerolog.Nop()
+	storage := newTestStorage(t, grpc.UnaryInterceptor(patchDuringGet))
+	serv, err := NewHttpServer(&logger, storageservice.NewStorageServiceClient(storage),
+		tracesdk.NewTracerProvider(), NewLRUCache(10, time.Minute), nil)
+	if err != nil {
+		t.Fatalf("NewHttpServer() error = %v", err)
+	}
+
+	e = echo.New()
+	e.Validator = NewValidator()
+	e.HTTPErrorHandler = serv.ErrorHandler
+	e.GET("/storage/:id", serv.GetValueById)
+	e.POST("/storage", serv.AddValue)
+	e.PATCH("/storage/:id", serv.PatchValue)
+
+	mustServe(t, e, http.StatusOK, http.MethodPost, "/storage", `{"title":"Book","author":"Author","price":"1.00"}`)
+
+	title := func() string {
+		t.Helper()
+
+		var book Book
+		rec := mustServe(t, e, http.StatusOK, http.MethodGet, "/storage/1", "")
+		if err := json.Unmarshal(rec.Body.Bytes(), &book); err != nil {
+			t.Fatalf("book %s: %v", rec.Body, err)
+		}
+		return book.Title
+	}
+
+	racing = true
+	if got := title(); got != "Book" {
+		t.Fatalf("title of GET racing PATCH = %q, want %q", got, "Book")
+	}
+	if racing {
+		t.Fatalf("PATCH was not run")
+	}
+
+	if got := title(); got != "Patch" {
+		t.Fatalf("title after PATCH = %q, want %q", got, "Patch")
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	e, serv := newTestServer(t, NewLRUCache(10, time.Minute))
+
+	recorder := tracetest.NewSpanRecorder()
+	serv.tracer = tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	// counters are shared by servers of the package, deltas are checked
+	hits, misses := testutil.ToFloat64(serv.cacheHitsCounter), testutil.ToFloat64(serv.cacheMissesCounter)
+
+	get := func(wantTitle string, wantHit bool) {
+		t.Helper()
+
+		rec := mustServe(t, e, http.StatusOK, http.MethodGet, "/storage/1", "")
+
+		var book Book
+		if err := json.Unmarshal(rec.Body.Bytes(), &book); err != nil {
+			t.Fatalf("book %s: %v", rec.Body, err)
+		}
+		if book.Title != wantTitle {
+			t.Errorf("title = %q, want %q", book.Title, wantTitle)
+		}
+
+		if wantHit {
+			hits++
+		} else {
+			misses++
+		}
+		if got := testutil.ToFloat64(serv.cacheHitsCounter); got != hits {
+			t.Errorf("book_cache_hits_total = %v, want %v", got, hits)
+		}
+		if got := testutil.ToFloat64(serv.cacheMissesCounter); got != misses {
+			t.Errorf("book_cache_misses_total = %v, want %v", got, misses)
+		}
+
+		spans := recorder.Ended()
+		span := spans[len(spans)-1]
+		if span.Name() != "GetValueById" {
+			t.Fatalf("last span = %s, want GetValueById", span.Name())
+		}
+		if !hasAttribute(span.Attributes(), attribute.Bool("cache.hit", wantHit)) {
+			t.Errorf("span attributes = %v, want cache.hit %t", span.Attributes(), wantHit)
+		}
+	}
+
+	mustServe(t, e, http.StatusOK, http.MethodPost, "/storage", `{"title":"Book","author":"Author","price":"1.00"}`)
+
+	get("Book", false)
+	get("Book", true)
+
+	mustServe(t, e, http.StatusOK, http.MethodPut, "/storage/1",
+		`{"title":"Put","author":"Author","price":"1.00"}`, HeaderIfMatch, "*")
+	get("Put", false)
+	get("Put", true)
+
+	mustServe(t, e, http.StatusOK, http.MethodPatch, "/storage/1", `{"title":"Patch"}`, HeaderIfMatch, "*")
+	get("Patch", false)
+	get("Patch", true)
+
+	mustServe(t, e, http.StatusNoContent, http.MethodDelete, "/storage/1", "")
+	mustServe(t, e, http.StatusNotFound, http.MethodGet, "/storage/1", "")
+	if got := testutil.ToFloat64(serv.cacheMissesCounter); got != misses+1 {
+		t.Errorf("book_cache_misses_total = %v after DELETE, want %v", got, misses+1)
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCacheInvalidatesAuthorBooks(t *testing.T) {
+	e, _ := newTestServer(t, NewLRUCache(10, time.Minute))
+
+	bio := func(id string) string {
+		t.Helper()
+
+		var book Book
+		rec := mustServe(t, e, http.StatusOK, http.MethodGet, "/storage/"+id, "")
+		if err := json.Unmarshal(rec.Body.Bytes(), &book); err != nil {
+			t.Fatalf("book %s: %v", rec.Body, err)
+		}
+		return book.AuthorBio
+	}
+
+	mustServe(t, e, http.StatusOK, http.MethodPost, "/storage", `{"title":"First","author":"Author","price":"1.00"}`)
+	if got := bio("1"); got != "" {
+		t.Fatalf("author_bio = %q, want none", got)
+	}
+
+	// the bio of the second book fills in the empty one of the author
+	mustServe(t, e, http.StatusOK, http.MethodPost, "/storage",
+		`{"title":"Second","author":"Author","price":"1.00","author_bio":"Old bio"}`)
+	if got := bio("1"); got != "Old bio" {
+		t.Fatalf("author_bio of cached book = %q, want %q", got, "Old bio")
+	}
+	bio("2")
+
+	mustServe(t, e, http.StatusOK, http.MethodPatch, "/storage/2", `{"author_bio":"New bio"}`, HeaderIfMatch, "*")
+	for _, id := range []string{"1", "2"} {
+		if got := bio(id); got != "New bio" {
+			t.Errorf("author_bio of cached book %s = %q, want %q", id, got, "New bio")
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+)
+
+// currencyFromQuery reads ISO 4217 code of currency query parameter, empty if not asked
+func currencyFromQuery(ctx echo.Context) (string, error) {
+	currency := ctx.QueryParam("currency")
+	if currency == "" {
+		return "", nil
+	}
+
+	if err := currencyValidate.Var(currency, "iso4217"); err != nil {
+		return "", newApiError(http.StatusBadRequest, "currency must be an ISO 4217 code, e.g. EUR")
+	}
+
+	return currency, nil
+}
+
+// quote prices book in currency, a native price is preferred over conversion
+func (serv *HttpServer) quote(resp *storageservice.GetValueResponse, currency string) (*PriceQuote, error) {
+	base := responseCurrency(resp)
+
+	switch amount, ok := resp.Prices[currency]; {
+	case currency == base:
+		return &PriceQuote{Currency: currency, Amount: priceFromResponse(resp), Source: PriceSourceNative}, nil
+	case ok:
+		return &PriceQuote{Currency: currency, Amount: Price(amount), Source: PriceSourceNative}, nil
+	}
+
+	conv, err := serv.rates.Convert(priceFromResponse(resp).decimal(), base, currency)
+	if err != nil {
+		if errors.Is(err, ErrNoRate) {
+			apiErr := newApiError(http.StatusUnprocessableEntity,
+				"cannot price book "+strconv.Itoa(int(resp.Id))+" in "+currency+": "+err.Error())
+			apiErr.Internal = err
+			return nil, apiErr
+		}
+		return nil, err
+	}
+
+	return &PriceQuote{
+		Currency:      currency,
+		Amount:        Price(conv.Amount),
+		Source:        PriceSourceConverted,
+		ConvertedFrom: base,
+		Rate:          conv.Rate,
+		RateTimestamp: &conv.Timestamp,
+	}, nil
+}
+
+// quotedBook is bookFromResponse with price quote in currency if one is asked
+func (serv *HttpServer) quotedBook(resp *storageservice.GetValueResponse, currency string) (Book, error) {
+	book := bookFromResponse(resp)
+	if currency == "" {
+		return book, nil
+	}
+
+	quote, err := serv.quote(resp, currency)
+	if err != nil {
+		return Book{}, err
+	}
+	book.Quote = quote
+
+	return book, nil
+}
+
+// responseCurrency falls back to the only currency of storage versions without currency field
+func responseCurrency(resp *storageservice.GetValueResponse) string {
+	if resp.Currency == "" {
+		return storageservice.DefaultCurrency
+	}
+
+	return resp.Currency
+}
+
+func pricesFromResponse(resp *storageservice.GetValueResponse) map[string]Price {
+	if len(resp.Prices) == 0 {
+		return nil
+	}
+
+	prices := make(map[string]Price, len(resp.Prices))
+	for currency, amount := range resp.Prices {
+		prices[currency] = Price(amount)
+	}
+
+	return prices
+}
+
+func pricesToRequest(prices map[string]Price) map[string]string {
+	if len(prices) == 0 {
+		return nil
+	}
+
+	req := make(map[string]string, len(prices))
+	for currency, amount := range prices {
+		req[currency] = amount.decimal()
+	}
+
+	return req
+}
+
+// GetRates returns exchange rate table used for price conversion
+func (serv *HttpServer) GetRates(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, serv.rates.Table())
+}
+
+// SetRates replaces exchange rate table with the request body
+func (serv *HttpServer) SetRates(ctx echo.Context) error {
+	var table RateTable
+
+	if err := ctx.Bind(&table); err != nil {
+		apiErr := newApiError(http.StatusBadRequest, "malformed rate table")
+		apiErr.Internal = err
+		return apiErr
+	}
+
+	if err := serv.rates.Set(table); err != nil {
+		return newApiError(http.StatusBadRequest, err.Error())
+	}
+
+	table = serv.rates.Table()
+	serv.logger.Info().Str("principal", PrincipalSubject(ctx)).Str("base", table.Base).
+		Time("timestamp", table.Timestamp).Msg("exchange rates replaced")
+
+	return ctx.JSON(http.StatusOK, table)
+}
+
+// ReloadRates reads exchange rate table from the rates file again
+func (serv *HttpServer) ReloadRates(ctx echo.Context) error {
+	if err := serv.rates.Reload(); err != nil {
+		if errors.Is(err, ErrNoRatesFile) {
+			return newApiError(http.StatusConflict, err.Error())
+		}
+
+		apiErr := newApiError(http.StatusInternalServerError, "cannot reload exchange rates")
+		apiErr.Internal = err
+		return apiErr
+	}
+
+	table := serv.rates.Table()
+	serv.logger.Info().Str("principal", PrincipalSubject(ctx)).Str("base", table.Base).
+		Time("timestamp", table.Timestamp).Msg("exchange rates reloaded")
+
+	return ctx.JSON(http.StatusOK, table)
+}
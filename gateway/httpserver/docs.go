@@ -0,0 +1,43 @@
+package httpserver
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// swaggerInitializer replaces the one from swagger ui dist pointing to petstore
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "/openapi.json",
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [
+      SwaggerUIBundle.presets.apis,
+      SwaggerUIStandalonePreset
+    ],
+    plugins: [
+      SwaggerUIBundle.plugins.DownloadUrl
+    ],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// DocsRedirect sends /docs to /docs/ so relative links of swagger ui resolve
+func (serv *HttpServer) DocsRedirect(ctx echo.Context) error {
+	return ctx.Redirect(http.StatusMovedPermanently, "/docs/")
+}
+
+// Docs serves swagger ui embedded into the binary
+func (serv *HttpServer) Docs(ctx echo.Context) error {
+	switch file := ctx.Param("*"); file {
+	case "", "index.html":
+		return echo.StaticFileHandler("index.html", swaggerFiles.FS)(ctx)
+	case "swagger-initializer.js":
+		return ctx.Blob(http.StatusOK, "application/javascript", []byte(swaggerInitializer))
+	default:
+		return echo.StaticFileHandler(file, swaggerFiles.FS)(ctx)
+	}
+}
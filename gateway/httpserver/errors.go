@@ -0,0 +1,219 @@
+package httpserver
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ApiError is returned by handlers and rendered by ErrorHandler
+type ApiError struct {
+	Status   int
+	Code     string
+	Message  string
+	Fields   []FieldError
+	Internal error
+}
+
+func newApiError(httpStatus int, message string) *ApiError {
+	return &ApiError{
+		Status:  httpStatus,
+		Code:    errorCode(httpStatus),
+		Message: message,
+	}
+}
+
+func (e *ApiError) Error() string {
+	if e.Internal != nil {
+		return e.Message + ": " + e.Internal.Error()
+	}
+
+	return e.Message
+}
+
+func (e *ApiError) Unwrap() error {
+	return e.Internal
+}
+
+// ErrorHandler is echo.HTTPErrorHandler writing ErrorResponse for any failed request
+func (serv *HttpServer) ErrorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+
+	apiErr := toApiError(err)
+
+	if apiErr.Status >= http.StatusInternalServerError {
+		serv.logger.Error().Err(err).Str("path", ctx.Path()).Str("principal", PrincipalSubject(ctx)).Msg("request failed")
+	}
+
+	body := ErrorResponse{Error: ErrorBody{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Fields:    apiErr.Fields,
+		RequestId: ctx.Response().Header().Get(echo.HeaderXRequestID),
+	}}
+
+	if spanCtx := trace.SpanContextFromContext(ctx.Request().Context()); spanCtx.HasTraceID() {
+		body.Error.TraceId = spanCtx.TraceID().String()
+		ctx.Response().Header().Set("Trace-Id", body.Error.TraceId)
+	}
+
+	if ctx.Request().Method == http.MethodHead {
+		err = ctx.NoContent(apiErr.Status)
+	} else {
+		err = ctx.JSON(apiErr.Status, body)
+	}
+
+	if err != nil {
+		serv.logger.Error().Err(err).Msg("failed to write error response")
+	}
+}
+
+func toApiError(err error) *ApiError {
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		message, ok := httpErr.Message.(string)
+		if !ok {
+			message = http.StatusText(httpErr.Code)
+		}
+
+		return &ApiError{
+			Status:   httpErr.Code,
+			Code:     errorCode(httpErr.Code),
+			Message:  message,
+			Internal: httpErr.Internal,
+		}
+	}
+
+	return &ApiError{
+		Status:   http.StatusInternalServerError,
+		Code:     errorCode(http.StatusInternalServerError),
+		Message:  "Server error",
+		Internal: err,
+	}
+}
+
+// errorCode makes machine-readable code from http status, e.g. not_found
+func errorCode(httpStatus int) string {
+	switch httpStatus {
+	case 499:
+		return "client_closed_request"
+	}
+
+	text := http.StatusText(httpStatus)
+	if text == "" {
+		return "unknown"
+	}
+
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
+
+// httpStatusFromGrpc maps storage service status codes to http ones
+func httpStatusFromGrpc(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Canceled:
+		return 499 // client closed request
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// storageError converts error got from storage via grpc to ApiError
+// and marks span as failed
+func (serv *HttpServer) storageError(span trace.Span, err error) error {
+	apiErr := apiErrorFromStatus(status.Convert(err))
+	apiErr.Internal = err
+
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, apiErr.Message)
+
+	if apiErr.Status < http.StatusInternalServerError {
+		serv.logger.Warn().Err(err).Msg("storage rejected request")
+	}
+
+	return apiErr
+}
+
+func apiErrorFromStatus(st *status.Status) *ApiError {
+	httpStatus := httpStatusFromGrpc(st.Code())
+	if errorReason(st) == storageservice.ReasonIdempotencyKeyReused {
+		httpStatus = http.StatusUnprocessableEntity
+	}
+
+	apiErr := newApiError(httpStatus, st.Message())
+	apiErr.Fields = fieldViolations(st)
+
+	if httpStatus == http.StatusInternalServerError {
+		apiErr.Message = "Server error"
+	}
+
+	return apiErr
+}
+
+// fieldViolations extracts per-field errors attached by storage validation
+func fieldViolations(st *status.Status) []FieldError {
+	var fields []FieldError
+
+	for _, detail := range st.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+
+		for _, v := range badRequest.FieldViolations {
+			fields = append(fields, FieldError{
+				Field:   v.Field,
+				Message: v.Description,
+			})
+		}
+	}
+
+	return fields
+}
+
+// errorReason is ErrorInfo reason of storage error, empty if there is none
+func errorReason(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == storageservice.ErrorDomain {
+			return info.Reason
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,33 @@
+package httpserver
+
+import (
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestHttpStatusFromGrpc(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		want int
+	}{
+		{codes.OK, http.StatusOK},
+		{codes.NotFound, http.StatusNotFound},
+		{codes.InvalidArgument, http.StatusBadRequest},
+		{codes.AlreadyExists, http.StatusConflict},
+		{codes.FailedPrecondition, http.StatusPreconditionFailed},
+		{codes.Unavailable, http.StatusServiceUnavailable},
+		{codes.DeadlineExceeded, http.StatusGatewayTimeout},
+		{codes.Internal, http.StatusInternalServerError},
+		{codes.Unknown, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			if got := httpStatusFromGrpc(tt.code); got != tt.want {
+				t.Errorf("httpStatusFromGrpc(%s) = %d, want %d", tt.code, got, tt.want)
+			}
+		})
+	}
+}
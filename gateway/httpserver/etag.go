@@ -0,0 +1,80 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	HeaderETag        = "ETag"
+	HeaderIfMatch     = "If-Match"
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
+// bookETag is a strong entity tag of book version, e.g. "3"
+func bookETag(version int32) string {
+	return strconv.Quote(strconv.Itoa(int(version)))
+}
+
+// quotedBookETag is a weak entity tag of book priced in another currency as the
+// representation depends on rate table too, e.g. W/"3;EUR;1697587200"
+func quotedBookETag(version int32, quote *PriceQuote) string {
+	tag := strconv.Itoa(int(version)) + ";" + quote.Currency
+	if quote.RateTimestamp != nil {
+		tag += ";" + strconv.FormatInt(quote.RateTimestamp.Unix(), 10)
+	}
+
+	return "W/" + strconv.Quote(tag)
+}
+
+// etagMatches reports whether If-None-Match header value lists etag,
+// weak comparison is used as RFC 9110 requires for If-None-Match
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expectedVersion reads book version from If-Match header, nil means any version;
+// missing header is an error only if required is set
+func expectedVersion(ctx echo.Context, required bool) (*int32, error) {
+	return parseIfMatch(ctx.Request().Header.Get(HeaderIfMatch), required)
+}
+
+// parseIfMatch takes the version from an ETag of GET, strong "3" or weak W/"3;EUR;1697587200"
+// of a book priced in another currency; the version is all the update has to match
+func parseIfMatch(header string, required bool) (*int32, error) {
+	header = strings.TrimSpace(header)
+
+	switch {
+	case header == "" && required:
+		return nil, newApiError(http.StatusPreconditionRequired,
+			"If-Match header with ETag from GET /storage/:id is required")
+	case header == "", header == "*":
+		return nil, nil
+	}
+
+	unquoted, err := strconv.Unquote(strings.TrimPrefix(header, "W/"))
+	if err != nil {
+		return nil, newApiError(http.StatusBadRequest, "If-Match must be a single ETag")
+	}
+
+	tagVersion, _, _ := strings.Cut(unquoted, ";")
+
+	version, err := strconv.ParseInt(tagVersion, 10, 32)
+	if err != nil {
+		// not an ETag this gateway has issued, so it cannot match
+		return nil, newApiError(http.StatusPreconditionFailed, "book version does not match expected")
+	}
+
+	v := int32(version)
+	return &v, nil
+}
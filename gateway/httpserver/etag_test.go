@@ -0,0 +1,114 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header      string
+		required    bool
+		wantVersion int32 // 0 means nil
+		wantStatus  int   // 0 means no error
+	}{
+		{`"3"`, true, 3, 0},
+		{`W/"3;EUR;1697587200"`, true, 3, 0},
+		{`W/"3;EUR"`, false, 3, 0},
+		{`*`, true, 0, 0},
+		{``, false, 0, 0},
+		{``, true, 0, http.StatusPreconditionRequired},
+		{`3`, true, 0, http.StatusBadRequest},
+		{`"abc"`, true, 0, http.StatusPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		version, err := parseIfMatch(tt.header, tt.required)
+
+		if tt.wantStatus != 0 {
+			if err == nil || toApiError(err).Status != tt.wantStatus {
+				t.Errorf("parseIfMatch(%q) error = %v, want status %d", tt.header, err, tt.wantStatus)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("parseIfMatch(%q) error = %v", tt.header, err)
+			continue
+		}
+
+		if (version == nil) != (tt.wantVersion == 0) || version != nil && *version != tt.wantVersion {
+			t.Errorf("parseIfMatch(%q) = %v, want %d", tt.header, version, tt.wantVersion)
+		}
+	}
+}
+
+func TestUpdateWithQuotedBookETag(t *testing.T) {
+	e, _ := newTestServer(t, nil)
+
+	mustServe(t, e, http.StatusOK, http.MethodPost, "/storage",
+		`{"title":"Book","author":"Author","price":"10.00","currency":"EUR","prices":{"USD":"11.00"}}`)
+
+	rec := mustServe(t, e, http.StatusOK, http.MethodGet, "/storage/1?currency=USD", "")
+	etag := rec.Header().Get(HeaderETag)
+	if !strings.HasPrefix(etag, `W/"1;USD`) {
+		t.Fatalf("ETag = %q, want weak tag of quoted book", etag)
+	}
+
+	rec = mustServe(t, e, http.StatusOK, http.MethodPut, "/storage/1",
+		`{"title":"New title","author":"Author","price":"10.00"}`, HeaderIfMatch, etag)
+	if got := rec.Header().Get(HeaderETag); got != `"2"` {
+		t.Fatalf("ETag after PUT = %q, want \"2\"", got)
+	}
+
+	// the tag is stale now
+	mustServe(t, e, http.StatusPreconditionFailed, http.MethodPatch, "/storage/1",
+		`{"title":"Other title"}`, HeaderIfMatch, etag)
+}
+
+func TestTranscodingRequiresIfMatch(t *testing.T) {
+	handler, err := NewTranscodingHandler(context.Background(), newTestStorage(t))
+	if err != nil {
+		t.Fatalf("NewTranscodingHandler() error = %v", err)
+	}
+
+	transcode := func(method, target, body string, headers ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := transcode(http.MethodPost, "/v1/books", `{"title":"Book","author":"Author"}`); rec.Code != http.StatusOK {
+		t.Fatalf("POST /v1/books = %d %s", rec.Code, rec.Body)
+	}
+
+	steps := []struct {
+		name    string
+		body    string
+		headers []string
+		want    int
+	}{
+		{"no version", `{"title":"A"}`, nil, http.StatusPreconditionRequired},
+		{"weak etag", `{"title":"B"}`, []string{HeaderIfMatch, `W/"1;USD;1697587200"`}, http.StatusOK},
+		{"stale etag", `{"title":"C"}`, []string{HeaderIfMatch, `"1"`}, http.StatusPreconditionFailed},
+		{"version in body", `{"title":"D","expected_version":2}`, nil, http.StatusOK},
+		{"any version", `{"title":"E"}`, []string{HeaderIfMatch, "*"}, http.StatusOK},
+	}
+
+	for _, step := range steps {
+		if rec := transcode(http.MethodPatch, "/v1/books/1", step.body, step.headers...); rec.Code != step.want {
+			t.Fatalf("%s: PATCH /v1/books/1 = %d %s, want %d", step.name, rec.Code, rec.Body, step.want)
+		}
+	}
+}
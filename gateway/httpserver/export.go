@@ -0,0 +1,215 @@
+package httpserver
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+const (
+	MIMEApplicationProtobuf = "application/x-protobuf"
+
+	// rows written before the response is flushed to client
+	exportFlushRows = 500
+)
+
+var csvExportHeader = []string{"id", "title", "author_id", "author", "price", "currency", "description", "author_bio"}
+
+// exportWriter encodes streamed books in one of export formats
+type exportWriter interface {
+	Write(book *storageservice.GetValueResponse) error
+	Flush() error
+}
+
+type exportFormat struct {
+	contentType string
+	newWriter   func(w io.Writer) exportWriter
+}
+
+// protobuf export is a sequence of varint length-delimited GetValueResponse messages
+var exportFormats = map[string]exportFormat{
+	"csv":      {MIMETextCSV + "; charset=UTF-8", newCsvExportWriter},
+	"jsonl":    {MIMEApplicationNDJSON, newJsonExportWriter},
+	"protobuf": {MIMEApplicationProtobuf, newProtoExportWriter},
+}
+
+// ExportValues streams all books matching filter params in id order,
+// the response is written as books arrive from storage
+func (serv *HttpServer) ExportValues(ctx echo.Context) error {
+	formatName := ctx.QueryParam("format")
+	if formatName == "" {
+		formatName = "jsonl"
+	}
+
+	format, ok := exportFormats[formatName]
+	if !ok {
+		return newApiError(http.StatusBadRequest,
+			fmt.Sprintf("unknown format %q, want csv, jsonl or protobuf", formatName))
+	}
+
+	filter, err := filterFromQuery(ctx)
+	if err != nil {
+		return newApiError(http.StatusBadRequest, err.Error())
+	}
+
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"ExportValues",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("query"),
+				Value: attribute.StringValue(ctx.QueryString()),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	stream, err := serv.storageClient.ExportBooks(distCtx, &storageservice.ExportRequest{Filter: filter})
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	// storage errors before the first book can still be answered with an error response
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		return serv.storageError(span, err)
+	}
+
+	resp := ctx.Response()
+	resp.Header().Set(echo.HeaderContentType, format.contentType)
+	resp.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "books."+formatName))
+	resp.Header().Add("Trace-Id", traceId)
+	resp.WriteHeader(http.StatusOK)
+
+	buf := bufio.NewWriter(resp)
+	writer := format.newWriter(buf)
+
+	flush := func() error {
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		if err := buf.Flush(); err != nil {
+			return err
+		}
+		resp.Flush()
+		return nil
+	}
+
+	rows := 0
+	book := first
+
+	for err == nil {
+		if err = writer.Write(book); err != nil {
+			break
+		}
+
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err = flush(); err != nil {
+				break
+			}
+		}
+
+		book, err = stream.Recv()
+	}
+
+	span.SetAttributes(
+		attribute.String("export.format", formatName),
+		attribute.Int("export.rows", rows),
+	)
+
+	if err == io.EOF {
+		err = flush()
+	}
+
+	if err != nil {
+		// status is already sent, aborting the connection is the only way to tell
+		// the client that export is incomplete
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "export interrupted")
+		serv.logger.Error().Err(err).Int("rows", rows).Str("trace_id", traceId).Msg("export interrupted")
+		panic(http.ErrAbortHandler)
+	}
+
+	return nil
+}
+
+type csvExportWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCsvExportWriter(w io.Writer) exportWriter {
+	cw := csv.NewWriter(w)
+	_ = cw.Write(csvExportHeader) // errors are kept by csv.Writer until Flush
+
+	return &csvExportWriter{w: cw, record: make([]string, len(csvExportHeader))}
+}
+
+func (cw *csvExportWriter) Write(book *storageservice.GetValueResponse) error {
+	cw.record[0] = strconv.Itoa(int(book.Id))
+	cw.record[1] = book.Title
+	cw.record[2] = strconv.Itoa(int(book.AuthorId))
+	cw.record[3] = book.Author
+	cw.record[4] = string(priceFromResponse(book))
+	cw.record[5] = responseCurrency(book)
+	cw.record[6] = book.Description
+	cw.record[7] = book.AuthorBio
+
+	return cw.w.Write(cw.record)
+}
+
+func (cw *csvExportWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type jsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func newJsonExportWriter(w io.Writer) exportWriter {
+	return &jsonExportWriter{enc: json.NewEncoder(w)}
+}
+
+func (jw *jsonExportWriter) Write(book *storageservice.GetValueResponse) error {
+	return jw.enc.Encode(bookFromResponse(book))
+}
+
+func (jw *jsonExportWriter) Flush() error {
+	return nil
+}
+
+type protoExportWriter struct {
+	w io.Writer
+}
+
+func newProtoExportWriter(w io.Writer) exportWriter {
+	return &protoExportWriter{w: w}
+}
+
+func (pw *protoExportWriter) Write(book *storageservice.GetValueResponse) error {
+	_, err := protodelim.MarshalTo(pw.w, book)
+	return err
+}
+
+func (pw *protoExportWriter) Flush() error {
+	return nil
+}
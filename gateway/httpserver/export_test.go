@@ -0,0 +1,169 @@
+package httpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// exportTitles decodes titles of exported books of the format
+var exportTitles = map[string]func(t *testing.T, body []byte) []string{
+	"csv": func(t *testing.T, body []byte) []string {
+		records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+		if err != nil {
+			t.Fatalf("csv export %s: %v", body, err)
+		}
+		if len(records) == 0 || !reflect.DeepEqual(records[0], csvExportHeader) {
+			t.Fatalf("csv export %s, want header %v", body, csvExportHeader)
+		}
+
+		var titles []string
+		for _, record := range records[1:] {
+			titles = append(titles, record[1])
+		}
+		return titles
+	},
+	"jsonl": func(t *testing.T, body []byte) []string {
+		var titles []string
+		for dec := json.NewDecoder(bytes.NewReader(body)); dec.More(); {
+			var book Book
+			if err := dec.Decode(&book); err != nil {
+				t.Fatalf("jsonl export %s: %v", body, err)
+			}
+			titles = append(titles, book.Title)
+		}
+		return titles
+	},
+	"protobuf": func(t *testing.T, body []byte) []string {
+		var titles []string
+		for r := bufio.NewReader(bytes.NewReader(body)); ; {
+			book := &storageservice.GetValueResponse{}
+			err := protodelim.UnmarshalFrom(r, book)
+			if errors.Is(err, io.EOF) {
+				return titles
+			}
+			if err != nil {
+				t.Fatalf("protobuf export: %v", err)
+			}
+			titles = append(titles, book.Title)
+		}
+	},
+}
+
+func newExportTestServer(t *testing.T) (*echo.Echo, *HttpServer) {
+	t.Helper()
+
+	e, serv := newTestServer(t, nil)
+	for _, body := range []string{
+		`{"title":"Alpha","author":"First author","price":"10.00"}`,
+		`{"title":"Beta","author":"Second author","price":"20.00"}`,
+		`{"title":"Gamma","author":"First author","price":"30.00"}`,
+	} {
+		mustServe(t, e, http.StatusOK, http.MethodPost, "/storage", body)
+	}
+
+	return e, serv
+}
+
+func TestExportValues(t *testing.T) {
+	e, _ := newExportTestServer(t)
+
+	tests := []struct {
+		query           string
+		format          string
+		wantContentType string
+		wantTitles      []string
+	}{
+		{"?format=csv", "csv", MIMETextCSV + "; charset=UTF-8", []string{"Alpha", "Beta", "Gamma"}},
+		{"", "jsonl", MIMEApplicationNDJSON, []string{"Alpha", "Beta", "Gamma"}},
+		{"?format=protobuf", "protobuf", MIMEApplicationProtobuf, []string{"Alpha", "Beta", "Gamma"}},
+		{"?format=csv&author=First%20author", "csv", MIMETextCSV + "; charset=UTF-8", []string{"Alpha", "Gamma"}},
+		{"?format=jsonl&min_price=15", "jsonl", MIMEApplicationNDJSON, []string{"Beta", "Gamma"}},
+		{"?format=protobuf&title=amm", "protobuf", MIMEApplicationProtobuf, []string{"Gamma"}},
+	}
+
+	for _, tt := range tests {
+		rec := mustServe(t, e, http.StatusOK, http.MethodGet, "/storage/export"+tt.query, "")
+
+		if got := rec.Header().Get(echo.HeaderContentType); got != tt.wantContentType {
+			t.Errorf("%s: Content-Type = %q, want %q", tt.query, got, tt.wantContentType)
+		}
+		if got, want := rec.Header().Get(echo.HeaderContentDisposition), `attachment; filename="books.`+tt.format+`"`; got != want {
+			t.Errorf("%s: Content-Disposition = %q, want %q", tt.query, got, want)
+		}
+
+		if got := exportTitles[tt.format](t, rec.Body.Bytes()); !reflect.DeepEqual(got, tt.wantTitles) {
+			t.Errorf("%s: exported %v, want %v", tt.query, got, tt.wantTitles)
+		}
+	}
+
+	mustServe(t, e, http.StatusBadRequest, http.MethodGet, "/storage/export?format=xml", "")
+	mustServe(t, e, http.StatusBadRequest, http.MethodGet, "/storage/export?min_price=cheap", "")
+}
+
+// failingExportClient breaks export streams after failAfter books
+type failingExportClient struct {
+	storageservice.StorageServiceClient
+	failAfter int
+}
+
+func (c failingExportClient) ExportBooks(ctx context.Context, in *storageservice.ExportRequest, opts ...grpc.CallOption) (storageservice.StorageService_ExportBooksClient, error) {
+	stream, err := c.StorageServiceClient.ExportBooks(ctx, in, opts...)
+	return &failingExportStream{StorageService_ExportBooksClient: stream, left: c.failAfter}, err
+}
+
+type failingExportStream struct {
+	storageservice.StorageService_ExportBooksClient
+	left int
+}
+
+func (s *failingExportStream) Recv() (*storageservice.GetValueResponse, error) {
+	if s.left == 0 {
+		return nil, status.Error(codes.Unavailable, "storage is gone")
+	}
+	s.left--
+
+	return s.StorageService_ExportBooksClient.Recv()
+}
+
+func TestExportValuesStorageError(t *testing.T) {
+	e, serv := newExportTestServer(t)
+
+	// nothing is sent yet, the error is answered as usual
+	serv.storageClient = failingExportClient{StorageServiceClient: serv.storageClient}
+	mustServe(t, e, http.StatusServiceUnavailable, http.MethodGet, "/storage/export", "")
+}
+
+func TestExportValuesInterrupted(t *testing.T) {
+	e, serv := newExportTestServer(t)
+	serv.storageClient = failingExportClient{StorageServiceClient: serv.storageClient, failAfter: 1}
+
+	rec := httptest.NewRecorder()
+	defer func() {
+		// the status is sent already, the connection is aborted instead
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Fatalf("recovered %v, want http.ErrAbortHandler", r)
+		}
+		if rec.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200 sent before the error", rec.Code)
+		}
+	}()
+
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/storage/export", nil))
+	t.Fatalf("export finished with %d %s, want abort", rec.Code, rec.Body)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/labstack/echo/v4"
@@ -16,8 +17,15 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
 type MetricsStack struct {
 	totalRequestsAcceptedCounter prometheus.Counter
+	cacheHitsCounter             prometheus.Counter
+	cacheMissesCounter           prometheus.Counter
 	// totalRequestsFailedCounter        prometheus.Counter
 	// totalRequestsHandBugFailedCounter prometheus.Counter
 	dataTransferGauge prometheus.Gauge
@@ -30,6 +38,8 @@ type HttpServer struct {
 	logger        *zerolog.Logger
 	tracer        *tracesdk.TracerProvider
 	storageClient storageservice.StorageServiceClient
+	cache         BookCache
+	rates         *Rates
 	MetricsStack
 	mutex sync.RWMutex
 }
@@ -37,11 +47,22 @@ type HttpServer struct {
 func NewHttpServer(
 	loggger *zerolog.Logger,
 	storageClient storageservice.StorageServiceClient,
-	tracer *tracesdk.TracerProvider) (*HttpServer, error) {
+	tracer *tracesdk.TracerProvider,
+	cache BookCache,
+	rates *Rates) (*HttpServer, error) {
+	if cache == nil {
+		cache = NoCache{}
+	}
+	if rates == nil {
+		rates = &Rates{}
+	}
+
 	return &HttpServer{
 		logger:        loggger,
 		tracer:        tracer,
 		storageClient: storageClient,
+		cache:         cache,
+		rates:         rates,
 		MetricsStack:  initMetrics(),
 	}, nil
 }
@@ -49,7 +70,7 @@ func NewHttpServer(
 func (serv *HttpServer) GetValueById(ctx echo.Context) error {
 	id := ctx.Param("id")
 	if id == "" {
-		return ctx.JSON(http.StatusBadRequest, "empty id")
+		return newApiError(http.StatusBadRequest, "empty id")
 	}
 
 	// ----------------------tracing----------------------
@@ -72,32 +93,171 @@ func (serv *HttpServer) GetValueById(ctx echo.Context) error {
 
 	idNum, err := strconv.Atoi(id)
 	if err != nil {
-		return ctx.JSON(http.StatusBadRequest, "wrong id format")
+		return newApiError(http.StatusBadRequest, "wrong id format")
 	}
 
-	resp, err := serv.storageClient.GetBookById(distCtx, &storageservice.GetValueRequest{
-		Id: int32(idNum),
-	})
-
+	currency, err := currencyFromQuery(ctx)
 	if err != nil {
-		serv.logger.Error().Err(err).Msg("got err from stoage via grpc")
-		return ctx.JSON(http.StatusInternalServerError, "Server error")
+		return err
+	}
+
+	resp, hit := serv.cache.Get(spanCtx, int32(idNum))
+	span.SetAttributes(attribute.Bool("cache.hit", hit))
+
+	if hit {
+		serv.cacheHitsCounter.Inc()
+	} else {
+		serv.cacheMissesCounter.Inc()
+
+		gen := serv.cache.Generation(spanCtx, int32(idNum))
+
+		resp, err = serv.storageClient.GetBookById(distCtx, &storageservice.GetValueRequest{
+			Id: int32(idNum),
+		})
+
+		if err != nil {
+			return serv.storageError(span, err)
+		}
+
+		serv.cache.Set(spanCtx, int32(idNum), resp, gen)
 	}
 
 	serv.dataTransferGauge.Add(float64(len(resp.String()))) // for test purposes
 
 	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
 
-	return ctx.JSON(http.StatusOK, Book{
-		BookID: resp.Id,
-		BookToAdd: BookToAdd{
-			Title:       resp.Title,
-			Author:      resp.Author,
-			Price:       float64(resp.Price),
-			Description: resp.Description,
-			AuthorBio:   resp.AuthorBio,
-		},
+	book, err := serv.quotedBook(resp, currency)
+	if err != nil {
+		return err
+	}
+
+	etag := bookETag(resp.Version)
+	if book.Quote != nil {
+		etag = quotedBookETag(resp.Version, book.Quote)
+	}
+	ctx.Response().Header().Set(HeaderETag, etag)
+
+	if etagMatches(ctx.Request().Header.Get(HeaderIfNoneMatch), etag) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return ctx.JSON(http.StatusOK, book)
+}
+
+func (serv *HttpServer) ListValues(ctx echo.Context) error {
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"ListValues",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("query"),
+				Value: attribute.StringValue(ctx.QueryString()),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	req, err := listRequestFromQuery(ctx)
+	if err != nil {
+		return newApiError(http.StatusBadRequest, err.Error())
+	}
+
+	currency, err := currencyFromQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := serv.storageClient.ListBooks(distCtx, req)
+
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+
+	books := make([]Book, 0, len(resp.Books))
+	for _, b := range resp.Books {
+		book, err := serv.quotedBook(b, currency)
+		if err != nil {
+			return err
+		}
+		books = append(books, book)
+	}
+
+	return ctx.JSON(http.StatusOK, BookList{
+		Books:      books,
+		NextCursor: resp.NextCursor,
+	})
+}
+
+func (serv *HttpServer) SearchValues(ctx echo.Context) error {
+	query := ctx.QueryParam("q")
+	if query == "" {
+		return newApiError(http.StatusBadRequest, "empty query")
+	}
+
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"SearchValues",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("q"),
+				Value: attribute.StringValue(query),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	var limit int
+	if l := ctx.QueryParam("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil {
+			return newApiError(http.StatusBadRequest, "wrong limit format")
+		}
+	}
+
+	currency, err := currencyFromQuery(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := serv.storageClient.SearchBooks(distCtx, &storageservice.SearchRequest{
+		Query: query,
+		Limit: int32(limit),
 	})
+
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+
+	hits := make([]SearchHit, 0, len(resp.Hits))
+	for _, h := range resp.Hits {
+		book, err := serv.quotedBook(h.Book, currency)
+		if err != nil {
+			return err
+		}
+
+		hits = append(hits, SearchHit{
+			Book:           book,
+			Rank:           h.Rank,
+			TitleHighlight: h.TitleHighlight,
+			Snippet:        h.Snippet,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, SearchResult{Hits: hits})
 }
 
 func (serv *HttpServer) AddValue(ctx echo.Context) error {
@@ -111,26 +271,47 @@ func (serv *HttpServer) AddValue(ctx echo.Context) error {
 
 	var value BookToAdd
 
-	err := ctx.Bind(&value)
-	if err != nil {
-		serv.logger.Error().Err(err).Msg("cannot bind request body with ValueToAdd struct")
-		return ctx.JSON(http.StatusInternalServerError, "Server error")
+	if err := serv.bindAndValidate(ctx, &value); err != nil {
+		return err
 	}
 
 	traceId := span.SpanContext().TraceID().String()
 	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
 
+	// storage replays the stored id for a repeated key instead of inserting again
+	if key := ctx.Request().Header.Get(HeaderIdempotencyKey); key != "" {
+		span.SetAttributes(attribute.String("idempotency.key", key))
+		distCtx = metadata.AppendToOutgoingContext(distCtx, storageservice.IdempotencyKeyHeader, key)
+	}
+
+	var header metadata.MD
+
 	resp, err := serv.storageClient.AddBook(distCtx, &storageservice.SetValueRequest{
-		Title:       value.Title,
-		Author:      value.Author,
-		Price:       float32(value.Price),
-		Description: value.Description,
-		AuthorBio:   value.AuthorBio,
-	})
+		Title:        value.Title,
+		AuthorId:     value.AuthorID,
+		Author:       value.Author,
+		Price:        value.Price.legacy(),
+		PriceDecimal: value.Price.decimal(),
+		Currency:     value.Currency,
+		Prices:       pricesToRequest(value.Prices),
+		Description:  value.Description,
+		AuthorBio:    value.AuthorBio,
+	}, grpc.Header(&header))
 
 	if err != nil {
-		serv.logger.Error().Err(err).Msg("got err from stoage via grpc")
-		return ctx.JSON(http.StatusInternalServerError, "Server error")
+		return serv.storageError(span, err)
+	}
+
+	if len(header.Get(storageservice.IdempotentReplayHeader)) > 0 {
+		ctx.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	} else if serv.cache.Enabled() && value.AuthorID == 0 && value.AuthorBio != "" {
+		// the bio fills in an empty one of an existing author
+		book, err := serv.storageClient.GetBookById(distCtx, &storageservice.GetValueRequest{Id: resp.Id})
+		if err != nil {
+			serv.logger.Warn().Err(err).Int32("id", resp.Id).Msg("book cache invalidation failed")
+		} else {
+			serv.invalidateAuthorBooks(spanCtx, distCtx, book.AuthorId)
+		}
 	}
 
 	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
@@ -138,6 +319,179 @@ func (serv *HttpServer) AddValue(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, resp.Id)
 }
 
+func (serv *HttpServer) UpdateValue(ctx echo.Context) error {
+	var value BookToAdd
+
+	if err := serv.bindAndValidate(ctx, &value); err != nil {
+		return err
+	}
+
+	// replacement resets currency and drops native prices missing from the body
+	currency := value.Currency
+	if currency == "" {
+		currency = storageservice.DefaultCurrency
+	}
+
+	prices := make(map[string]*Price, len(value.Prices))
+	for code, amount := range value.Prices {
+		amount := amount
+		prices[code] = &amount
+	}
+
+	patch := BookToPatch{
+		Title:       &value.Title,
+		Price:       &value.Price,
+		Currency:    &currency,
+		Prices:      prices,
+		Description: &value.Description,
+	}
+	if value.AuthorID != 0 {
+		patch.AuthorID = &value.AuthorID
+	} else {
+		patch.Author = &value.Author
+	}
+	// bio is shared with other books of the author, empty one is not a reason to wipe it
+	if value.AuthorBio != "" {
+		patch.AuthorBio = &value.AuthorBio
+	}
+
+	return serv.updateValue(ctx, "UpdateValue", patch, true)
+}
+
+func (serv *HttpServer) PatchValue(ctx echo.Context) error {
+	var value BookToPatch
+
+	if err := serv.bindAndValidate(ctx, &value); err != nil {
+		return err
+	}
+
+	return serv.updateValue(ctx, "PatchValue", value, false)
+}
+
+func (serv *HttpServer) updateValue(ctx echo.Context, operation string, value BookToPatch, replacePrices bool) error {
+	id := ctx.Param("id")
+	if id == "" {
+		return newApiError(http.StatusBadRequest, "empty id")
+	}
+
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		operation,
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("id"),
+				Value: attribute.StringValue(id),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return newApiError(http.StatusBadRequest, "wrong id format")
+	}
+
+	req := &storageservice.UpdateValueRequest{
+		Id:            int32(idNum),
+		Title:         value.Title,
+		AuthorId:      value.AuthorID,
+		Author:        value.Author,
+		Currency:      value.Currency,
+		Description:   value.Description,
+		AuthorBio:     value.AuthorBio,
+		ReplacePrices: replacePrices,
+	}
+	if value.Price != nil {
+		decimal, legacy := value.Price.decimal(), value.Price.legacy()
+		req.PriceDecimal, req.Price = &decimal, &legacy
+	}
+	if len(value.Prices) > 0 {
+		req.Prices = make(map[string]string, len(value.Prices))
+		for currency, amount := range value.Prices {
+			if amount != nil {
+				req.Prices[currency] = amount.decimal()
+			} else {
+				req.Prices[currency] = "" // removes the price
+			}
+		}
+	}
+
+	// updates without If-Match could silently overwrite a concurrent change
+	req.ExpectedVersion, err = expectedVersion(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	resp, err := serv.storageClient.UpdateBook(distCtx, req)
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	serv.cache.Delete(spanCtx, req.Id)
+	if req.AuthorBio != nil {
+		serv.invalidateAuthorBooks(spanCtx, distCtx, resp.AuthorId)
+	}
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+	ctx.Response().Header().Set(HeaderETag, bookETag(resp.Version))
+
+	return ctx.JSON(http.StatusOK, bookFromResponse(resp))
+}
+
+func (serv *HttpServer) DeleteValue(ctx echo.Context) error {
+	id := ctx.Param("id")
+	if id == "" {
+		return newApiError(http.StatusBadRequest, "empty id")
+	}
+
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"DeleteValue",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("id"),
+				Value: attribute.StringValue(id),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return newApiError(http.StatusBadRequest, "wrong id format")
+	}
+
+	version, err := expectedVersion(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	_, err = serv.storageClient.DeleteBook(distCtx, &storageservice.DeleteValueRequest{
+		Id:              int32(idNum),
+		ExpectedVersion: version,
+	})
+
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	serv.cache.Delete(spanCtx, int32(idNum))
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
 func (serv *HttpServer) CountTotalReqMetricMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		if err := next(c); err != nil {
@@ -172,6 +526,18 @@ func initMetrics() MetricsStack {
 	// 		Help: "total request count failed on server side",
 	// 	})
 
+	cacheHitsCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "book_cache_hits_total",
+			Help: "GetValueById requests served from cache",
+		})
+
+	cacheMissesCounter := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "book_cache_misses_total",
+			Help: "GetValueById requests passed to storage",
+		})
+
 	dataTransferGauge := prometheus.NewGauge(
 		prometheus.GaugeOpts{
 			Name: "data_transf_gauge",
@@ -198,17 +564,26 @@ func initMetrics() MetricsStack {
 	// 		Help:       "some test help 2",
 	// 	})
 
-	prometheus.MustRegister(
-		totalRequestsAcceptedCounter,
-		dataTransferGauge,
-		// totalRequestsHandBugFailedCounter,
-		// approximateReviewCountGauge,
-	)
-
 	return MetricsStack{
-		totalRequestsAcceptedCounter: totalRequestsAcceptedCounter,
-		dataTransferGauge:            dataTransferGauge,
+		totalRequestsAcceptedCounter: register(totalRequestsAcceptedCounter),
+		cacheHitsCounter:             register(cacheHitsCounter),
+		cacheMissesCounter:           register(cacheMissesCounter),
+		dataTransferGauge:            register(dataTransferGauge),
 		// totalRequestsHandBugFailedCounter: totalRequestsHandBugFailedCounter,
 		// approximateReviewCountGauge:       approximateReviewCountGauge,
 	}
 }
+
+// register is prometheus.MustRegister returning the collector registered before
+// under the same name instead of panicking, servers created later share it, e.g. in tests
+func register[C prometheus.Collector](collector C) C {
+	if err := prometheus.Register(collector); err != nil {
+		registered, ok := err.(prometheus.AlreadyRegisteredError)
+		if !ok {
+			panic(err)
+		}
+		return registered.ExistingCollector.(C)
+	}
+
+	return collector
+}
@@ -0,0 +1,118 @@
+package httpserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestServer serves the memory storage backend over an in-process
+// connection to the gateway book routes, auth is left out
+func newTestServer(t *testing.T, cache BookCache) (*echo.Echo, *HttpServer) {
+	t.Helper()
+
+	logger := zerolog.Nop()
+
+	serv, err := NewHttpServer(&logger, storageservice.NewStorageServiceClient(newTestStorage(t)),
+		tracesdk.NewTracerProvider(), cache, nil)
+	if err != nil {
+		t.Fatalf("NewHttpServer() error = %v", err)
+	}
+
+	e := echo.New()
+	e.Validator = NewValidator()
+	e.HTTPErrorHandler = serv.ErrorHandler
+
+	e.GET("/storage/export", serv.ExportValues)
+	e.GET("/storage/:id", serv.GetValueById)
+	e.POST("/storage", serv.AddValue)
+	e.POST("/storage/import", serv.ImportValues)
+	e.PUT("/storage/:id", serv.UpdateValue)
+	e.PATCH("/storage/:id", serv.PatchValue)
+	e.DELETE("/storage/:id", serv.DeleteValue)
+
+	return e, serv
+}
+
+// newTestStorage is a connection to the memory storage backend served in-process
+func newTestStorage(t *testing.T, opts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	tracer := tracesdk.NewTracerProvider()
+
+	storage, err := storageservice.NewStorageService(context.Background(), storageservice.StorageServiceOpts{
+		Tracer:  tracer,
+		Logger:  &logger,
+		Backend: storageservice.BackendMemory,
+	})
+	if err != nil {
+		t.Fatalf("NewStorageService() error = %v", err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	grpcServ := grpc.NewServer(opts...)
+	storageservice.RegisterStorageServiceServer(grpcServ, storage)
+	go func() { _ = grpcServ.Serve(listener) }()
+	t.Cleanup(grpcServ.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(PrincipalUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(PrincipalStreamInterceptor),
+	)
+	if err != nil {
+		t.Fatalf("grpc.Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+// serve runs a request with JSON or the given content type body
+func serve(e *echo.Echo, method, target, contentType, body string, headers ...string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+
+	req := httptest.NewRequest(method, target, reader)
+	if contentType == "" {
+		contentType = echo.MIMEApplicationJSON
+	}
+	req.Header.Set(echo.HeaderContentType, contentType)
+
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func mustServe(t *testing.T, e *echo.Echo, want int, method, target, body string, headers ...string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := serve(e, method, target, "", body, headers...)
+	if rec.Code != want {
+		t.Fatalf("%s %s = %d %s, want %d", method, target, rec.Code, rec.Body, want)
+	}
+
+	return rec
+}
@@ -0,0 +1,282 @@
+package httpserver
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	MIMEApplicationJSONLines = "application/jsonl"
+	MIMEApplicationNDJSON    = "application/x-ndjson"
+	MIMETextCSV              = "text/csv"
+
+	maxImportLineSize = 1 << 20
+)
+
+// importRow is a parsed source row, err is set when it cannot be sent to storage
+type importRow struct {
+	line int
+	book BookToAdd
+	err  *ImportRowError
+}
+
+// ImportValues streams books from JSONL or CSV body to storage,
+// CSV must start with a header naming the columns as BookToAdd json fields
+func (serv *HttpServer) ImportValues(ctx echo.Context) error {
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+
+	var rows func(yield func(importRow) error) error
+
+	switch mediaType {
+	case MIMEApplicationJSONLines, MIMEApplicationNDJSON:
+		rows = func(yield func(importRow) error) error {
+			return readJSONLines(ctx.Request().Body, yield)
+		}
+	case MIMETextCSV:
+		rows = func(yield func(importRow) error) error {
+			return readCSV(ctx.Request().Body, yield)
+		}
+	default:
+		return newApiError(http.StatusUnsupportedMediaType,
+			"want "+MIMEApplicationJSONLines+", "+MIMEApplicationNDJSON+" or "+MIMETextCSV)
+	}
+
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"ImportValues",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("content_type"),
+				Value: attribute.StringValue(mediaType),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	stream, err := serv.storageClient.ImportBooks(distCtx)
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	var (
+		rejected []ImportRowError
+		lastLine int
+	)
+
+	err = rows(func(row importRow) error {
+		lastLine = row.line
+
+		if row.err == nil {
+			row.err = serv.validateImportRow(ctx, row)
+		}
+
+		if row.err != nil {
+			rejected = append(rejected, *row.err)
+			return nil
+		}
+
+		// bios filled in for existing authors reach cached books once they expire
+		return stream.Send(&storageservice.ImportBookRow{
+			Row: int32(row.line),
+			Book: &storageservice.SetValueRequest{
+				Title:        row.book.Title,
+				AuthorId:     row.book.AuthorID,
+				Author:       row.book.Author,
+				Price:        row.book.Price.legacy(),
+				PriceDecimal: row.book.Price.decimal(),
+				Currency:     row.book.Currency,
+				Prices:       pricesToRequest(row.book.Prices),
+				Description:  row.book.Description,
+				AuthorBio:    row.book.AuthorBio,
+			},
+		})
+	})
+
+	// io.EOF from Send means storage has ended the stream, its error comes from CloseAndRecv.
+	// Storage commits rows sent before an unreadable line, they are reported
+	// so that the client goes on after that line instead of importing them again
+	var readErr *ImportRowError
+	if err != nil && err != io.EOF {
+		readErr = &ImportRowError{Row: lastLine + 1, Message: "cannot read import body: " + err.Error()}
+		if errors.Is(err, bufio.ErrTooLong) {
+			readErr.Message = fmt.Sprintf("cannot read import body: line is longer than %d bytes", maxImportLineSize)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	summary := ImportSummary{
+		Received: int(resp.Received) + len(rejected),
+		Imported: int(resp.Imported),
+		Failed:   int(resp.Failed) + len(rejected),
+		Errors:   rejected,
+	}
+
+	for _, e := range resp.Errors {
+		rowErr := ImportRowError{Row: int(e.Row), Message: e.Message}
+		for field, message := range e.Fields {
+			rowErr.Fields = append(rowErr.Fields, FieldError{Field: field, Message: message})
+		}
+		summary.Errors = append(summary.Errors, rowErr)
+	}
+
+	if readErr != nil {
+		summary.Incomplete = true
+		summary.Failed++
+		summary.Errors = append(summary.Errors, *readErr)
+	}
+
+	span.SetAttributes(
+		attribute.Bool("import.incomplete", summary.Incomplete),
+		attribute.Int("import.received", summary.Received),
+		attribute.Int("import.imported", summary.Imported),
+		attribute.Int("import.failed", summary.Failed),
+	)
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+
+	return ctx.JSON(http.StatusOK, summary)
+}
+
+func (serv *HttpServer) validateImportRow(ctx echo.Context, row importRow) *ImportRowError {
+	err := ctx.Validate(&row.book)
+	if err == nil {
+		return nil
+	}
+
+	rowErr := &ImportRowError{Row: row.line, Message: "invalid book"}
+
+	fieldErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		rowErr.Message = err.Error()
+		return rowErr
+	}
+
+	for _, fe := range fieldErrs {
+		rowErr.Fields = append(rowErr.Fields, FieldError{
+			Field:   fe.Field(),
+			Message: fieldErrorMessage(fe),
+		})
+	}
+
+	return rowErr
+}
+
+func readJSONLines(body io.Reader, yield func(importRow) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), maxImportLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		row := importRow{line: line}
+		if err := json.Unmarshal([]byte(text), &row.book); err != nil {
+			row.err = &ImportRowError{Row: line, Message: "malformed json: " + err.Error()}
+		}
+
+		if err := yield(row); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func readCSV(body io.Reader, yield func(importRow) error) error {
+	reader := csv.NewReader(body)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			parseErr, ok := err.(*csv.ParseError)
+			if !ok {
+				return err
+			}
+
+			// the reader can go on after a malformed record,
+			// FieldPos panics after an error so the line comes from parseErr
+			rowErr := &ImportRowError{Row: parseErr.Line, Message: parseErr.Error()}
+			if err := yield(importRow{line: parseErr.Line, err: rowErr}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		row := importRow{
+			line: line,
+			book: BookToAdd{
+				Title:       field(record, "title"),
+				Author:      field(record, "author"),
+				Price:       Price(field(record, "price")),
+				Currency:    field(record, "currency"),
+				Description: field(record, "description"),
+				AuthorBio:   field(record, "author_bio"),
+			},
+		}
+
+		if v := field(record, "author_id"); v != "" {
+			authorID, err := strconv.Atoi(v)
+			if err != nil {
+				row.err = &ImportRowError{Row: line, Message: "wrong author_id format"}
+			}
+			row.book.AuthorID = int32(authorID)
+		}
+
+		if err := yield(row); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestImportValuesCSV(t *testing.T) {
+	e, _ := newTestServer(t, nil)
+
+	body := strings.Join([]string{
+		"title,author,price,currency",
+		"Book,Author,10.50,EUR",
+		`Bad "quote,Author,1.00,EUR`,
+		"Short row,Author",
+		",Author,1.00,EUR",
+		"Other book,Author,2.00,USD",
+	}, "\n") + "\n"
+
+	rec := serve(e, http.MethodPost, "/storage/import", MIMETextCSV, body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /storage/import = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	var summary ImportSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("summary %s: %v", rec.Body, err)
+	}
+
+	if summary.Received != 5 || summary.Imported != 2 || summary.Failed != 3 {
+		t.Fatalf("summary = %+v, want 5 received, 2 imported and 3 failed", summary)
+	}
+
+	rows := map[int]string{}
+	for _, rowErr := range summary.Errors {
+		rows[rowErr.Row] = rowErr.Message
+	}
+
+	for row, want := range map[int]string{3: "bare \"", 4: "wrong number of fields", 5: "invalid book"} {
+		if !strings.Contains(rows[row], want) {
+			t.Errorf("error of row %d = %q, want %q", row, rows[row], want)
+		}
+	}
+}
+
+func TestImportValuesUnreadableLine(t *testing.T) {
+	e, _ := newTestServer(t, nil)
+
+	body := strings.Join([]string{
+		`{"title":"Book","author":"Author","price":"1.00"}`,
+		`{"title":"` + strings.Repeat("x", maxImportLineSize) + `","author":"Author","price":"1.00"}`,
+		`{"title":"Not read","author":"Author","price":"1.00"}`,
+	}, "\n") + "\n"
+
+	rec := serve(e, http.MethodPost, "/storage/import", MIMEApplicationJSONLines, body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /storage/import = %d %s, want 200", rec.Code, rec.Body)
+	}
+
+	var summary ImportSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("summary %s: %v", rec.Body, err)
+	}
+
+	// the row before the long line is imported and reported as such
+	if !summary.Incomplete || summary.Received != 1 || summary.Imported != 1 || summary.Failed != 1 {
+		t.Fatalf("summary = %+v, want incomplete with 1 imported and 1 failed", summary)
+	}
+	if len(summary.Errors) != 1 || summary.Errors[0].Row != 2 || !strings.Contains(summary.Errors[0].Message, "longer than") {
+		t.Fatalf("errors = %+v, want line 2 too long", summary.Errors)
+	}
+
+	mustServe(t, e, http.StatusOK, http.MethodGet, "/storage/1", "")
+	mustServe(t, e, http.StatusNotFound, http.MethodGet, "/storage/2", "")
+}
@@ -1,14 +1,153 @@
 package httpserver
 
+import (
+	"time"
+
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+)
+
 type Book struct {
 	BookID int32 `json:"book_id"`
 	BookToAdd
+	// Quote is the price in currency asked by query parameter
+	Quote *PriceQuote `json:"quote,omitempty"`
+}
+
+const (
+	PriceSourceNative    = "native"
+	PriceSourceConverted = "converted"
+)
+
+// PriceQuote is book price in a requested currency, either set for that market
+// or converted from the base price with the rate table of RateTimestamp
+type PriceQuote struct {
+	Currency      string     `json:"currency"`
+	Amount        Price      `json:"amount"`
+	Source        string     `json:"source"` // native or converted
+	ConvertedFrom string     `json:"converted_from,omitempty"`
+	Rate          string     `json:"rate,omitempty"`
+	RateTimestamp *time.Time `json:"rate_timestamp,omitempty"`
 }
 
+// limits follow column sizes in storageservice migrations; Currency is ISO 4217 code
+// of Price, RUB if empty, and Prices are native prices for other markets.
+// AuthorID picks an existing author, otherwise the author is found by name or created
+// with AuthorBio; the bio is shared by all books of the author
 type BookToAdd struct {
-	Title       string  `json:"title"`
-	Author      string  `json:"author"`
-	Price       float64 `json:"price"`
-	Description string  `json:"description"`
-	AuthorBio   string  `json:"author_bio"`
+	Title       string           `json:"title" validate:"required,max=100"`
+	AuthorID    int32            `json:"author_id,omitempty"`
+	Author      string           `json:"author" validate:"required_without=AuthorID,max=100"`
+	Price       Price            `json:"price" validate:"price"`
+	Currency    string           `json:"currency,omitempty" validate:"omitempty,iso4217"`
+	Prices      map[string]Price `json:"prices,omitempty" validate:"dive,keys,iso4217,endkeys,price"`
+	Description string           `json:"description" validate:"max=3001"`
+	AuthorBio   string           `json:"author_bio" validate:"max=3000"`
+}
+
+// BookToPatch holds partial update, nil fields are left unchanged;
+// Prices are merged into native prices and null amount removes the currency
+type BookToPatch struct {
+	Title       *string           `json:"title" validate:"omitnil,min=1,max=100"`
+	AuthorID    *int32            `json:"author_id"`
+	Author      *string           `json:"author" validate:"omitnil,min=1,max=100"`
+	Price       *Price            `json:"price" validate:"omitnil,price"`
+	Currency    *string           `json:"currency" validate:"omitnil,iso4217"`
+	Prices      map[string]*Price `json:"prices" validate:"dive,keys,iso4217,endkeys,omitnil,price"`
+	Description *string           `json:"description" validate:"omitnil,max=3001"`
+	AuthorBio   *string           `json:"author_bio" validate:"omitnil,max=3000"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ErrorResponse is the body of every failed response
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Fields    []FieldError `json:"fields,omitempty"`
+	TraceId   string       `json:"trace_id,omitempty"`
+	RequestId string       `json:"request_id,omitempty"`
+}
+
+type BookList struct {
+	Books      []Book `json:"books"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func bookFromResponse(resp *storageservice.GetValueResponse) Book {
+	return Book{
+		BookID: resp.Id,
+		BookToAdd: BookToAdd{
+			Title:       resp.Title,
+			AuthorID:    resp.AuthorId,
+			Author:      resp.Author,
+			Price:       priceFromResponse(resp),
+			Currency:    responseCurrency(resp),
+			Prices:      pricesFromResponse(resp),
+			Description: resp.Description,
+			AuthorBio:   resp.AuthorBio,
+		},
+	}
+}
+
+type DeletedBook struct {
+	Book
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+type TrashList struct {
+	Books      []DeletedBook `json:"books"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type SearchHit struct {
+	Book
+	Rank           float32 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
+
+type SearchResult struct {
+	Hits []SearchHit `json:"hits"`
+}
+
+type ImportRowError struct {
+	Row     int          `json:"row"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+type ImportSummary struct {
+	Received int              `json:"received"`
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+	// Incomplete means the body could not be read to the end, rows before
+	// the last error are imported and the rest is not read
+	Incomplete bool `json:"incomplete,omitempty"`
+}
+
+type Author struct {
+	AuthorID int32  `json:"author_id"`
+	Name     string `json:"name"`
+	Bio      string `json:"bio"`
+}
+
+type AuthorList struct {
+	Authors    []Author `json:"authors"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
+
+func authorFromResponse(resp *storageservice.Author) Author {
+	return Author{
+		AuthorID: resp.Id,
+		Name:     resp.Name,
+		Bio:      resp.Bio,
+	}
 }
@@ -0,0 +1,560 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// OpenAPI 3 document, only the parts used by the gateway
+
+type OpenApi struct {
+	OpenApi    string                          `json:"openapi"`
+	Info       OpenApiInfo                     `json:"info"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components Components                      `json:"components"`
+}
+
+type OpenApiInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Operation struct {
+	OperationId string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Name         string `json:"name,omitempty"`
+	In           string `json:"in,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+}
+
+// apiOperation describes a route for the spec, path is in echo format
+type apiOperation struct {
+	Method   string
+	Path     string
+	Id       string
+	Summary  string
+	Tag      string
+	Scope    string      // required scope, empty for public routes
+	Params   []Parameter // query and header params
+	Body     interface{} // nil for requests without body
+	Consumes []string    // raw body media types, used instead of Body
+	Response interface{} // nil for responses without body
+	Produces []string    // raw response media types, used instead of Response
+	Status   int
+}
+
+const ifMatchDescription = "ETag from GET /storage/{id} or *, 412 is returned if the book has changed since"
+
+var currencyParam = queryParam("currency", "string",
+	"ISO 4217 code to quote prices in, native price for that market or the base one converted by current rates")
+
+var apiOperations = []apiOperation{
+	{
+		Method: http.MethodGet, Path: "/storage", Id: "listBooks", Tag: "books", Scope: ScopeBooksRead,
+		Summary: "List books page by page, filtered and sorted",
+		Params: []Parameter{
+			queryParam("page_size", "integer", "books per page, 20 by default, 100 at most"),
+			queryParam("cursor", "string", "next_cursor from the previous page, rejected with other filter or sorting"),
+			queryParam("author", "string", "exact author name"),
+			queryParam("author_id", "integer", "author id"),
+			queryParam("title", "string", "title substring, case insensitive"),
+			queryParam("min_price", "number", "lowest price, inclusive, at most 2 fraction digits"),
+			queryParam("max_price", "number", "highest price, inclusive, at most 2 fraction digits"),
+			enumParam("sort_by", "sort field, id by default", "id", "title", "price"),
+			enumParam("order", "sort direction, asc by default", "asc", "desc"),
+			currencyParam,
+		},
+		Response: BookList{},
+	},
+	{
+		Method: http.MethodGet, Path: "/storage/search", Id: "searchBooks", Tag: "books", Scope: ScopeBooksRead,
+		Summary: "Full-text search over title, description and author bio",
+		Params: []Parameter{
+			requiredParam(queryParam("q", "string", "search query, web search syntax")),
+			queryParam("limit", "integer", "hits to return, 20 by default, 100 at most"),
+			currencyParam,
+		},
+		Response: SearchResult{},
+	},
+	{
+		Method: http.MethodGet, Path: "/storage/export", Id: "exportBooks", Tag: "books", Scope: ScopeBooksRead,
+		Summary: "Stream all books in id order as a file",
+		Params: []Parameter{
+			enumParam("format", "jsonl by default, protobuf is length-delimited GetValueResponse messages", "csv", "jsonl", "protobuf"),
+			queryParam("author", "string", "exact author name"),
+			queryParam("author_id", "integer", "author id"),
+			queryParam("title", "string", "title substring, case insensitive"),
+			queryParam("min_price", "number", "lowest price, inclusive, at most 2 fraction digits"),
+			queryParam("max_price", "number", "highest price, inclusive, at most 2 fraction digits"),
+		},
+		Produces: []string{MIMETextCSV, MIMEApplicationNDJSON, MIMEApplicationProtobuf},
+	},
+	{
+		Method: http.MethodGet, Path: "/storage/:id", Id: "getBook", Tag: "books", Scope: ScopeBooksRead,
+		Summary: "Get book by id, ETag header holds book version",
+		Params: []Parameter{
+			headerParam(HeaderIfNoneMatch, "ETag of a cached copy, 304 is returned if the book is unchanged"),
+			currencyParam,
+		},
+		Response: Book{},
+	},
+	{
+		Method: http.MethodPost, Path: "/storage", Id: "addBook", Tag: "books", Scope: ScopeBooksWrite,
+		Summary: "Add book, responds with its id",
+		Params: []Parameter{
+			headerParam(HeaderIdempotencyKey, "repeated requests with the same key return the first result, "+
+				"reusing the key with a different body fails with 422"),
+		},
+		Body:     BookToAdd{},
+		Response: int32(0),
+	},
+	{
+		Method: http.MethodPost, Path: "/storage/import", Id: "importBooks", Tag: "books", Scope: ScopeBooksWrite,
+		Summary: "Import books from JSON lines or CSV with a header row, responds with per-row results; " +
+			"an unreadable line stops the import with incomplete set, rows before it stay imported",
+		Consumes: []string{MIMEApplicationJSONLines, MIMEApplicationNDJSON, MIMETextCSV},
+		Response: ImportSummary{},
+	},
+	{
+		Method: http.MethodPut, Path: "/storage/:id", Id: "updateBook", Tag: "books", Scope: ScopeBooksWrite,
+		Summary: "Replace all book fields",
+		Params: []Parameter{
+			requiredParam(headerParam(HeaderIfMatch, ifMatchDescription)),
+		},
+		Body:     BookToAdd{},
+		Response: Book{},
+	},
+	{
+		Method: http.MethodPatch, Path: "/storage/:id", Id: "patchBook", Tag: "books", Scope: ScopeBooksWrite,
+		Summary: "Update given book fields",
+		Params: []Parameter{
+			requiredParam(headerParam(HeaderIfMatch, ifMatchDescription)),
+		},
+		Body:     BookToPatch{},
+		Response: Book{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/storage/:id", Id: "deleteBook", Tag: "books", Scope: ScopeBooksWrite,
+		Summary: "Move book to trash, it can be restored until purged after retention period",
+		Params: []Parameter{
+			headerParam(HeaderIfMatch, ifMatchDescription),
+		},
+		Status: http.StatusNoContent,
+	},
+	{
+		Method: http.MethodPost, Path: "/storage/:id/restore", Id: "restoreBook", Tag: "books", Scope: ScopeBooksWrite,
+		Summary:  "Restore deleted book, 404 if it is not in trash or has been purged",
+		Response: Book{},
+	},
+	{
+		Method: http.MethodGet, Path: "/authors", Id: "listAuthors", Tag: "authors", Scope: ScopeBooksRead,
+		Summary: "List authors page by page in id order",
+		Params: []Parameter{
+			queryParam("page_size", "integer", "authors per page, 20 by default, 100 at most"),
+			queryParam("cursor", "string", "next_cursor from the previous page"),
+			queryParam("name", "string", "name substring, case insensitive"),
+		},
+		Response: AuthorList{},
+	},
+	{
+		Method: http.MethodGet, Path: "/authors/:id", Id: "getAuthor", Tag: "authors", Scope: ScopeBooksRead,
+		Summary:  "Get author by id",
+		Response: Author{},
+	},
+	{
+		Method: http.MethodGet, Path: "/authors/:id/books", Id: "listAuthorBooks", Tag: "authors", Scope: ScopeBooksRead,
+		Summary: "List books of the author page by page, 404 if there is no such author",
+		Params: []Parameter{
+			queryParam("page_size", "integer", "books per page, 20 by default, 100 at most"),
+			queryParam("cursor", "string", "next_cursor from the previous page"),
+			enumParam("sort_by", "sort field, id by default", "id", "title", "price"),
+			enumParam("order", "sort direction, asc by default", "asc", "desc"),
+			currencyParam,
+		},
+		Response: BookList{},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/trash", Id: "listTrash", Tag: "admin", Scope: ScopeAdmin,
+		Summary: "List deleted books in id order until they are purged",
+		Params: []Parameter{
+			queryParam("page_size", "integer", "books per page, 20 by default, 100 at most"),
+			queryParam("cursor", "string", "next_cursor from the previous page"),
+		},
+		Response: TrashList{},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/rates", Id: "getRates", Tag: "admin", Scope: ScopeAdmin,
+		Summary:  "Exchange rates used to convert prices",
+		Response: RateTable{},
+	},
+	{
+		Method: http.MethodPut, Path: "/admin/rates", Id: "setRates", Tag: "admin", Scope: ScopeAdmin,
+		Summary:  "Replace exchange rates, timestamp defaults to now",
+		Body:     RateTable{},
+		Response: RateTable{},
+	},
+	{
+		Method: http.MethodPost, Path: "/admin/rates/reload", Id: "reloadRates", Tag: "admin", Scope: ScopeAdmin,
+		Summary:  "Reload exchange rates from RATES_FILE",
+		Response: RateTable{},
+	},
+	{
+		Method: http.MethodGet, Path: "/openapi.json", Id: "getOpenApi", Tag: "docs",
+		Summary:  "This document",
+		Response: map[string]interface{}{},
+	},
+	{
+		Method: http.MethodGet, Path: "/docs", Id: "getDocs", Tag: "docs",
+		Summary: "Swagger UI, redirects to /docs/",
+		Status:  http.StatusMovedPermanently,
+	},
+	{
+		Method: http.MethodGet, Path: "/docs/*", Id: "getDocsFile", Tag: "docs",
+		Summary: "Swagger UI files",
+		Status:  http.StatusOK,
+	},
+}
+
+var (
+	openApiOnce sync.Once
+	openApiSpec *OpenApi
+)
+
+// OpenApiSpec builds the spec from apiOperations, schemas are generated from models
+func OpenApiSpec() *OpenApi {
+	openApiOnce.Do(func() {
+		openApiSpec = buildOpenApi(apiOperations)
+	})
+
+	return openApiSpec
+}
+
+// SpecPath converts echo route path to OpenAPI one, e.g. /storage/:id to /storage/{id}
+func SpecPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		switch {
+		case strings.HasPrefix(s, ":"):
+			segments[i] = "{" + s[1:] + "}"
+		case s == "*":
+			segments[i] = "{file}"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func (serv *HttpServer) OpenApi(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, OpenApiSpec())
+}
+
+func buildOpenApi(ops []apiOperation) *OpenApi {
+	gen := &schemaGenerator{schemas: map[string]*Schema{}}
+
+	errorResponse := Response{
+		Description: "error",
+		Content:     jsonContent(gen.schemaOf(reflect.TypeOf(ErrorResponse{}))),
+	}
+
+	spec := &OpenApi{
+		OpenApi: "3.0.3",
+		Info: OpenApiInfo{
+			Title:   "observer gateway",
+			Version: "0.0.1",
+		},
+		Paths: map[string]map[string]Operation{},
+	}
+
+	for _, op := range ops {
+		operation := Operation{
+			OperationId: op.Id,
+			Summary:     op.Summary,
+			Tags:        []string{op.Tag},
+			Parameters:  append(pathParams(op.Path), op.Params...),
+			Responses: map[string]Response{
+				"default": errorResponse,
+			},
+		}
+
+		if op.Scope != "" {
+			operation.Security = []map[string][]string{
+				{"apiKey": {op.Scope}},
+				{"bearer": {op.Scope}},
+			}
+		}
+
+		if op.Body != nil {
+			operation.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(gen.schemaOf(reflect.TypeOf(op.Body))),
+			}
+		}
+
+		if len(op.Consumes) > 0 {
+			operation.RequestBody = &RequestBody{Required: true, Content: binaryContent(op.Consumes)}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		resp := Response{
+			Description: http.StatusText(status),
+			Headers: map[string]Header{
+				"Trace-Id": {Description: "trace to look up in Jaeger", Schema: &Schema{Type: "string"}},
+			},
+		}
+		if op.Response != nil {
+			resp.Content = jsonContent(gen.schemaOf(reflect.TypeOf(op.Response)))
+		}
+		if len(op.Produces) > 0 {
+			resp.Content = binaryContent(op.Produces)
+		}
+		operation.Responses[strconv.Itoa(status)] = resp
+
+		path := SpecPath(op.Path)
+		if spec.Paths[path] == nil {
+			spec.Paths[path] = map[string]Operation{}
+		}
+		spec.Paths[path][strings.ToLower(op.Method)] = operation
+	}
+
+	spec.Components.Schemas = gen.schemas
+	spec.Components.SecuritySchemes = map[string]SecurityScheme{
+		"apiKey": {
+			Type:        "apiKey",
+			Name:        HeaderApiKey,
+			In:          "header",
+			Description: "static key with books:read, books:write and/or admin scopes",
+		},
+		"bearer": {
+			Type:         "http",
+			Scheme:       "bearer",
+			BearerFormat: "JWT",
+			Description:  "HS256 or RS256 token, sub claim is the principal, scope claim lists space separated scopes",
+		},
+	}
+
+	return spec
+}
+
+func pathParams(path string) []Parameter {
+	var params []Parameter
+
+	for _, s := range strings.Split(path, "/") {
+		switch {
+		case s == ":id":
+			params = append(params, Parameter{Name: "id", In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int32"}})
+		case strings.HasPrefix(s, ":"):
+			params = append(params, Parameter{Name: s[1:], In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		case s == "*":
+			params = append(params, Parameter{Name: "file", In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+
+	return params
+}
+
+func queryParam(name, typ, description string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: typ}}
+}
+
+func headerParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+func enumParam(name, description string, values ...string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string", Enum: values}}
+}
+
+func requiredParam(p Parameter) Parameter {
+	p.Required = true
+	return p
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{echo.MIMEApplicationJSON: {Schema: schema}}
+}
+
+func binaryContent(mediaTypes []string) map[string]MediaType {
+	content := map[string]MediaType{}
+	for _, mediaType := range mediaTypes {
+		content[mediaType] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+
+	return content
+}
+
+// schemaGenerator makes schemas from go types using json and validate tags,
+// structs go to components and are referenced by name
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+func (gen *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	switch t {
+	case reflect.TypeOf(Price("")):
+		minimum := 0.0
+		return &Schema{Type: "number", Format: "decimal", Minimum: &minimum}
+	case reflect.TypeOf(json.Number("")):
+		return &Schema{Type: "number", Format: "decimal"}
+	case reflect.TypeOf(time.Time{}):
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := gen.schemaOf(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice:
+		return &Schema{Type: "array", Items: gen.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: gen.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if _, ok := gen.schemas[t.Name()]; !ok {
+			schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			gen.schemas[t.Name()] = schema // set before fields for recursive types
+			gen.addFields(schema, t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+func (gen *schemaGenerator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Anonymous {
+			gen.addFields(schema, field.Type)
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+
+		prop := gen.schemaOf(field.Type)
+		if prop.Ref == "" {
+			applyValidateTag(schema, prop, name, field.Tag.Get("validate"))
+		}
+
+		schema.Properties[name] = prop
+	}
+}
+
+// applyValidateTag turns validator rules into schema constraints
+func applyValidateTag(parent, prop *Schema, name, tag string) {
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+
+		// rules after dive apply to elements
+		if key == "dive" {
+			return
+		}
+
+		switch key {
+		case "required":
+			parent.Required = append(parent.Required, name)
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil || prop.Type != "string" {
+				continue
+			}
+			if key == "min" {
+				prop.MinLength = &n
+			} else {
+				prop.MaxLength = &n
+			}
+		case "gte":
+			n, err := strconv.ParseFloat(param, 64)
+			if err == nil {
+				prop.Minimum = &n
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+)
+
+// Price is an exact decimal amount written to JSON as a number with
+// 2 fraction digits, e.g. 680.00; numbers and strings are accepted on input
+// so clients sending float prices keep working
+type Price string
+
+func (p Price) MarshalJSON() ([]byte, error) {
+	normalized, err := storageservice.NormalizePrice(string(p))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(normalized), nil
+}
+
+func (p *Price) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*p = Price(s)
+		return nil
+	}
+
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*p = Price(n)
+
+	return nil
+}
+
+// decimal is the price for storage requests, Price is validated by then
+func (p Price) decimal() string {
+	normalized, _ := storageservice.NormalizePrice(string(p))
+	return normalized
+}
+
+// legacy fills deprecated float fields for storage versions without price_decimal
+func (p Price) legacy() float32 {
+	f, _ := strconv.ParseFloat(p.decimal(), 32)
+	return float32(f)
+}
+
+// priceFromResponse prefers decimal price and falls back to the float one
+// returned by storage versions without price_decimal; a NULL price has
+// neither and renders as 0.00
+func priceFromResponse(resp *storageservice.GetValueResponse) Price {
+	if resp.PriceDecimal != "" {
+		return Price(resp.PriceDecimal)
+	}
+
+	return Price(strconv.FormatFloat(float64(resp.Price), 'f', 2, 32))
+}
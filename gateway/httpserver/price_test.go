@@ -0,0 +1,78 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"testing"
+
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+)
+
+func TestPriceJSON(t *testing.T) {
+	marshal := []struct {
+		price   Price
+		want    string
+		wantErr bool
+	}{
+		{"680", "680.00", false},
+		{".5", "0.50", false},
+		{"007.10", "7.10", false},
+		{"", "0.00", false}, // books without price render as free
+		{"1.999", "", true},
+		{"1e3", "", true},
+		{"-1", "", true},
+	}
+
+	for _, tt := range marshal {
+		got, err := json.Marshal(tt.price)
+		if (err != nil) != tt.wantErr || (err == nil && string(got) != tt.want) {
+			t.Errorf("Marshal(%q) = %s, %v, want %s", tt.price, got, err, tt.want)
+		}
+	}
+
+	unmarshal := []struct {
+		data    string
+		want    Price
+		wantErr bool
+	}{
+		{`19.99`, "19.99", false},
+		{`"19.99"`, "19.99", false},
+		{`680`, "680", false},
+		{`1e3`, "1e3", false}, // kept as sent, validation rejects it
+		{`null`, "unchanged", false},
+		{`true`, "unchanged", true},
+		{`"19.99`, "unchanged", true},
+	}
+
+	for _, tt := range unmarshal {
+		p := Price("unchanged")
+		err := json.Unmarshal([]byte(tt.data), &p)
+		if (err != nil) != tt.wantErr || p != tt.want {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q", tt.data, p, err, tt.want)
+		}
+	}
+}
+
+func TestPriceConversions(t *testing.T) {
+	if got := Price("19.99").legacy(); got != float32(19.99) {
+		t.Errorf("legacy() = %v, want 19.99", got)
+	}
+	if got := Price("7.5").decimal(); got != "7.50" {
+		t.Errorf("decimal() = %q, want 7.50", got)
+	}
+
+	tests := []struct {
+		resp *storageservice.GetValueResponse
+		want Price
+	}{
+		{&storageservice.GetValueResponse{PriceDecimal: "12.30", Price: 99}, "12.30"},
+		{&storageservice.GetValueResponse{Price: 19.99}, "19.99"},
+		// NULL price of the database comes without decimal and as float 0
+		{&storageservice.GetValueResponse{}, "0.00"},
+	}
+
+	for _, tt := range tests {
+		if got := priceFromResponse(tt.resp); got != tt.want {
+			t.Errorf("priceFromResponse(%v) = %q, want %q", tt.resp, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package httpserver
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+)
+
+var sortFields = map[string]storageservice.SortField{
+	"id":    storageservice.SortField_SORT_FIELD_ID,
+	"title": storageservice.SortField_SORT_FIELD_TITLE,
+	"price": storageservice.SortField_SORT_FIELD_PRICE,
+}
+
+// listRequestFromQuery reads paging, filter and sort params of GET /storage
+func listRequestFromQuery(ctx echo.Context) (*storageservice.ListValuesRequest, error) {
+	req := &storageservice.ListValuesRequest{
+		Cursor: ctx.QueryParam("cursor"),
+	}
+
+	if ps := ctx.QueryParam("page_size"); ps != "" {
+		pageSize, err := strconv.Atoi(ps)
+		if err != nil {
+			return nil, errors.New("wrong page_size format")
+		}
+		req.PageSize = int32(pageSize)
+	}
+
+	filter, err := filterFromQuery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Filter = filter
+
+	if sortBy := ctx.QueryParam("sort_by"); sortBy != "" {
+		field, ok := sortFields[sortBy]
+		if !ok {
+			return nil, fmt.Errorf("unknown sort_by %q, want id, title or price", sortBy)
+		}
+		req.SortBy = field
+	}
+
+	switch order := ctx.QueryParam("order"); order {
+	case "", "asc":
+	case "desc":
+		req.Descending = true
+	default:
+		return nil, fmt.Errorf("unknown order %q, want asc or desc", order)
+	}
+
+	return req, nil
+}
+
+// filterFromQuery reads author, author_id, title, min_price and max_price params
+func filterFromQuery(ctx echo.Context) (*storageservice.BookFilter, error) {
+	filter := &storageservice.BookFilter{}
+
+	if author := ctx.QueryParam("author"); author != "" {
+		filter.Author = &author
+	}
+
+	if v := ctx.QueryParam("author_id"); v != "" {
+		authorID, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("wrong author_id format")
+		}
+		id := int32(authorID)
+		filter.AuthorId = &id
+	}
+
+	if title := ctx.QueryParam("title"); title != "" {
+		filter.TitleContains = &title
+	}
+
+	for param, dst := range map[string]**string{
+		"min_price": &filter.MinPriceDecimal,
+		"max_price": &filter.MaxPriceDecimal,
+	} {
+		if v := ctx.QueryParam(param); v != "" {
+			price, err := storageservice.NormalizePrice(v)
+			if err != nil {
+				return nil, fmt.Errorf("wrong %s format, want decimal like 680.00", param)
+			}
+			*dst = &price
+		}
+	}
+
+	return filter, nil
+}
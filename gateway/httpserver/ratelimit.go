@@ -0,0 +1,164 @@
+package httpserver
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+)
+
+const (
+	HeaderApiKey             = "X-Api-Key"
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+
+	// clients idle for this long lose their bucket, a new one starts full
+	rateLimitIdleTTL = 10 * time.Minute
+)
+
+// RateLimit is a token bucket refilled by Rate tokens per second up to Burst,
+// zero Rate disables limiting
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+type clientBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter throttles authenticated clients by principal and the others by IP,
+// so that made up credentials cannot get a fresh bucket; read (GET, HEAD, OPTIONS)
+// and write requests have separate buckets
+type RateLimiter struct {
+	read      RateLimit
+	write     RateLimit
+	auth      *Authenticator
+	mutex     sync.Mutex
+	buckets   map[string]*clientBucket
+	lastSweep time.Time
+	throttled *prometheus.CounterVec
+}
+
+// NewRateLimiter limits requests before auth checks them, auth is
+// only used to tell the principal; the client IP comes from echo IPExtractor
+func NewRateLimiter(read, write RateLimit, auth *Authenticator) *RateLimiter {
+	throttled := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_requests_throttled_total",
+			Help: "requests rejected by rate limiter",
+		}, []string{"route", "kind"})
+
+	throttled = register(throttled)
+
+	// bucket of zero size would reject everything
+	for _, limit := range []*RateLimit{&read, &write} {
+		if limit.Burst < 1 {
+			limit.Burst = 1
+		}
+	}
+
+	return &RateLimiter{
+		read:      read,
+		write:     write,
+		auth:      auth,
+		buckets:   map[string]*clientBucket{},
+		lastSweep: time.Now(),
+		throttled: throttled,
+	}
+}
+
+func (rl *RateLimiter) Middleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		kind, limit := "write", rl.write
+		switch ctx.Request().Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			kind, limit = "read", rl.read
+		}
+
+		if limit.Rate <= 0 {
+			return next(ctx)
+		}
+
+		now := time.Now()
+		limiter := rl.limiter(kind+"|"+rl.clientKey(ctx), limit, now)
+
+		header := ctx.Response().Header()
+		header.Set(HeaderRateLimitLimit, strconv.Itoa(limit.Burst))
+
+		if limiter.AllowN(now, 1) {
+			tokens := limiter.TokensAt(now)
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(int(tokens)))
+			header.Set(HeaderRateLimitReset, strconv.Itoa(secondsUntil(float64(limit.Burst)-tokens, limit.Rate)))
+
+			return next(ctx)
+		}
+
+		// reservation is only used to learn when the next token comes
+		reservation := limiter.ReserveN(now, 1)
+		retryAfter := int(math.Ceil(reservation.DelayFrom(now).Seconds()))
+		reservation.CancelAt(now)
+
+		header.Set(HeaderRateLimitRemaining, "0")
+		header.Set(HeaderRateLimitReset, strconv.Itoa(retryAfter))
+		header.Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+		rl.throttled.WithLabelValues(ctx.Path(), kind).Inc()
+
+		trace.SpanFromContext(ctx.Request().Context()).SetAttributes(
+			attribute.Bool("ratelimit.throttled", true),
+		)
+
+		return newApiError(http.StatusTooManyRequests, "rate limit exceeded, retry in "+strconv.Itoa(retryAfter)+"s")
+	}
+}
+
+func (rl *RateLimiter) limiter(key string, limit RateLimit, now time.Time) *rate.Limiter {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if now.Sub(rl.lastSweep) > rateLimitIdleTTL {
+		for k, b := range rl.buckets {
+			if now.Sub(b.lastSeen) > rateLimitIdleTTL {
+				delete(rl.buckets, k)
+			}
+		}
+		rl.lastSweep = now
+	}
+
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket = &clientBucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		rl.buckets[key] = bucket
+	}
+	bucket.lastSeen = now
+
+	return bucket.limiter
+}
+
+// clientKey is the principal of valid credentials, bad ones are not rejected
+// here but share the bucket of their IP
+func (rl *RateLimiter) clientKey(ctx echo.Context) string {
+	if principal := rl.auth.identify(ctx); principal != nil {
+		return "principal:" + principal.Method + ":" + principal.Subject
+	}
+
+	return "ip:" + ctx.RealIP()
+}
+
+func secondsUntil(tokens, perSecond float64) int {
+	if tokens <= 0 {
+		return 0
+	}
+
+	return int(math.Ceil(tokens / perSecond))
+}
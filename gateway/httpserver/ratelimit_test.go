@@ -0,0 +1,146 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog"
+)
+
+func TestRateLimiter(t *testing.T) {
+	logger := zerolog.Nop()
+	auth := NewAuthenticator(AuthConfig{
+		ApiKeys: map[string]Principal{"s3cr3t": {Subject: "reporting", Scopes: []string{ScopeBooksRead}}},
+	}, &logger)
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.HTTPErrorHandler = (&HttpServer{logger: &logger}).ErrorHandler
+	e.Use(NewRateLimiter(RateLimit{Rate: 0.5, Burst: 2}, RateLimit{Rate: 0.5, Burst: 1}, auth).Middleware)
+	e.Any("/storage", func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) })
+
+	// steps run in order against the same limiter, every bucket refills a token in 2s
+	steps := []struct {
+		name          string
+		method        string
+		ip            string
+		headers       map[string]string
+		want          int
+		wantRemaining string
+	}{
+		{"first read", http.MethodGet, "10.0.0.1", nil, http.StatusOK, "1"},
+		{"second read", http.MethodGet, "10.0.0.1", nil, http.StatusOK, "0"},
+		{"read over burst", http.MethodGet, "10.0.0.1", nil, http.StatusTooManyRequests, "0"},
+		{"write has own bucket", http.MethodPost, "10.0.0.1", nil, http.StatusOK, "0"},
+		{"write over burst", http.MethodDelete, "10.0.0.1", nil, http.StatusTooManyRequests, "0"},
+		{"made up api key shares ip bucket", http.MethodGet, "10.0.0.1",
+			map[string]string{HeaderApiKey: "random"}, http.StatusTooManyRequests, "0"},
+		{"made up bearer token shares ip bucket", http.MethodGet, "10.0.0.1",
+			map[string]string{echo.HeaderAuthorization: "Bearer random"}, http.StatusTooManyRequests, "0"},
+		{"forwarded for is not trusted", http.MethodGet, "10.0.0.1",
+			map[string]string{echo.HeaderXForwardedFor: "10.0.0.2"}, http.StatusTooManyRequests, "0"},
+		{"valid api key has own bucket", http.MethodGet, "10.0.0.1",
+			map[string]string{HeaderApiKey: "s3cr3t"}, http.StatusOK, "1"},
+		{"principal keeps its bucket on other ip", http.MethodGet, "10.0.0.3",
+			map[string]string{HeaderApiKey: "s3cr3t"}, http.StatusOK, "0"},
+		{"other ip", http.MethodGet, "10.0.0.2", nil, http.StatusOK, "1"},
+	}
+
+	for _, step := range steps {
+		req := httptest.NewRequest(step.method, "/storage", nil)
+		req.RemoteAddr = step.ip + ":40000"
+		for k, v := range step.headers {
+			req.Header.Set(k, v)
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != step.want {
+			t.Fatalf("%s: status = %d, want %d", step.name, rec.Code, step.want)
+		}
+
+		header := rec.Header()
+		if got := header.Get(HeaderRateLimitRemaining); got != step.wantRemaining {
+			t.Errorf("%s: %s = %q, want %q", step.name, HeaderRateLimitRemaining, got, step.wantRemaining)
+		}
+
+		wantLimit := "2"
+		if step.method != http.MethodGet {
+			wantLimit = "1"
+		}
+		if got := header.Get(HeaderRateLimitLimit); got != wantLimit {
+			t.Errorf("%s: %s = %q, want %q", step.name, HeaderRateLimitLimit, got, wantLimit)
+		}
+
+		if step.want == http.StatusTooManyRequests {
+			if got := header.Get(echo.HeaderRetryAfter); got != "2" {
+				t.Errorf("%s: %s = %q, want 2", step.name, echo.HeaderRetryAfter, got)
+			}
+			if got := header.Get(HeaderRateLimitReset); got != "2" {
+				t.Errorf("%s: %s = %q, want 2", step.name, HeaderRateLimitReset, got)
+			}
+		} else if got := header.Get(echo.HeaderRetryAfter); got != "" {
+			t.Errorf("%s: %s = %q on allowed request", step.name, echo.HeaderRetryAfter, got)
+		}
+	}
+}
+
+func TestRateLimiterSharesPrincipalWithAuth(t *testing.T) {
+	logger := zerolog.Nop()
+	secret := []byte("hs256-secret")
+	auth := NewAuthenticator(AuthConfig{HS256Secret: secret}, &logger)
+
+	claims := jwtClaims{Scope: ScopeBooksRead}
+	claims.Subject, claims.ExpiresAt = "alice", time.Now().Add(time.Hour).Unix()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("SignedString() error = %v", err)
+	}
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.HTTPErrorHandler = (&HttpServer{logger: &logger}).ErrorHandler
+	e.Use(NewRateLimiter(RateLimit{Rate: 10, Burst: 10}, RateLimit{Rate: 10, Burst: 10}, auth).Middleware)
+	// credentials are dropped after the limiter, auth gets the principal it has verified
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			ctx.Request().Header.Del(echo.HeaderAuthorization)
+			return next(ctx)
+		}
+	})
+	e.Use(auth.RequireByMethod)
+	e.GET("/storage", func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, PrincipalFromContext(ctx.Request().Context()).Subject)
+	})
+
+	for _, tt := range []struct {
+		name   string
+		header string
+		want   int
+	}{
+		{"valid token", "Bearer " + token, http.StatusOK},
+		{"bad token", "Bearer abc.def.ghi", http.StatusUnauthorized},
+		{"no credentials", "", http.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/storage", nil)
+		req.RemoteAddr = "10.0.0.1:40000"
+		if tt.header != "" {
+			req.Header.Set(echo.HeaderAuthorization, tt.header)
+		}
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Fatalf("%s: status = %d %s, want %d", tt.name, rec.Code, rec.Body, tt.want)
+		}
+		if tt.want == http.StatusOK && rec.Body.String() != "alice" {
+			t.Errorf("%s: principal = %q, want alice", tt.name, rec.Body)
+		}
+	}
+}
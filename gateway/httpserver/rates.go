@@ -0,0 +1,201 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+)
+
+var (
+	ErrNoRatesFile = errors.New("exchange rates file is not configured")
+	ErrNoRate      = errors.New("no exchange rate")
+)
+
+// RateTable lists how many units of each currency one unit of Base buys,
+// e.g. {"base": "EUR", "rates": {"RUB": "98.12", "USD": "1.0712"}}
+type RateTable struct {
+	Base      string                 `json:"base" validate:"required,iso4217"`
+	Timestamp time.Time              `json:"timestamp"`
+	Rates     map[string]json.Number `json:"rates" validate:"required,dive,keys,iso4217,endkeys,required"`
+}
+
+// Rates is exchange rate table shared by request handlers,
+// it is replaced as a whole so a request never sees a half-loaded table
+type Rates struct {
+	file    string
+	logger  *zerolog.Logger
+	mutex   sync.RWMutex
+	table   RateTable
+	parsed  map[string]*big.Rat
+	modTime time.Time
+}
+
+var currencyValidate = validator.New()
+
+// NewRates loads rates from JSON file, empty file leaves the table empty until Set
+func NewRates(file string, logger *zerolog.Logger) (*Rates, error) {
+	rates := &Rates{file: file, logger: logger}
+
+	if file != "" {
+		if err := rates.Reload(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rates, nil
+}
+
+// Set replaces rate table, zero Timestamp means now
+func (r *Rates) Set(table RateTable) error {
+	if err := currencyValidate.Struct(table); err != nil {
+		return fmt.Errorf("invalid rate table: %w", err)
+	}
+
+	parsed := make(map[string]*big.Rat, len(table.Rates)+1)
+	for currency, rate := range table.Rates {
+		value, ok := new(big.Rat).SetString(rate.String())
+		if !ok || value.Sign() <= 0 {
+			return fmt.Errorf("invalid rate table: rate of %s must be a positive decimal", currency)
+		}
+		parsed[currency] = value
+	}
+
+	if rate, ok := parsed[table.Base]; ok && rate.Cmp(big.NewRat(1, 1)) != 0 {
+		return fmt.Errorf("invalid rate table: rate of base currency %s must be 1", table.Base)
+	}
+	parsed[table.Base] = big.NewRat(1, 1)
+
+	if table.Timestamp.IsZero() {
+		table.Timestamp = time.Now().UTC()
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.table, r.parsed = table, parsed
+
+	return nil
+}
+
+// Table returns current rate table, Base is empty if none is loaded
+func (r *Rates) Table() RateTable {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	return r.table
+}
+
+// Reload reads rate table from the file
+func (r *Rates) Reload() error {
+	if r.file == "" {
+		return ErrNoRatesFile
+	}
+
+	info, err := os.Stat(r.file)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(r.file)
+	if err != nil {
+		return err
+	}
+
+	var table RateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return fmt.Errorf("malformed rates file %s: %w", r.file, err)
+	}
+
+	if err := r.Set(table); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	r.modTime = info.ModTime()
+	r.mutex.Unlock()
+
+	return nil
+}
+
+// Watch reloads rates whenever the file changes, checking it every interval until ctx is done;
+// a broken file is logged and the previous table is kept
+func (r *Rates) Watch(ctx context.Context, interval time.Duration) {
+	if r.file == "" || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(r.file)
+		if err != nil {
+			r.logger.Warn().Err(err).Str("file", r.file).Msg("cannot stat rates file")
+			continue
+		}
+
+		r.mutex.RLock()
+		changed := !info.ModTime().Equal(r.modTime)
+		r.mutex.RUnlock()
+
+		if !changed {
+			continue
+		}
+
+		if err := r.Reload(); err != nil {
+			r.logger.Error().Err(err).Str("file", r.file).Msg("cannot reload rates file")
+			continue
+		}
+
+		r.logger.Info().Str("file", r.file).Time("timestamp", r.Table().Timestamp).Msg("exchange rates reloaded")
+	}
+}
+
+// Conversion is a price converted with the rate table of Timestamp
+type Conversion struct {
+	Amount    string
+	Rate      string
+	Timestamp time.Time
+}
+
+// Convert converts decimal amount between currencies through the base one,
+// the result is rounded to 2 fraction digits, halves away from zero
+func (r *Rates) Convert(amount, from, to string) (Conversion, error) {
+	value, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		return Conversion{}, fmt.Errorf("malformed amount %q", amount)
+	}
+
+	r.mutex.RLock()
+	fromRate, toRate, timestamp := r.parsed[from], r.parsed[to], r.table.Timestamp
+	r.mutex.RUnlock()
+
+	switch {
+	case fromRate == nil:
+		return Conversion{}, fmt.Errorf("%w for %s", ErrNoRate, from)
+	case toRate == nil:
+		return Conversion{}, fmt.Errorf("%w for %s", ErrNoRate, to)
+	}
+
+	rate := new(big.Rat).Quo(toRate, fromRate)
+
+	return Conversion{
+		Amount:    new(big.Rat).Mul(value, rate).FloatString(2),
+		Rate:      rate.FloatString(6),
+		Timestamp: timestamp,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+func newTestRates(t *testing.T, rates map[string]json.Number) *Rates {
+	t.Helper()
+
+	logger := zerolog.Nop()
+	r, err := NewRates("", &logger)
+	if err != nil {
+		t.Fatalf("NewRates() error = %v", err)
+	}
+
+	if err := r.Set(RateTable{Base: "EUR", Rates: rates}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	return r
+}
+
+func TestRatesSet(t *testing.T) {
+	r := newTestRates(t, map[string]json.Number{"USD": "1.0712", "EUR": "1.000"})
+
+	if table := r.Table(); table.Base != "EUR" || time.Since(table.Timestamp) > time.Minute {
+		t.Fatalf("Table() = %+v, want EUR table stamped now", table)
+	}
+
+	tests := []struct {
+		name  string
+		table RateTable
+		want  string
+	}{
+		{"no base", RateTable{Rates: map[string]json.Number{"USD": "1"}}, "Base"},
+		{"unknown base", RateTable{Base: "ABC", Rates: map[string]json.Number{"USD": "1"}}, "Base"},
+		{"no rates", RateTable{Base: "EUR"}, "Rates"},
+		{"unknown currency", RateTable{Base: "EUR", Rates: map[string]json.Number{"ABC": "1"}}, "Rates"},
+		{"zero rate", RateTable{Base: "EUR", Rates: map[string]json.Number{"USD": "0"}}, "positive"},
+		{"negative rate", RateTable{Base: "EUR", Rates: map[string]json.Number{"USD": "-1.07"}}, "positive"},
+		{"malformed rate", RateTable{Base: "EUR", Rates: map[string]json.Number{"USD": "1,07"}}, "positive"},
+		{"base rate is not 1", RateTable{Base: "EUR", Rates: map[string]json.Number{"EUR": "1.1", "USD": "1.07"}}, "must be 1"},
+	}
+
+	for _, tt := range tests {
+		err := r.Set(tt.table)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Set() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	// a rejected table leaves the previous one
+	if conv, err := r.Convert("1", "EUR", "USD"); err != nil || conv.Amount != "1.07" {
+		t.Errorf("Convert() after rejected tables = %+v, %v", conv, err)
+	}
+}
+
+func TestRatesConvert(t *testing.T) {
+	r := newTestRates(t, map[string]json.Number{"USD": "1.0712", "RUB": "98.12"})
+
+	tests := []struct {
+		amount, from, to string
+		wantAmount       string
+		wantRate         string
+	}{
+		{"10.00", "EUR", "USD", "10.71", "1.071200"},
+		{"10", "EUR", "EUR", "10.00", "1.000000"},
+		// cross rate through EUR
+		{"10.00", "USD", "RUB", "915.98", "91.598208"},
+		{"2.345", "RUB", "USD", "0.03", "0.010917"},
+		// halves are rounded away from zero
+		{"0.005", "EUR", "EUR", "0.01", "1.000000"},
+		{"0.015", "EUR", "EUR", "0.02", "1.000000"},
+		{"0.004", "EUR", "EUR", "0.00", "1.000000"},
+	}
+
+	for _, tt := range tests {
+		conv, err := r.Convert(tt.amount, tt.from, tt.to)
+		if err != nil {
+			t.Errorf("Convert(%s, %s, %s) error = %v", tt.amount, tt.from, tt.to, err)
+			continue
+		}
+		if conv.Amount != tt.wantAmount || conv.Rate != tt.wantRate || conv.Timestamp.IsZero() {
+			t.Errorf("Convert(%s, %s, %s) = %+v, want %s at rate %s", tt.amount, tt.from, tt.to, conv, tt.wantAmount, tt.wantRate)
+		}
+	}
+
+	if _, err := r.Convert("1", "EUR", "KZT"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Convert() to KZT error = %v, want ErrNoRate", err)
+	}
+	if _, err := r.Convert("1", "KZT", "EUR"); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Convert() from KZT error = %v, want ErrNoRate", err)
+	}
+	if _, err := r.Convert("ten", "EUR", "USD"); err == nil {
+		t.Errorf("Convert() of malformed amount succeeded")
+	}
+}
+
+func TestRatesWatch(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rates.json")
+	modTime := time.Now().Add(-time.Hour)
+
+	// every write gets a later modification time, the watcher compares them
+	write := func(content string) {
+		t.Helper()
+
+		if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		modTime = modTime.Add(time.Minute)
+		if err := os.Chtimes(file, modTime, modTime); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+
+	rate := func(r *Rates) json.Number {
+		return r.Table().Rates["USD"]
+	}
+
+	write(`{"base": "EUR", "rates": {"USD": "1.07"}}`)
+
+	logger := zerolog.Nop()
+	r, err := NewRates(file, &logger)
+	if err != nil {
+		t.Fatalf("NewRates() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		r.Watch(ctx, 5*time.Millisecond)
+		close(done)
+	}()
+
+	write(`{"base": "EUR", "rates": {"USD": "1.10"}}`)
+	waitFor(t, func() bool { return rate(r) == "1.10" })
+
+	// a broken file keeps the previous table
+	write(`{"base": "EUR", "rates": {"USD": `)
+	time.Sleep(50 * time.Millisecond)
+	if got := rate(r); got != "1.10" {
+		t.Fatalf("rate after broken file = %s, want 1.10 kept", got)
+	}
+
+	write(`{"base": "EUR", "rates": {"USD": "1.20"}}`)
+	waitFor(t, func() bool { return rate(r) == "1.20" })
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("Watch() did not return after cancel")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(2 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in 2s")
+		}
+	}
+}
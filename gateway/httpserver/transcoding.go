@@ -0,0 +1,164 @@
+package httpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewTranscodingHandler serves REST routes generated from google.api.http
+// annotations of storageservice.proto instead of hand-written handlers
+func NewTranscodingHandler(ctx context.Context, conn *grpc.ClientConn) (http.Handler, error) {
+	mux := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(incomingHeader),
+		runtime.WithMetadata(forwardedMetadata),
+		runtime.WithOutgoingHeaderMatcher(outgoingHeader),
+		runtime.WithErrorHandler(transcodingErrorHandler),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions: protojson.MarshalOptions{
+				UseProtoNames:   true,
+				EmitUnpopulated: true,
+			},
+		}),
+	)
+
+	if err := storageservice.RegisterStorageServiceHandler(ctx, mux, conn); err != nil {
+		return nil, err
+	}
+
+	return requireExpectedVersion(mux), nil
+}
+
+// requireExpectedVersion makes PATCH /v1/books/{id} require If-Match as PUT and PATCH
+// of the hand-written handlers do, the version goes to expected_version of the body
+// unless the body has it already
+func requireExpectedVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch || !strings.HasPrefix(r.URL.Path, "/v1/books/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body := map[string]json.RawMessage{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeTranscodingError(w, r, newApiError(http.StatusBadRequest, "malformed json body"))
+			return
+		}
+
+		_, hasVersion := body["expected_version"]
+		if _, ok := body["expectedVersion"]; ok {
+			hasVersion = true
+		}
+
+		version, err := parseIfMatch(r.Header.Get(HeaderIfMatch), !hasVersion)
+		if err != nil {
+			writeTranscodingError(w, r, toApiError(err))
+			return
+		}
+
+		if version != nil {
+			delete(body, "expectedVersion")
+			body["expected_version"], _ = json.Marshal(*version)
+		}
+
+		raw, _ := json.Marshal(body)
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		r.ContentLength = int64(len(raw))
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedMetadata passes trace and idempotency key to storage the same way hand-written handlers do
+func forwardedMetadata(ctx context.Context, req *http.Request) metadata.MD {
+	md := metadata.MD{}
+
+	if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.HasTraceID() {
+		md.Set("x-trace-id", spanCtx.TraceID().String())
+	}
+
+	if key := req.Header.Get(HeaderIdempotencyKey); key != "" {
+		md.Set(storageservice.IdempotencyKeyHeader, key)
+	}
+
+	return md
+}
+
+// gatewayMetadata are metadata keys only the gateway sets, storage trusts them
+var gatewayMetadata = []string{
+	storageservice.PrincipalHeader,
+	storageservice.PrincipalScopesHeader,
+	storageservice.IdempotencyKeyHeader,
+	"x-trace-id",
+}
+
+// incomingHeader is the grpc-gateway default matcher without Grpc-Metadata- headers
+// of gateway metadata, clients could pass a principal of their choice otherwise
+func incomingHeader(key string) (string, bool) {
+	name, ok := runtime.DefaultHeaderMatcher(key)
+	if !ok {
+		return "", false
+	}
+
+	for _, reserved := range gatewayMetadata {
+		if strings.EqualFold(name, reserved) {
+			return "", false
+		}
+	}
+
+	return name, true
+}
+
+// outgoingHeader renames storage response metadata to gateway headers,
+// the rest gets grpc-gateway default Grpc-Metadata- prefix
+func outgoingHeader(key string) (string, bool) {
+	if key == storageservice.IdempotentReplayHeader {
+		return HeaderIdempotentReplayed, true
+	}
+
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+// transcodingErrorHandler writes the same ErrorResponse as HttpServer.ErrorHandler
+func transcodingErrorHandler(
+	ctx context.Context,
+	_ *runtime.ServeMux,
+	_ runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error) {
+	trace.SpanFromContext(r.Context()).RecordError(err)
+
+	writeTranscodingError(w, r, apiErrorFromStatus(status.Convert(err)))
+}
+
+func writeTranscodingError(w http.ResponseWriter, r *http.Request, apiErr *ApiError) {
+	body := ErrorResponse{Error: ErrorBody{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		Fields:    apiErr.Fields,
+		RequestId: w.Header().Get(echo.HeaderXRequestID),
+	}}
+
+	if spanCtx := trace.SpanContextFromContext(r.Context()); spanCtx.HasTraceID() {
+		body.Error.TraceId = spanCtx.TraceID().String()
+		w.Header().Set("Trace-Id", body.Error.TraceId)
+	}
+
+	w.Header().Set(echo.HeaderContentType, echo.MIMEApplicationJSONCharsetUTF8)
+	w.WriteHeader(apiErr.Status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,59 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestTranscodingDropsGatewayMetadata(t *testing.T) {
+	// metadata storage receives with the call
+	var received metadata.MD
+	capture := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		received, _ = metadata.FromIncomingContext(ctx)
+		return handler(ctx, req)
+	}
+
+	handler, err := NewTranscodingHandler(context.Background(), newTestStorage(t, grpc.UnaryInterceptor(capture)))
+	if err != nil {
+		t.Fatalf("NewTranscodingHandler() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/books/1", nil)
+	for _, header := range []string{
+		"Grpc-Metadata-X-Principal",
+		"Grpc-Metadata-X-Principal-Scopes",
+		"Grpc-Metadata-Idempotency-Key",
+		"Grpc-Metadata-X-Trace-Id",
+		"Grpc-Metadata-X-Client",
+	} {
+		req.Header.Set(header, "forged")
+	}
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if received == nil {
+		t.Fatalf("storage was not called")
+	}
+
+	for _, key := range []string{
+		storageservice.PrincipalHeader,
+		storageservice.PrincipalScopesHeader,
+		storageservice.IdempotencyKeyHeader,
+		"x-trace-id",
+	} {
+		if v := received.Get(key); len(v) > 0 {
+			t.Errorf("storage received %s = %v from client headers", key, v)
+		}
+	}
+
+	// other metadata is passed as grpc-gateway does by default
+	if v := received.Get("x-client"); len(v) != 1 || v[0] != "forged" {
+		t.Errorf("storage received x-client = %v, want forged", v)
+	}
+}
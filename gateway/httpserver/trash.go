@@ -0,0 +1,107 @@
+package httpserver
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// RestoreValue brings back a deleted book which has not been purged yet
+func (serv *HttpServer) RestoreValue(ctx echo.Context) error {
+	id := ctx.Param("id")
+
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"RestoreValue",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("id"),
+				Value: attribute.StringValue(id),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	idNum, err := strconv.Atoi(id)
+	if err != nil {
+		return newApiError(http.StatusBadRequest, "wrong id format")
+	}
+
+	resp, err := serv.storageClient.RestoreBook(distCtx, &storageservice.RestoreBookRequest{
+		Id: int32(idNum),
+	})
+
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	serv.cache.Delete(spanCtx, resp.Id)
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+	ctx.Response().Header().Set(HeaderETag, bookETag(resp.Version))
+
+	return ctx.JSON(http.StatusOK, bookFromResponse(resp))
+}
+
+// ListTrash lists deleted books which can still be restored
+func (serv *HttpServer) ListTrash(ctx echo.Context) error {
+	// ----------------------tracing----------------------
+	spanCtx, span := serv.tracer.Tracer("http-tracer").Start(
+		ctx.Request().Context(),
+		"ListTrash",
+		trace.WithAttributes(
+			attribute.KeyValue{
+				Key:   attribute.Key("query"),
+				Value: attribute.StringValue(ctx.QueryString()),
+			},
+		),
+	)
+	defer span.End()
+	// ---------------------------------------------------
+
+	traceId := span.SpanContext().TraceID().String()
+	distCtx := metadata.AppendToOutgoingContext(spanCtx, "x-trace-id", traceId)
+
+	req := &storageservice.ListDeletedBooksRequest{
+		Cursor: ctx.QueryParam("cursor"),
+	}
+
+	if ps := ctx.QueryParam("page_size"); ps != "" {
+		pageSize, err := strconv.Atoi(ps)
+		if err != nil {
+			return newApiError(http.StatusBadRequest, "wrong page_size format")
+		}
+		req.PageSize = int32(pageSize)
+	}
+
+	resp, err := serv.storageClient.ListDeletedBooks(distCtx, req)
+
+	if err != nil {
+		return serv.storageError(span, err)
+	}
+
+	ctx.Response().Header().Add("Trace-Id", span.SpanContext().TraceID().String())
+
+	books := make([]DeletedBook, 0, len(resp.Books))
+	for _, b := range resp.Books {
+		books = append(books, DeletedBook{
+			Book:      bookFromResponse(b.Book),
+			DeletedAt: b.DeletedAt.AsTime(),
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, TrashList{
+		Books:      books,
+		NextCursor: resp.NextCursor,
+	})
+}
@@ -0,0 +1,86 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	storageservice "github.com/s-vvardenfell/observer/storageservice/service"
+)
+
+// Validator implements echo.Validator, field names are taken from json tags
+type Validator struct {
+	validate *validator.Validate
+}
+
+func NewValidator() *Validator {
+	v := validator.New()
+
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.Split(field.Tag.Get("json"), ",")[0]
+	})
+
+	_ = v.RegisterValidation("price", func(fl validator.FieldLevel) bool {
+		_, err := storageservice.NormalizePrice(fl.Field().String())
+		return err == nil
+	})
+
+	return &Validator{validate: v}
+}
+
+func (v *Validator) Validate(i interface{}) error {
+	return v.validate.Struct(i)
+}
+
+// bindAndValidate reads request body into value, any failure is a client error
+func (serv *HttpServer) bindAndValidate(ctx echo.Context, value interface{}) error {
+	if err := ctx.Bind(value); err != nil {
+		serv.logger.Warn().Err(err).Msg("cannot bind request body")
+		apiErr := newApiError(http.StatusBadRequest, "malformed request body")
+		apiErr.Internal = err
+		return apiErr
+	}
+
+	if err := ctx.Validate(value); err != nil {
+		fieldErrs, ok := err.(validator.ValidationErrors)
+		if !ok {
+			return err
+		}
+
+		apiErr := newApiError(http.StatusBadRequest, "invalid book")
+		for _, fe := range fieldErrs {
+			apiErr.Fields = append(apiErr.Fields, FieldError{
+				Field:   fe.Field(),
+				Message: fieldErrorMessage(fe),
+			})
+		}
+
+		return apiErr
+	}
+
+	return nil
+}
+
+func fieldErrorMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required without author_id"
+	case "min":
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "price":
+		return "must be a non-negative decimal with at most 2 fraction digits"
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	default:
+		return fmt.Sprintf("failed on %s", fe.Tag())
+	}
+}
@@ -1,11 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/s-vvardenfell/observer/tracer"
@@ -57,6 +64,8 @@ func main() {
 			util.CheckEnv("STORAGE_SVC_HOST", "127.0.0.1"),
 			util.CheckEnv("STORAGE_SVC_PORT", "9991")),
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(httpserver.PrincipalUnaryInterceptor),
+		grpc.WithChainStreamInterceptor(httpserver.PrincipalStreamInterceptor),
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler(
 			// otelgrpc.WithMessageEvents(),
 			// otelgrpc.WithSpanOptions(),
@@ -81,20 +90,214 @@ func main() {
 
 	storageServiceClient := storageservice.NewStorageServiceClient(conn)
 
-	httpServ, err := httpserver.NewHttpServer(&logger, storageServiceClient, tracer)
+	cacheTTL, err := util.CheckEnvDuration("BOOK_CACHE_TTL", time.Minute)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	cacheSize, err := strconv.Atoi(util.CheckEnv("BOOK_CACHE_SIZE", "10000"))
+	if err != nil {
+		logger.Fatal().Err(err).Msg("wrong BOOK_CACHE_SIZE format")
+	}
+
+	// httpserver.RemoteCache needs a KVStore client the gateway does not have,
+	// replicas keep caches of their own
+	var bookCache httpserver.BookCache = httpserver.NoCache{}
+	if cacheSize > 0 && cacheTTL > 0 {
+		bookCache = httpserver.NewLRUCache(cacheSize, cacheTTL)
+	}
+
+	rates, err := httpserver.NewRates(util.CheckEnv("RATES_FILE", ""), &logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("failed to load exchange rates")
+	}
+
+	ratesRefresh, err := util.CheckEnvDuration("RATES_REFRESH_INTERVAL", time.Minute)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	go rates.Watch(bgCtx, ratesRefresh)
+
+	httpServ, err := httpserver.NewHttpServer(&logger, storageServiceClient, tracer, bookCache, rates)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to init http server")
 	}
 
+	auth := authFromEnv(&logger)
+
 	echoInst := echo.New()
+	echoInst.IPExtractor = ipExtractorFromEnv(&logger)
+	echoInst.Validator = httpserver.NewValidator()
+	echoInst.HTTPErrorHandler = httpServ.ErrorHandler
+	echoInst.Use(middleware.RequestID())
 	echoInst.Use(otelecho.Middleware("http-tracer", otelecho.WithTracerProvider(tracer)))
-	echoInst.Use(middleware.Logger())
+	echoInst.Use(middleware.LoggerWithConfig(middleware.LoggerConfig{
+		Format: strings.Replace(middleware.DefaultLoggerConfig.Format,
+			`"remote_ip":`, `"principal":${custom},"remote_ip":`, 1),
+		CustomTagFunc: func(ctx echo.Context, buf *bytes.Buffer) (int, error) {
+			subject, _ := json.Marshal(httpserver.PrincipalSubject(ctx))
+			return buf.Write(subject)
+		},
+	}))
 	echoInst.Use(middleware.Recover())
 	echoInst.Use(httpServ.CountTotalReqMetricMiddleware)
-	echoInst.GET("/storage/:id", httpServ.GetValueById)
-	echoInst.POST("/storage", httpServ.AddValue)
+	echoInst.Use(httpserver.NewRateLimiter(
+		rateLimitFromEnv(&logger, "RATE_LIMIT_READ", 50, 100),
+		rateLimitFromEnv(&logger, "RATE_LIMIT_WRITE", 10, 20),
+		auth,
+	).Middleware)
+
+	switch mode := util.CheckEnv("GATEWAY_MODE", "handlers"); mode {
+	case "handlers":
+		registerRoutes(echoInst, httpServ, auth)
+	case "transcoding": // routes from google.api.http annotations of storageservice.proto
+		restHandler, err := httpserver.NewTranscodingHandler(bgCtx, conn)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to init transcoding handler")
+		}
+
+		echoInst.Any("/v1/*", echo.WrapHandler(restHandler), auth.RequireByMethod)
+	default:
+		logger.Fatal().Msgf("unknown GATEWAY_MODE %q, want handlers or transcoding", mode)
+	}
 
 	echoInst.Logger.Fatal(echoInst.Start(fmt.Sprintf("%s:%s",
 		util.CheckEnv("HTTP_SRV_HOST", "127.0.0.1"),
 		util.CheckEnv("HTTP_SRV_PORT", "1323"))))
 }
+
+// registerRoutes adds gateway api, every route must be described in httpserver/openapi.go
+// with the same scope
+func registerRoutes(echoInst *echo.Echo, httpServ *httpserver.HttpServer, auth *httpserver.Authenticator) {
+	read := auth.Require(httpserver.ScopeBooksRead)
+	write := auth.Require(httpserver.ScopeBooksWrite)
+	admin := auth.Require(httpserver.ScopeAdmin)
+
+	echoInst.GET("/storage", httpServ.ListValues, read)
+	echoInst.GET("/storage/search", httpServ.SearchValues, read)
+	echoInst.GET("/storage/export", httpServ.ExportValues, read)
+	echoInst.GET("/storage/:id", httpServ.GetValueById, read)
+	echoInst.POST("/storage", httpServ.AddValue, write)
+	echoInst.POST("/storage/import", httpServ.ImportValues, write)
+	echoInst.PUT("/storage/:id", httpServ.UpdateValue, write)
+	echoInst.PATCH("/storage/:id", httpServ.PatchValue, write)
+	echoInst.DELETE("/storage/:id", httpServ.DeleteValue, write)
+	echoInst.POST("/storage/:id/restore", httpServ.RestoreValue, write)
+
+	echoInst.GET("/authors", httpServ.ListAuthors, read)
+	echoInst.GET("/authors/:id", httpServ.GetAuthor, read)
+	echoInst.GET("/authors/:id/books", httpServ.ListAuthorBooks, read)
+
+	echoInst.GET("/admin/trash", httpServ.ListTrash, admin)
+	echoInst.GET("/admin/rates", httpServ.GetRates, admin)
+	echoInst.PUT("/admin/rates", httpServ.SetRates, admin)
+	echoInst.POST("/admin/rates/reload", httpServ.ReloadRates, admin)
+
+	echoInst.GET("/openapi.json", httpServ.OpenApi)
+	echoInst.GET("/docs", httpServ.DocsRedirect)
+	echoInst.GET("/docs/*", httpServ.Docs)
+}
+
+// rateLimitFromEnv reads <prefix>_RPS and <prefix>_BURST, zero rps disables the limit
+func rateLimitFromEnv(logger *zerolog.Logger, prefix string, rps float64, burst int) httpserver.RateLimit {
+	limit := httpserver.RateLimit{Rate: rps, Burst: burst}
+
+	if v := util.CheckEnv(prefix+"_RPS", ""); v != "" {
+		r, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("wrong %s_RPS format", prefix)
+		}
+		limit.Rate = r
+	}
+
+	if v := util.CheckEnv(prefix+"_BURST", ""); v != "" {
+		b, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("wrong %s_BURST format", prefix)
+		}
+		limit.Burst = b
+	}
+
+	return limit
+}
+
+// ipExtractorFromEnv takes client IP from X-Forwarded-For only when the peer is
+// one of HTTP_TRUSTED_PROXIES, comma separated CIDRs, the header is spoofable otherwise
+func ipExtractorFromEnv(logger *zerolog.Logger) echo.IPExtractor {
+	proxies := util.CheckEnv("HTTP_TRUSTED_PROXIES", "")
+	if strings.TrimSpace(proxies) == "" {
+		return echo.ExtractIPDirect()
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+
+	for _, cidr := range strings.Split(proxies, ",") {
+		_, ipRange, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			logger.Fatal().Err(err).Msg("wrong HTTP_TRUSTED_PROXIES format, want comma separated CIDRs")
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// authFromEnv reads credentials accepted by the gateway:
+// AUTH_API_KEYS is a comma separated list of name:key:scopes with space separated scopes,
+// e.g. "reporting:s3cr3t:books:read", JWTs are accepted if AUTH_JWT_HS256_SECRET or
+// AUTH_JWT_RS256_PUBLIC_KEY_FILE (PEM) is set
+func authFromEnv(logger *zerolog.Logger) *httpserver.Authenticator {
+	if util.CheckEnv("AUTH_ENABLED", "true") == "false" {
+		logger.Warn().Msg("authentication is disabled, anyone can read and write books")
+		return httpserver.NewDisabledAuthenticator()
+	}
+
+	config := httpserver.AuthConfig{
+		ApiKeys:  map[string]httpserver.Principal{},
+		Issuer:   util.CheckEnv("AUTH_JWT_ISSUER", ""),
+		Audience: util.CheckEnv("AUTH_JWT_AUDIENCE", ""),
+	}
+
+	for _, entry := range strings.Split(util.CheckEnv("AUTH_API_KEYS", ""), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+			logger.Fatal().Msg("wrong AUTH_API_KEYS format, want name:key:scopes")
+		}
+
+		config.ApiKeys[parts[1]] = httpserver.Principal{
+			Subject: parts[0],
+			Scopes:  strings.Fields(parts[2]),
+		}
+	}
+
+	if secret := util.CheckEnv("AUTH_JWT_HS256_SECRET", ""); secret != "" {
+		config.HS256Secret = []byte(secret)
+	}
+
+	if keyFile := util.CheckEnv("AUTH_JWT_RS256_PUBLIC_KEY_FILE", ""); keyFile != "" {
+		pem, err := os.ReadFile(keyFile)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to read AUTH_JWT_RS256_PUBLIC_KEY_FILE")
+		}
+
+		config.RS256PublicKey, err = jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("failed to parse AUTH_JWT_RS256_PUBLIC_KEY_FILE")
+		}
+	}
+
+	if len(config.ApiKeys) == 0 && config.HS256Secret == nil && config.RS256PublicKey == nil {
+		logger.Warn().Msg("no API keys or JWT keys are configured, every book request will be rejected")
+	}
+
+	return httpserver.NewAuthenticator(config, logger)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/s-vvardenfell/observer/gateway/httpserver"
+)
+
+func TestOpenApiSpecCoversRoutes(t *testing.T) {
+	echoInst := echo.New()
+	registerRoutes(echoInst, &httpserver.HttpServer{}, httpserver.NewDisabledAuthenticator())
+
+	spec := httpserver.OpenApiSpec()
+
+	for _, route := range echoInst.Routes() {
+		path := httpserver.SpecPath(route.Path)
+
+		if _, ok := spec.Paths[path][strings.ToLower(route.Method)]; !ok {
+			t.Errorf("route %s %s is missing from openapi spec", route.Method, route.Path)
+		}
+	}
+}
@@ -1,3 +1,3 @@
 #!/usr/bin/env sh
 
-docker compose -f docker-compose.yaml up -d postgres migrate
\ No newline at end of file
+docker compose -f docker-compose.yaml up -d postgres
\ No newline at end of file
@@ -4,8 +4,17 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
 	"github.com/s-vvardenfell/observer/util"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 
@@ -21,6 +30,24 @@ func main() {
 	logger := zerolog.New(os.Stdout).Level(zerolog.InfoLevel).With().Timestamp().Logger()
 	bgCtx := context.Background()
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate", "seed":
+			err := runCommand(&logger, os.Args[1:])
+			if errors.Is(err, errUsage) {
+				fmt.Fprintln(os.Stderr, migrateUsage)
+				os.Exit(2)
+			}
+			if err != nil {
+				logger.Fatal().Err(err).Msgf("%s failed", os.Args[1])
+			}
+			return
+		default:
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			os.Exit(2)
+		}
+	}
+
 	tracer, err := tracer.InitGrpcTracer(bgCtx, "grpc-tracer", fmt.Sprintf("%s:%s",
 		util.CheckEnv("JAEGER_GRPC_HOST", "127.0.0.1"),
 		util.CheckEnv("JAEGER_GRPC_PORT", "4317")))
@@ -34,16 +61,51 @@ func main() {
 		}
 	}()
 
-	storSvc, err := storageservice.NewStorageService(storageservice.StorageServiceOpts{
-		Tracer:     tracer,
-		Logger:     &logger,
-		SqlConnStr: util.CheckEnv("STORAGE_CONN_STR", "postgres://0.0.0.0:5432/defaultdb?sslmode=disable"),
+	idempotencyKeyTTL, err := util.CheckEnvDuration("IDEMPOTENCY_KEY_TTL", storageservice.DefaultIdempotencyKeyTTL)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	trashRetention, err := util.CheckEnvDuration("BOOK_TRASH_RETENTION", storageservice.DefaultTrashRetention)
+	if err != nil {
+		logger.Fatal().Err(err).Send()
+	}
+
+	// stop waiting for the database on SIGINT or SIGTERM
+	startCtx, stopStart := signal.NotifyContext(bgCtx, os.Interrupt, syscall.SIGTERM)
+
+	storSvc, err := storageservice.NewStorageService(startCtx, storageservice.StorageServiceOpts{
+		Tracer:            tracer,
+		Logger:            &logger,
+		SqlConnStr:        util.CheckEnv("STORAGE_CONN_STR", "postgres://0.0.0.0:5432/defaultdb?sslmode=disable"),
+		Backend:           util.CheckEnv("STORAGE_BACKEND", ""),
+		DB:                dbOptionsFromEnv(&logger),
+		Metrics:           prometheus.DefaultRegisterer,
+		AutoMigrate:       util.CheckEnv("STORAGE_AUTO_MIGRATE", "false") == "true",
+		Seed:              util.CheckEnv("STORAGE_SEED", "false") == "true",
+		IdempotencyKeyTTL: idempotencyKeyTTL,
+		TrashRetention:    trashRetention,
 	})
+	stopStart()
 
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to init storage service")
 	}
 
+	go func() { // metrics server
+		http.Handle("/metrics", promhttp.Handler())
+
+		if err := http.ListenAndServe(
+			fmt.Sprintf("%s:%s",
+				util.CheckEnv("PROM_HOST", "127.0.0.1"),
+				util.CheckEnv("PROM_PORT", "9102")), nil); err != nil {
+			logger.Error().Err(err).Msg("failed run metrics exporter endpoint")
+		}
+	}()
+
+	go storSvc.PurgeIdempotencyKeys(bgCtx, time.Hour)
+	go storSvc.PurgeDeletedBooks(bgCtx, time.Hour)
+
 	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%s",
 		util.CheckEnv("STORAGE_SVC_HOST", "127.0.0.1"),
 		util.CheckEnv("STORAGE_SVC_PORT", "9991")))
@@ -67,3 +129,39 @@ func main() {
 		logger.Fatal().Err(err).Msg("failed to serve storage service")
 	}
 }
+
+// dbOptionsFromEnv reads the DB_* pool, startup and tracing settings, unset ones keep the defaults.
+// DB_TRACE_PARAMS=true puts query parameters into the spans, they may hold personal data
+func dbOptionsFromEnv(logger *zerolog.Logger) storagedb.DBOptions {
+	envInt := func(name string) int {
+		v := util.CheckEnv(name, "")
+		if v == "" {
+			return 0
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("wrong %s format", name)
+		}
+		return n
+	}
+
+	envDuration := func(name string) time.Duration {
+		d, err := util.CheckEnvDuration(name, 0)
+		if err != nil {
+			logger.Fatal().Err(err).Send()
+		}
+		return d
+	}
+
+	return storagedb.DBOptions{
+		MaxOpenConns:      envInt("DB_MAX_OPEN_CONNS"),
+		MaxIdleConns:      envInt("DB_MAX_IDLE_CONNS"),
+		ConnMaxLifetime:   envDuration("DB_CONN_MAX_LIFETIME"),
+		ConnMaxIdleTime:   envDuration("DB_CONN_MAX_IDLE_TIME"),
+		ConnectAttempts:   envInt("DB_CONNECT_ATTEMPTS"),
+		ConnectBackoff:    envDuration("DB_CONNECT_BACKOFF"),
+		ConnectMaxBackoff: envDuration("DB_CONNECT_MAX_BACKOFF"),
+		TraceParams:       util.CheckEnv("DB_TRACE_PARAMS", "false") == "true",
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
+	"github.com/s-vvardenfell/observer/util"
+)
+
+const migrateUsage = `usage:
+  storageservice migrate up [N]         apply N or all pending migrations
+  storageservice migrate down [N|all]   revert N migrations, 1 by default
+  storageservice migrate status         print the schema version
+  storageservice migrate force V        set the version after fixing a dirty schema, -1 for none
+  storageservice seed                   put the seed catalogue into an empty database`
+
+var errUsage = errors.New(migrateUsage)
+
+// runCommand runs the migrate and seed subcommands
+// on the database of STORAGE_CONN_STR
+func runCommand(logger *zerolog.Logger, args []string) error {
+	run, err := parseCommand(args)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	m, err := storagedb.NewMigrator(ctx,
+		util.CheckEnv("STORAGE_CONN_STR", "postgres://0.0.0.0:5432/defaultdb?sslmode=disable"),
+		dbOptionsFromEnv(logger))
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := run(ctx, m); err != nil {
+		return err
+	}
+
+	if args[0] == "seed" {
+		return nil
+	}
+
+	st, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	version := "none"
+	if st.Version != storagedb.NilVersion {
+		version = strconv.FormatInt(st.Version, 10)
+	}
+	if st.Dirty {
+		version += " (dirty)"
+	}
+
+	fmt.Printf("version: %s\nlatest: %d\npending: %v\n", version, st.Latest, st.Pending)
+	return nil
+}
+
+// parseCommand checks args before connecting to the database
+func parseCommand(args []string) (func(ctx context.Context, m *storagedb.Migrator) error, error) {
+	if args[0] == "seed" {
+		if len(args) > 1 {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, m *storagedb.Migrator) error { return m.Seed(ctx) }, nil
+	}
+
+	if len(args) < 2 {
+		return nil, errUsage
+	}
+
+	switch args[1] {
+	case "up":
+		n, err := countArg(args[2:], 0)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, m *storagedb.Migrator) error { return m.Up(ctx, n) }, nil
+	case "down":
+		n := -1
+		if len(args) != 3 || args[2] != "all" {
+			var err error
+			if n, err = countArg(args[2:], 1); err != nil {
+				return nil, err
+			}
+		}
+		return func(ctx context.Context, m *storagedb.Migrator) error { return m.Down(ctx, n) }, nil
+	case "force":
+		if len(args) != 3 {
+			return nil, errUsage
+		}
+		version, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			return nil, errUsage
+		}
+		return func(ctx context.Context, m *storagedb.Migrator) error { return m.Force(ctx, version) }, nil
+	case "status":
+		if len(args) > 2 {
+			return nil, errUsage
+		}
+		return func(context.Context, *storagedb.Migrator) error { return nil }, nil
+	}
+
+	return nil, errUsage
+}
+
+// countArg is the optional positive count of migrations
+func countArg(args []string, def int) (int, error) {
+	if len(args) == 0 {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n <= 0 || len(args) > 1 {
+		return 0, errUsage
+	}
+
+	return n, nil
+}
@@ -0,0 +1 @@
+-- 010 up has nothing to undo, books seeded by it before are dropped with their tables
@@ -0,0 +1 @@
+ALTER TABLE books ALTER COLUMN price TYPE FLOAT USING price::float;
@@ -0,0 +1 @@
+ALTER TABLE books ALTER COLUMN price TYPE NUMERIC(12, 2) USING round(price::numeric, 2);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS book_prices;
+ALTER TABLE books DROP COLUMN IF EXISTS currency;
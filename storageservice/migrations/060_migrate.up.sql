@@ -0,0 +1,9 @@
+-- existing prices are in roubles, the currency of the seed catalogue
+ALTER TABLE books ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE IF NOT EXISTS book_prices (
+    book_id INT NOT NULL REFERENCES books (book_id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(12, 2) NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (book_id, currency)
+);
@@ -0,0 +1,23 @@
+DROP VIEW IF EXISTS book_details;
+
+DROP INDEX IF EXISTS books_search_vector_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS author VARCHAR(100);
+ALTER TABLE books ADD COLUMN IF NOT EXISTS author_bio VARCHAR(3000);
+
+UPDATE books SET author = authors.name, author_bio = authors.bio FROM authors WHERE authors.author_id = books.author_id;
+
+ALTER TABLE books ALTER COLUMN author SET NOT NULL;
+ALTER TABLE books DROP COLUMN IF EXISTS author_id;
+
+ALTER TABLE books ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('russian', coalesce(author_bio, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS books_search_vector_idx ON books USING GIN (search_vector);
+
+DROP TABLE IF EXISTS authors;
@@ -0,0 +1,60 @@
+CREATE TABLE IF NOT EXISTS authors (
+    author_id SERIAL PRIMARY KEY NOT NULL,
+    name VARCHAR(100) NOT NULL UNIQUE,
+    bio VARCHAR(3000),
+    search_vector tsvector
+        GENERATED ALWAYS AS (setweight(to_tsvector('russian', coalesce(bio, '')), 'C')) STORED
+);
+
+CREATE INDEX IF NOT EXISTS authors_search_vector_idx ON authors USING GIN (search_vector);
+
+-- one author per name, bio of the latest book wins
+INSERT INTO authors (name, bio)
+SELECT DISTINCT ON (author)
+    author,
+    author_bio
+FROM
+    books
+ORDER BY
+    author,
+    book_id DESC
+ON CONFLICT (name) DO NOTHING;
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS author_id INT REFERENCES authors (author_id);
+
+UPDATE books SET author_id = authors.author_id FROM authors WHERE authors.name = books.author;
+
+ALTER TABLE books ALTER COLUMN author_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS books_author_id_idx ON books (author_id);
+
+-- bio is indexed with authors now, book search_vector is rebuilt without it
+DROP INDEX IF EXISTS books_search_vector_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE books DROP COLUMN author, DROP COLUMN author_bio;
+
+ALTER TABLE books ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS books_search_vector_idx ON books USING GIN (search_vector);
+
+-- book_details is what the api calls a book: book row with its author
+CREATE OR REPLACE VIEW book_details AS
+SELECT
+    books.book_id,
+    books.title,
+    books.author_id,
+    authors.name AS author,
+    books.price,
+    books.currency,
+    books.description,
+    authors.bio AS author_bio,
+    books.version,
+    books.search_vector,
+    authors.search_vector AS author_search_vector
+FROM
+    books
+    JOIN authors ON authors.author_id = books.author_id;
@@ -0,0 +1,24 @@
+-- trashed books would come back to life without the column
+DELETE FROM books WHERE deleted_at IS NOT NULL;
+
+DROP VIEW IF EXISTS book_details;
+
+CREATE VIEW book_details AS
+SELECT
+    books.book_id,
+    books.title,
+    books.author_id,
+    authors.name AS author,
+    books.price,
+    books.currency,
+    books.description,
+    authors.bio AS author_bio,
+    books.version,
+    books.search_vector,
+    authors.search_vector AS author_search_vector
+FROM
+    books
+    JOIN authors ON authors.author_id = books.author_id;
+
+DROP INDEX IF EXISTS books_deleted_at_idx;
+ALTER TABLE books DROP COLUMN IF EXISTS deleted_at;
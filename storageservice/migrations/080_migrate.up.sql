@@ -0,0 +1,22 @@
+ALTER TABLE books ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS books_deleted_at_idx ON books (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- new view columns can only be appended
+CREATE OR REPLACE VIEW book_details AS
+SELECT
+    books.book_id,
+    books.title,
+    books.author_id,
+    authors.name AS author,
+    books.price,
+    books.currency,
+    books.description,
+    authors.bio AS author_bio,
+    books.version,
+    books.search_vector,
+    authors.search_vector AS author_search_vector,
+    books.deleted_at
+FROM
+    books
+    JOIN authors ON authors.author_id = books.author_id;
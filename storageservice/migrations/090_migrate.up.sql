@@ -0,0 +1,2 @@
+-- keys are prefixed with the principal of the client, which would not fit 255 characters
+ALTER TABLE idempotency_keys ALTER COLUMN key TYPE TEXT;
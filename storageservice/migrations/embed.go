@@ -0,0 +1,60 @@
+// Package migrations holds the schema migrations of the storage service.
+// Files in sqlite replace the postgres ones of the same name for the sqlite backend
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+	"sort"
+)
+
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
+
+// SQLiteFS is FS with the files of sqlite in place of the ones of the same name
+var SQLiteFS fs.FS = overlayFS{FS: FS, dir: "sqlite"}
+
+// overlayFS shows the files of dir at the root of FS, hiding the root files of the same name
+type overlayFS struct {
+	fs.FS
+	dir string
+}
+
+func (o overlayFS) Open(name string) (fs.File, error) {
+	if name != "." {
+		if f, err := o.FS.Open(path.Join(o.dir, name)); err == nil {
+			return f, nil
+		}
+	}
+
+	return o.FS.Open(name)
+}
+
+func (o overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(o.FS, name)
+	if err != nil || name != "." {
+		return entries, err
+	}
+
+	over, err := fs.ReadDir(o.FS, o.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]fs.DirEntry, len(entries)+len(over))
+	for _, entry := range entries {
+		byName[entry.Name()] = entry
+	}
+	for _, entry := range over {
+		byName[entry.Name()] = entry
+	}
+
+	merged := make([]fs.DirEntry, 0, len(byName))
+	for _, entry := range byName {
+		merged = append(merged, entry)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Name() < merged[j].Name() })
+
+	return merged, nil
+}
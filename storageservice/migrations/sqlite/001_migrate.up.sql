@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS books (
+    book_id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    title TEXT NOT NULL,
+    author TEXT NOT NULL,
+    price REAL,
+    description TEXT,
+    author_bio TEXT
+);
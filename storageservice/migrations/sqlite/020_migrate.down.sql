@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS books_search_insert;
+DROP TRIGGER IF EXISTS books_search_update;
+DROP TRIGGER IF EXISTS books_search_delete;
+
+DROP TABLE IF EXISTS book_search;
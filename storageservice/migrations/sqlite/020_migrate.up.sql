@@ -0,0 +1,20 @@
+-- search_vector of postgres is an fts5 table with book_id as rowid kept in sync by triggers
+CREATE VIRTUAL TABLE IF NOT EXISTS book_search USING fts5(title, description, author_bio);
+
+INSERT INTO book_search (rowid, title, description, author_bio)
+SELECT book_id, title, coalesce(description, ''), coalesce(author_bio, '') FROM books;
+
+CREATE TRIGGER IF NOT EXISTS books_search_insert AFTER INSERT ON books BEGIN
+    INSERT INTO book_search (rowid, title, description, author_bio)
+    VALUES (new.book_id, new.title, coalesce(new.description, ''), coalesce(new.author_bio, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS books_search_update AFTER UPDATE OF title, description, author_bio ON books BEGIN
+    UPDATE book_search
+    SET title = new.title, description = coalesce(new.description, ''), author_bio = coalesce(new.author_bio, '')
+    WHERE rowid = new.book_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS books_search_delete AFTER DELETE ON books BEGIN
+    DELETE FROM book_search WHERE rowid = old.book_id;
+END;
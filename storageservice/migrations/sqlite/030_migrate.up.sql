@@ -0,0 +1,10 @@
+-- times are RFC 3339 text written by the service, julianday() compares them
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key TEXT PRIMARY KEY NOT NULL,
+    request_hash TEXT NOT NULL,
+    book_id INTEGER NOT NULL,
+    created_at TEXT NOT NULL,
+    expires_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (julianday(expires_at));
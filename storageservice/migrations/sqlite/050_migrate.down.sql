@@ -0,0 +1,6 @@
+ALTER TABLE books ADD COLUMN price_float REAL;
+
+UPDATE books SET price_float = CAST(price AS REAL) WHERE price IS NOT NULL;
+
+ALTER TABLE books DROP COLUMN price;
+ALTER TABLE books RENAME COLUMN price_float TO price;
@@ -0,0 +1,7 @@
+-- NUMERIC(12, 2) is kept as text so prices read back as written, e.g. 10.50
+ALTER TABLE books ADD COLUMN price_decimal TEXT;
+
+UPDATE books SET price_decimal = printf('%.2f', price) WHERE price IS NOT NULL;
+
+ALTER TABLE books DROP COLUMN price;
+ALTER TABLE books RENAME COLUMN price_decimal TO price;
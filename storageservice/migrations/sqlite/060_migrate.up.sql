@@ -0,0 +1,9 @@
+-- existing prices are in roubles, the currency of the seed catalogue
+ALTER TABLE books ADD COLUMN currency TEXT NOT NULL DEFAULT 'RUB';
+
+CREATE TABLE IF NOT EXISTS book_prices (
+    book_id INTEGER NOT NULL REFERENCES books (book_id) ON DELETE CASCADE,
+    currency TEXT NOT NULL,
+    amount TEXT NOT NULL CHECK (CAST(amount AS REAL) >= 0),
+    PRIMARY KEY (book_id, currency)
+);
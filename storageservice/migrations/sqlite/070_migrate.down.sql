@@ -0,0 +1,51 @@
+DROP VIEW IF EXISTS book_details;
+
+DROP TRIGGER IF EXISTS authors_search_update;
+DROP TRIGGER IF EXISTS books_search_insert;
+DROP TRIGGER IF EXISTS books_search_update;
+
+-- author_id is a foreign key which cannot be dropped, the table is rebuilt instead
+CREATE TABLE books_without_authors (
+    book_id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    title TEXT NOT NULL,
+    author TEXT NOT NULL,
+    description TEXT,
+    author_bio TEXT,
+    version INTEGER NOT NULL DEFAULT 1,
+    price TEXT,
+    currency TEXT NOT NULL DEFAULT 'RUB'
+);
+
+INSERT INTO books_without_authors (book_id, title, author, description, author_bio, version, price, currency)
+SELECT
+    books.book_id,
+    books.title,
+    authors.name,
+    books.description,
+    authors.bio,
+    books.version,
+    books.price,
+    books.currency
+FROM
+    books
+    JOIN authors ON authors.author_id = books.author_id;
+
+DROP TABLE books;
+ALTER TABLE books_without_authors RENAME TO books;
+
+CREATE TRIGGER IF NOT EXISTS books_search_insert AFTER INSERT ON books BEGIN
+    INSERT INTO book_search (rowid, title, description, author_bio)
+    VALUES (new.book_id, new.title, coalesce(new.description, ''), coalesce(new.author_bio, ''));
+END;
+
+CREATE TRIGGER IF NOT EXISTS books_search_update AFTER UPDATE OF title, description, author_bio ON books BEGIN
+    UPDATE book_search
+    SET title = new.title, description = coalesce(new.description, ''), author_bio = coalesce(new.author_bio, '')
+    WHERE rowid = new.book_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS books_search_delete AFTER DELETE ON books BEGIN
+    DELETE FROM book_search WHERE rowid = old.book_id;
+END;
+
+DROP TABLE IF EXISTS authors;
@@ -0,0 +1,72 @@
+CREATE TABLE IF NOT EXISTS authors (
+    author_id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    bio TEXT
+);
+
+-- one author per name, bio of the latest book wins
+INSERT INTO authors (name, bio)
+SELECT
+    author,
+    author_bio
+FROM
+    books
+WHERE
+    book_id IN (SELECT max(book_id) FROM books GROUP BY author)
+ORDER BY
+    author;
+
+-- a NOT NULL column cannot be added without default, InsertBook always sets it
+ALTER TABLE books ADD COLUMN author_id INTEGER REFERENCES authors (author_id);
+
+UPDATE books SET author_id = (SELECT author_id FROM authors WHERE authors.name = books.author);
+
+CREATE INDEX IF NOT EXISTS books_author_id_idx ON books (author_id);
+
+-- bio is searched with authors now, columns used by triggers cannot be dropped
+DROP TRIGGER IF EXISTS books_search_insert;
+DROP TRIGGER IF EXISTS books_search_update;
+
+ALTER TABLE books DROP COLUMN author;
+ALTER TABLE books DROP COLUMN author_bio;
+
+CREATE TRIGGER IF NOT EXISTS books_search_insert AFTER INSERT ON books BEGIN
+    INSERT INTO book_search (rowid, title, description, author_bio)
+    VALUES (
+        new.book_id,
+        new.title,
+        coalesce(new.description, ''),
+        coalesce((SELECT bio FROM authors WHERE author_id = new.author_id), '')
+    );
+END;
+
+CREATE TRIGGER IF NOT EXISTS books_search_update AFTER UPDATE OF title, description, author_id ON books BEGIN
+    UPDATE book_search
+    SET
+        title = new.title,
+        description = coalesce(new.description, ''),
+        author_bio = coalesce((SELECT bio FROM authors WHERE author_id = new.author_id), '')
+    WHERE rowid = new.book_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS authors_search_update AFTER UPDATE OF bio ON authors BEGIN
+    UPDATE book_search
+    SET author_bio = coalesce(new.bio, '')
+    WHERE rowid IN (SELECT book_id FROM books WHERE author_id = new.author_id);
+END;
+
+-- book_details is what the api calls a book: book row with its author
+CREATE VIEW IF NOT EXISTS book_details AS
+SELECT
+    books.book_id,
+    books.title,
+    books.author_id,
+    authors.name AS author,
+    books.price,
+    books.currency,
+    books.description,
+    authors.bio AS author_bio,
+    books.version
+FROM
+    books
+    JOIN authors ON authors.author_id = books.author_id;
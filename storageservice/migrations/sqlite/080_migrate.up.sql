@@ -0,0 +1,21 @@
+ALTER TABLE books ADD COLUMN deleted_at TEXT;
+
+CREATE INDEX IF NOT EXISTS books_deleted_at_idx ON books (deleted_at) WHERE deleted_at IS NOT NULL;
+
+DROP VIEW IF EXISTS book_details;
+
+CREATE VIEW book_details AS
+SELECT
+    books.book_id,
+    books.title,
+    books.author_id,
+    authors.name AS author,
+    books.price,
+    books.currency,
+    books.description,
+    authors.bio AS author_bio,
+    books.version,
+    books.deleted_at
+FROM
+    books
+    JOIN authors ON authors.author_id = books.author_id;
@@ -0,0 +1 @@
+-- sqlite keys stay TEXT
@@ -0,0 +1 @@
+-- sqlite keys are TEXT already
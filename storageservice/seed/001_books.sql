@@ -0,0 +1,62 @@
+-- the seed catalogue, it is only put into an empty one
+
+INSERT INTO authors (name, bio) VALUES
+('Стивен Кинг', 'Стивен Кинг – один из самых известных писателей нашего времени. Его талант и влияние на мировую культуру трудно переоценить. Стивен Кинг написал более 60 романов и множество коротких рассказов, каждый из которых пронизан его неповторимым стилем. Он способен проникнуть в глубины человеческой психологии, создавая живых и неповторимых персонажей. Его произведения не только пугают и заставляют читателя держать дыхание, но и заставляют задуматься над смыслом жизни, судьбой, добром и злом. Один из его первых успехов - роман Кэрри, стал бестселлером и утвердил за автором позицию мастера ужасов. Но настоящей легендой жанра он стал после выпуска Сияния, который воплотил на экране Стэнли Кубрик. Это произведение, как и многие другие Кинга, не просто пугает, но и раскрывает сложные психологические грани персонажей. Стивен Кинг – автор, чья способность создавать атмосферу невероятного заставляют читателей возвращаться к его произведениям снова и снова. Он исследует широкий спектр жанров – от ужасов и триллеров до научной фантастики и драмы. Каждая его книга – это глубокое погружение в мир страхов и надежд, где герои сталкиваются с чудовищами, как внешними, так и внутренними. Стивен Кинг не только писатель, но и общественный деятель. Он открыто выступает по важным социальным вопросам и поддерживает благотворительные организации. Его литературное наследие останется с нами навсегда, и его влияние на мировую литературу и кинематограф невозможно переоценить. Кинг – это не просто мастер ужасов, но и мудрый наблюдатель человеческой природы, чьи произведения будут вдохновлять и пугать поколения читателей ещё много лет.'),
+('Герман Гессе', 'Герман Гессе – выдающийся немецкий писатель, лауреат Нобелевской премии по литературе, чья жизнь и творчество оказали глубокое влияние на мировую литературу. Родившись в Германии в 1877 году, Гессе пережил детство, отмеченное трудностями и личными кризисами, что сыграло важную роль в формировании его художественной идентичности. Его произведения, включая такие знаменитые романы, как Сиддхартха и Игра в бисер, отражают поиск духовной гармонии, индивидуализма и самопознания. Гессе экспериментировал с различными религиозными и философскими темами, и его произведения часто обращаются к идеям восточных религий, трансцендентализму и психоанализу, вдохновляя читателей на размышления о смысле жизни и человеческой душе. Его стиль прозы характеризуется глубокой метафоричностью и яркой эмоциональностью, что делает его произведения невероятно проникновенными и вдохновляющими для многих поколений читателей. Гессе ушел из жизни в Швейцарии в 1962 году, но его литературное наследие продолжает оставаться актуальным и вдохновляющим.'),
+('Говард Лавкрафт', 'Говард Лавкрафт был американским писателем, одним из самых значимых представителей жанра ужасов и фантастики в начале XX века. Родившись в 1890 году в Провиденсе, штат Род-Айленд, Лавкрафт провел большую часть своей жизни в тесной связи с литературой и полным созерцанием мира. Его самыми известными произведениями являются рассказы, в которых Лавкрафт создал мистический мир, населенный древними богами и космическими ужасами, раскрывая темы безграничной вселенской страха и бессмысленности. Часто используя повествование от первого лица, писатель мастерски строил непередаваемую атмосферу тайны и ужаса, заставляя читателя сомневаться в реальности и состоянии разума героев его произведений. Лавкрафт смело экспериментировал со стилем и формой, описывая космические сущности и мифологию, восходящую ко временам древних богов и их поклонениям. В своих рассказах он проникал в самые глубины человеческой психологии, исследуя мотивы и страхи, которые скрываются в самом сердце каждого из нас. Лавкрафт считался одним из предтечей современной ужасной литературы, влияние его работы ощущается до сих пор в литературе и кинематографе, а его персонажи, такие как Ктулху, стали истинными иконами жанра. В своём творчестве Лавкрафт связал высокоразвитую научную картину мира с абсолютно неразгаданными загадками и ужасами, что втягивает читателя в оккультные исследования, где простор для фантазии не ограничен законами физики и биологии. Поющие пауки и космические звезды, сущности из параллельных миров и законы глубокого времени - всё это Лавкрафт связал в единую вселенную, окутанную мраком и загадками, заставляя читателя задуматься о месте человека в этом океане неведомого и бесконечности космоса. В своем творчестве Лавкрафт оставил неподражаемый след, вобравший в себя ужас и философию, проливающие свет на нашу истинную роль в мире и возможную ценность нашей жизни.'),
+('Артуро Перес-Реверте', 'Артуро Перес-Реверте - испанский писатель и журналист. Автор исторических романов и детективов. В юности увлекался подводным плаванием, затем стал завзятым яхтсменом (Настоящая свобода начинается в 10 милях от берега). От отца унаследовал любовь к путешествиям, от деда — библиотеку в пять тысяч томов. В детстве читал всю приключенческую и классическую литературу, до которой мог дотянуться. Тогда его любимым героем был Робинзон Крузо, которого сейчас писатель находит омерзительным (Что сделал Робинзон, встретив другого человека? Превратил его в слугу). Артуро Перес-Реверте, начиная с 1971 года, более двадцати лет работал военным репортёром для газеты Пуэбло и для телевидения. Освещал военные конфликты, происходившие на Кипре, в Ливане, Западной Сахаре, Ливии, Мозамбике, Анголе, Югославии и других странах. Первый роман Гусар, действие которого происходит во времена Наполеоновских войн, опубликован в 1986 году. Успех пришёл к нему в 1990 году после публикации романа Фламандская доска, главные герои которого расследуют убийство, случившееся в XV веке. Самый известный его роман, Королева юга, опубликованный в 2002 году, дважды экранизирован, американский сериал вышел в 2016 году. Проживает в Ла-Навате (около Мадрида) и в родной Картахене, где имеет возможность путешествовать на яхте по Средиземному морю. С 2003 года член Королевской академии испанского языка.'),
+('Стендаль', 'Стендаль, настоящее имя которого было Мари Бейер де Бирэ, был французским писателем и одним из величайших мастеров романтической прозы XIX века. Он родился в 1783 году и стал известным благодаря своим произведениям, в которых исследовал человеческую психологию и страсти. Одним из его самых известных произведений является роман Красное и черное, который рассказывает о жизни и амбициях юноши по имени Жюльен Сорель. Стендаль был мастером психологического портрета, и его произведения часто освещали внутренний мир героев, их амбиции, страхи и стремления. Он также известен своей способностью описывать общество и политическую обстановку своего времени. Его стиль был неповторим и инновационен для своей эпохи, с использованием диалогов и внутренних монологов для раскрытия характеров. Стендаль также был одним из первых литераторов, который придал важность исследованию психологии и эмоций героев в литературе, что сделало его произведения актуальными и в современной литературе. Его влияние на развитие романтизма и литературы в целом трудно переоценить, и его произведения до сих пор остаются объектом изучения и восхищения для многих читателей и исследователей.'),
+('Артур Конан Дойл', 'Артур Конан Дойл (1859-1930) был выдающимся британским писателем и врачом, наиболее известным как создатель легендарного детектива Шерлока Холмса. Родившись в Эдинбурге, он получил медицинское образование, но его настоящая страсть принадлежала литературе. Первый роман о Шерлоке Холмсе, Скандал в Богемии, увидел свет в 1887 году, и с тех пор гений детективных исследований Холмс и его верный друг доктор Ватсон стали неразрывно связанными с миром криминальной литературы. Дойл написал в общей сложности 56 рассказов и четыре романа о приключениях Шерлока Холмса. Однако, Конан Дойл был не только автором детективных произведений. Он также писал исторические романы, фантастику, научно-популярные статьи и медицинские труды. Его исторические произведения, такие как Белый отряд и Компания дАртаньян, получили признание читателей и критиков. Он также был пионером научной фантастики с серией рассказов о профессоре Челленджере. Помимо литературной карьеры, Дойл был активным спортсменом и авантюристом. Он участвовал во многих видах спорта, включая крикет и гольф, и совершил несколько морских путешествий. Его жизненный опыт и любовь к приключениям нашли отражение во многих его произведениях. Смерть писателя наступила в 1930 году, но его наследие живет и процветает в литературе и кинематографе. Персонажи Шерлок Холмс и доктор Ватсон остаются вечными символами разгадывания тайн и острых умов, продолжая вдохновлять поколения читателей и зрителей по всему миру.')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO books (title, author_id, price, currency, description) VALUES
+('Темная башня', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '680.00', 'RUB', 'Роман Темная башня Стивена Кинга - это эпическая сага, объединяющая элементы фэнтези, научной фантастики и вестерна. Главный герой, Роланд Дисейн, является последним рыцарем из ка-тета, стремящимся достичь Темной башни - мистической структуры, связывающей все миры и времена. В его путешествии он собирает команду спутников, включая Джейка Чемберса, Эдди Дина и Сьюзанну Дин. Они сталкиваются с разнообразными опасностями, включая врагов, как людей, так и существ из других измерений. Сюжет сочетает поиск Темной башни и личные истории персонажей, создавая глубокий и многогранный мир, который Роланд и его ка-тет исследуют, исследуя свои собственные демоны и судьбы.'),
+('Оно', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '710.00', 'RUB', 'Оно - это один из самых известных романов Стивена Кинга, который сочетает в себе элементы ужасов и драмы. Сюжет разворачивается в вымышленном городе Дерри, штат Мэн. В центре событий - группа детей, известных как Лузеры, которые сталкиваются с злобным существом, известным как Оно. Оно - это древнее зло, способное принимать форму самых глубоких страхов каждого члена группы. В основном, оно принимает облик клоуна Пеннивайза, но также обладает способностью меняться и становиться чем-то, что пугает каждого ребенка в отдельности. Оно охотится за детьми и питается их страхами. Сюжет романа переключается между двумя временными периодами - детством главных персонажей в 1950-х годах и их взрослой жизнью в 1980-х. Дети решают столкнуться с Оно, и в процессе исследования они обнаруживают мрачные секреты города и его прошлого. Постепенно становится ясно, что Оно имеет давнюю связь с Дерри и возможно существует уже веками.'),
+('Сияние', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '1060.00', 'RUB', 'Сияние - это роман Стивена Кинга о Джеке Торренсе, писателе, который принимает работу смотрителя в изолированном от мира отеле Оверлук. Вместе с женой Уэнди и сыном Дэнни он начинает зимовать в отеле, однако таинственные явления и видения начинают преследовать их. Дэнни, сын Джека, обладает сиянием - сверхъестественными способностями, и он видит зловещие события прошлого отеля. По мере того как Джек погружается в безумие, семья Торренс борется за выживание в мрачной и запертой от сна ловушке отеля, сталкиваясь с его духами и своими собственными демонами.'),
+('Зеленая миля', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '690.00', 'RUB', 'Зеленая миля - роман Стивена Кинга, который рассказывает историю Пола Эджкомба, смотрителя в тюремном блоке смертников, известном как Зеленая миля. Пол обладает особым даром - он может чувствовать мысли и эмоции заключенных, иногда предсказывая события. Сюжет разворачивается в 1932 году в штате Луизиана, где Пол и его коллеги сталкиваются с разнообразными преступниками, включая Джона Коффи, огромного чернокожего мужчину с загадочными способностями и обвиненного в убийстве двух девочек. Пол убежден в невиновности Коффи и становится его защитником, но система и множество преград создают невероятные испытания для всех. Роман исследует темы морали, справедливости, смирения и надежды, при этом раскрывая человеческую природу и сложность моральных выборов. Зеленая миля - это интригующий и эмоционально насыщенный рассказ, который погружает читателя в мир, где добро и зло, сверхъестественное и человеческое соседствуют на тонкой грани.'),
+('Стрелок', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '1200.00', 'RUB', 'Роман Стрелок Стивена Кинга рассказывает о Роланде Десейне, последнем из ордена стрелков, рыцарей, чья цель - защищать Вселенную от тьмы. В мире, подобном Дикому Западу, Роланд исследует пустыни и пустоши в поисках Темной Башни, центральной точки вселенской экзистенции. В своем стремлении он объединяется с разношерстной командой спутников и сталкивается с мистическими сущностями, тайнами и опасностями, в том числе с нечеловеческим врагом по имени Человек в черном. Сюжет насыщен приключениями и символикой, а Роланд, как исследователь, продвигается к Темной Башне, раскрывая загадки своего мира.'),
+('Под куполом', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '610.00', 'RUB', 'Роман Под куполом Стивена Кинга разворачивается в маленьком городе Честерз Миллс, где внезапно и загадочным образом оказывается отрезанным от внешнего мира невидимым и непроницаемым энергетическим куполом. В результате этого события горожане оказываются запертыми внутри этой барьерной сферы и сталкиваются с нарастающим хаосом и страхом. Сюжет фокусируется на множестве персонажей, от местных жителей до политических деятелей, их борьбе за выживание и попытках разгадать тайну купола. В то время как внешний мир пытается разобраться в происходящем, внутри города разгораются конфликты, взлетают амбиции и скрываются темные секреты. По мере того как ситуация усугубляется, разворачиваются интриги, предательства и нарастающая напряженность между персонажами, а купол становится аллегорией для изоляции и того, как она может вывести на свет темные стороны человеческой природы.'),
+('Мертвая зона', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '1160.00', 'RUB', 'Роман Мертвая зона Стивена Кинга рассказывает историю Джонни Смита, обычного учителя, который после автомобильной аварии погружается в кому на несколько лет. После пробуждения он обнаруживает, что обладает способностью видеть будущее и прошлое людей, которых касается. Пытаясь адаптироваться к новой жизни, Джонни помогает полиции раскрыть несколько преступлений, включая серийное убийство. В процессе своих предсказаний он сталкивается с Грегом Стилсоном, политическим кандидатом, который оказывается опасным психопатом. Джонни понимает, что должен остановить Стилсона, чтобы спасти мир от его тирании, но это может стоить ему жизни. Мертвая зона - это напряженный и захватывающий роман, где главный герой сталкивается с моральными дилеммами и борется со своими личными демонами, используя свои сверхъестественные способности для защиты других.'),
+('Томминокеры', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '640.00', 'RUB', 'Томминокеры - это роман Стивена Кинга, в котором главные герои, Бобби Гарсиа и Джонни Смит, обнаруживают сверхъестественные способности после автокатастрофы. Бобби обладает телекинезом, а Джонни может видеть прошлое и будущее человека, прикасаясь к нему. Они становятся близкими друзьями и пытаются скрыть свои способности от мира, но когда их тайна раскрывается, они оказываются втянутыми в смертельную игру с загадочным сектой Томминокеры. Роман исследует темы дружбы, толерантности и силы человеческой воли, а также остается одним из ярких образцов жанра ужасов от Стивена Кинга.'),
+('Ловец снов', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '910.00', 'RUB', 'Роман Ловец снов Стивена Кинга рассказывает о Джейкобе Эпстейне, профессоре английской литературы, который сталкивается с трагической гибелью своего отца во время авиакатастрофы. Подвергнувшись серии странных сновидений, Джейкоб начинает исследовать мистический мир снов, исследуя их значения и влияние на человеческую психику. В своем путешествии он обнаруживает существование Ловца снов, сущности, которая может вмешиваться в чужие сновидения и оказывать воздействие на реальный мир. Джейкоб также занимается исследованием своего детства и семейных секретов, связанных с таинственной детской психиатрической клиникой. Постепенно он осознает, что его родители и другие взрослые скрывали правду о своих способностях видеть и влиять на сны. Роман исследует темы семейных тайн, потери, исцеления и важности принятия своего прошлого, при этом погружая читателя в мистический и загадочный мир сновидений и их влияния на человеческую жизнь.'),
+('11/22/63', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '1190.00', 'RUB', 'Роман 11/22/63 Стивена Кинга рассказывает историю Джейка Эппинга, учителя, который случайно обнаруживает портал во времени в одном из домов своего городка. Ему предлагается уникальная возможность попытаться предотвратить убийство президента Джона Кеннеди, произошедшее 22 ноября 1963 года. Джейк решает воспользоваться этой возможностью и отправляется в прошлое с миссией предотвратить трагедию. В процессе своего временного путешествия Джейк сталкивается с различными сложностями и моральными дилеммами. Он понимает, что даже небольшие изменения в прошлом могут иметь непредсказуемые последствия для будущего. Путешествие во времени также оказывает воздействие на его личную жизнь и приводит к сложным моральным выборам. Роман исследует темы времени, судьбы, и влияния прошлого на наше настоящее. Книга сочетает в себе элементы научной фантастики, исторического романа и драмы, создавая захватывающий и эмоционально насыщенный рассказ, который заставляет читателя задуматься о времени, возможностях изменения истории и ценности каждого момента в жизни.'),
+('Парень из Колорадо', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '1030.00', 'RUB', 'Роман Парень из Колорадо (также известный как The Colorado Kid) Стивена Кинга рассказывает историю двух ветеранов газетной редакции, Стефани МакКлауд и Дейва Боумена, работающих на небольшом острове Мейн. Они занимаются разгадыванием загадочных и неразрешенных преступлений, и однажды они сталкиваются с загадкой, которая бросает вызов их профессиональным навыкам и логике.Сюжет фокусируется на случае названном Парень из Колорадо - молодом человеке, найденном мертвым на берегу, но несущем в себе ряд странных загадок. Несмотря на их усилия, МакКлауд и Боумен не могут найти разумное объяснение этому происшествию, и оно остается неразгаданным. Весь роман является расследованием этой загадки и попыткой понять, что же произошло с Парнем из Колорадо. Парень из Колорадо - это не типичный детективный расследовательский роман. Вместо того, чтобы предоставить четкие ответы, Кинг создает интригующий повествовательный мир, где неразрешенные загадки остаются без ответа, подчеркивая мистическую и загадочную сторону жизни и человеческих судеб. Роман призывает читателя задуматься о природе тайн и ограничениях нашего понимания мира.'),
+('Кэрри', (SELECT author_id FROM authors WHERE name = 'Стивен Кинг'), '610.00', 'RUB', 'Роман Кэрри Стивена Кинга рассказывает историю Кэрри Уайт, подростка с телекинетическими способностями, которая страдает от жестоких издевательств в школе. Кэрри живет с мамой, религиозной маниакальной фанатичкой, и её жизнь наполнена одиночеством и унижениями. Все меняется во время выпускного вечера, когда она становится жертвой жестокого розыгрыша, когда ей льют на голову свинцовое ведро. В это момент её телекинетические способности пробуждаются, и она накапливает силу и ярость. Взрыв телекинеза приводит к катастрофическим последствиям - разрушению школы и смерти многих людей. Роман исследует темы жертвы, мести и жестокости, а также показывает, как непереносимое давление и изоляция могут привести к катастрофическим последствиям.'),
+('Петер Каменцинд', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '590.00', 'RUB', 'Роман Петер Каменцинд Германа Гессе рассказывает о жизни главного героя Петера Каменцинда, чья судьба полна трагизма и потерь. Воспитываясь в прекрасном и уютном семействе, Петер с детства обнаруживает свое исключительное восприятие и понимание окружающего мира. Однако под влиянием современного общества и в поисках своей индивидуальности, Петер отправляется в путешествие в Берлин, где он сталкивается с жестокой и суровой реальностью, а также с темными сторонами человеческой природы. Через множество любовных разочарований, потери своего истинного я и запутанные взаимоотношения с разными людьми, Петер осознает, что истинное счастье можно найти только внутри себя. Разочарованный и измученный, он возвращается в родное село, где обретает покой и гармонию, осознавая ценность того, что он ищет не во внешних достижениях, а в своем внутреннем мире и душе. Петер Каменцинд - это история о поисках себя, о духовном пробуждении и понимании того, что настоящее счастье заключается в гармонии со своим истинным я.'),
+('Под колесом', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '750.00', 'RUB', 'Главный герой повести, одарённый мальчик Ханс Гебенрат отправлен в элитную семинарию в Маульбронне. В школе Ханс становится умнее и образованнее, но совсем не развивается как личность. Он начинает дружить с Германом Хайльнером, мальчиком, который меньше интересуется учёбой. Через некоторое время Хайльнера исключают из семинарии, а Гебенрата отправляют домой, потому что его заподозрили в умственном помешательстве. В родной деревне Ханс не смог ни с кем подружиться, так как всё детство посвятил учёбе. Он становится учеником кузнеца, и ему нравится это практичное ремесло, в отличие от отвлечённых наук. В конце повести Ханс тонет при непонятных обстоятельствах. Хотя в книге не сказано об этом, многие литераторы считают, что это было самоубийство.'),
+('Гертруда', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '960.00', 'RUB', 'Главный герой романа — композитор Кун с ранних лет увлекался музыкой и после школы поступил в консерваторию. Он не был гением, но тем не менее не терял надежды стать хорошим музыкантом. Кун влюбляется в начинающую вокалистку Лидди и в один из зимних дней отправляется с ней кататься на санках. В результате неудачного спуска с горы герой получает многочисленные травмы и на всю жизнь остается хромым. Выйдя из больницы, Кун решает продолжить учёбу и вскоре отправляется в Швейцарию, где пишет свои первые музыкальные произведения. На одном из светских вечеров начинающий композитор знакомится с певцом Генрихом Муотом, который просит Куна выступить на своем дне рождения. Постепенно они становятся друзьями. Муот помогает Куну устроиться в оркестр на место скрипача. Композитора приглашают принять участие в музыкальных вечерах в доме богатого фабриканта Имтора, где он знакомится с Гертрудой, дочерью Имтора. Кун влюбляется в девушку и проводит много времени у неё дома. Всю дальнейшую жизнь Кун пытыется стать для Гертруды больше чем другом.'),
+('Росхальде', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '720.00', 'RUB', 'Действие романа происходит в имении Росхальде, где живет известный художник Иоганн Верагут вместе с женой Аделью и семилетним сыном Пьером. Из-за постоянных размолвок в семье Иоганн вынужден отправить старшего сына Альберта учиться в другой город и оставить дом своей супруге, сам при этом поселившись в мастерской. Единственной нитью, которая связывает Верагутов, является маленький Пьер. В один из дней в имение приезжает лучший друг Иоганна Отто Буркхарт, который вот уже несколько лет живет в Индии. Буркхарт давно планирует пригласить Верагута погостить к себе, но художнику необходимо некоторое время на завершение нескольких задуманных работ в Росхальде, пред тем как отправиться в путешествие. Все лето Иоганн работает над своими полотнами. Пьер мечется между домом и мастерской, не находя нигде должного к себе внимания, так как мать много времени проводит с приехавшим на каникулы Альбертом, а отец полностью занят живописью. Но после того как мальчик заболевает воспалением мозга, Иоганн и Адель, бросив все, проводят дни и ночи у постели сына, в ожидании улучшений его состояния. Постоянные боли мучают Пьера, и, в конце концов, сердце ребенка останавливается. После смерти сына Верагута уже больше ничего не держит в Росхальде.'),
+('Кнульп', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '810.00', 'RUB', 'Кнульп - роман Германа Гессе, повествующий о жизни немецкого иммигранта Германа Кнульпа, который покидает родную страну, чтобы начать новую жизнь в Южной Америке. В запутанном мире капитализма и колониализма, он сталкивается с непростыми жизненными ситуациями и обретает новую идентичность вдали от привычного окружения. Кнульп повествует о своих приключениях, открытиях и разочарованиях, точно пишущие его как об одиноком страннике, ищущем своего места в жизни и пытающемся найти смысл в хаосе мира и своей собственной душе. Роман исследует темы экзистенциального поиска, путешествия, человеческой природы и свободы, вызывая у читателя вопросы об истинной ценности и непостоянстве жизненных ценностей.'),
+('Демиан', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '730.00', 'RUB', 'Демиан - роман Германа Гессе, который рассказывает о жизни и внутреннем путешествии молодого немецкого студента Эмиля Синклера в начале XX века. Вторым главным героем является Демиан, таинственный человек, который становится наставником и находится на перекрестке между миром зрелости и мира юности. Под влиянием Демиана, Синклер пробуждается к самому себе и начинает понимать, что общественные нормы и конформизм не приносят ему мир и счастье, и он должен искать истину и свою собственную идентичность. Роман исследует темы самоопределения, поиска смысла жизни, самопознания и духовного пробуждения, где герой пытается сбросить маски, навязанные обществом, и обрести свободу и глубокое понимание самого себя. Это путешествие делает роман умным и мудрым, обращающим внимание на внутреннюю гармонию и независимость от внешних категорий.'),
+('Сиддхартха', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '900.00', 'RUB', 'Сиддхартха - роман Германа Гессе, который рассказывает о жизни главного героя Сиддхартхи, молодого брахмана, который стремится найти истину и просветление. Роман охватывает различные этапы жизни Сиддхартхи, начиная с его юности, когда он обретает знания и мудрость от учителей, но чувствует, что ему не хватает внутреннего опыта. Он покидает свой мир и отправляется в путешествие, где испытывает различные формы жизни, включая аскетизм, сексуальную похоть и мирскую жизнь. В конце концов, Сиддхартха осознает, что истина и просветление не могут быть достигнуты через внешние практики, и что они исходят из внутреннего познания и просветления. Финальное открытие Сиддхартхи происходит, когда он понимает значение простого искреннего участия в мирской жизни и абсолютной приверженности к этому моменту. Сиддхартха - это история о поиске смысла жизни, самооткрытии и просветлении, которая помогает читателю осознать важность внутренней гармонии и самопознания.'),
+('Степной волк', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '840.00', 'RUB', 'Роман по своей структуре является своеобразной книгой в книге. Повествование начинается с предисловия издателя, который решил опубликовать записки, оставленные ему главным героем и озаглавленные Записки Гарри Галлера (только для сумасшедших). Гарри Галлер находится в тяжелом внутреннем кризисе. Во время блужданий по городу он встречает человека, который дает ему небольшую книгу, Трактат о Степном Волке. Трактат повествует о Гарри, по прозвищу Степной Волк, который, так же как и протагонист романа, разделяет свою личность на две части: человека высокой духовной морали и животного — волка. При этом в книге указывается, что личность Гарри является в действительности гораздо более сложной и многогранной, и раскрываются его суицидальные наклонности. На следующий день, вновь блуждая по городу перед возвращением домой, где он решает совершить суицид, Гарри заходит в ресторан. Там он встречает девушку, которая обещает ему встречу, что откладывает его решение о самоубийстве. При следующей встрече выясняется, что её зовут Гермина, и она просит убить её, когда она ему прикажет. На протяжении романа не всегда понятно, где проходит грань между внутренними переживаниями героя и внешним миром. В конце романа Гарри открывается новая реальность, но чтобы остаться в ней, необходимо пожертвовать собственным разумом, на что и решается главный герой. Часто внутреннюю сущность Галлера Степного Волка ассоциируют с тем, что Юнг называл тенью, в то время как Гермину ассоциируют с анимой'),
+('Нарцисс и Златоуст', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '720.00', 'RUB', 'Императорский чиновник отдает в монастырь в ученики своего сына Златоуста. Юноша так и не находит друзей среди сверстников, но послушник Нарцисс, преподающий в монастыре греческий, чувствует к Златоусту интерес. Нарцисс — духовная и возвышенная личность. В Златоусте Нарцисса привлекает тайна его детства и характера — он остро чувствует нечто гнетущее, скрытое в памяти мальчика. Они проникаются симпатией друг к другу, много времени проводят вместе. В одной из бесед Нарцисс пробуждает в Златоусте забытый им образ матери, возвращение которого потрясает мальчика. Он оставляет идею стать послушником и уходит из монастыря. Златоуст отправляется в странствие. Лишенный какой бы то ни было цели, он наслаждается своей свободой. В любовных похождениях в это время он тоже свободен от какой-либо привязанности. Ненадолго он задерживается только в замке рыцаря, где добивается любви его дочери Лидии. За связь с ней рыцарь прогоняет его. Позже он встречает попутчика Виктора, но тот пытается ограбить его и оказывается убит Златоустом. После этого он понимает, что продолжение бродячей жизни только толкнет его на новые грехи.'),
+('Паломничество в Страну Востока', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '530.00', 'RUB', 'Главный герой, некто Г. Г., рассказывает о путешествии, предпринятом им вместе с другими членами Ордена. Каждый из них преследовал свои цели, так, Г. Г. хотел добиться любви принцессы Фатимы. Паломники шли малыми и большими группами, хотя иногда некоторым по тем или иным причинам требовалось пройти свой путь в одиночку. Г. Г. упоминает, что паломничество было организовано после Мировой войны, но, тем не менее, участники похода перемещались как в пространстве, так и во времени, встречая на своём пути различных реально существовавших личностей и выдуманных персонажей. Паломникам было запрещено использовать современные механизмы и изобретения, например, поезда или часы; участники также должны были посетить на своём пути различные места, связанные с историей Ордена. После того, как группа Г. Г. достигла Морбио-Инферьоре, была обнаружена пропажа слуги Лео, который нес важные вещи, а возможно и устав Ордена. Паломники восприняли это событие болезненно и не смогли продолжить путешествие далее. Спустя десять лет Г. Г. приходит к своему знакомому писателю Лукасу и рассказывает об Ордене, о Лео и о своём желании написать книгу о паломничестве. Лукас советует поискать имя Лео в городском телефонном справочнике. Г. Г. отправляется по указанному адресу и находит там своего бывшего соратника. Но Лео отказывается признавать какое-либо отношение к Ордену и утверждает, что вовсе не знает Г. Г. , а, точнее, не хочет знать. Тем не менее, на следующий день герой обнаруживает в своей комнате Лео, который по приказу Ордена должен привести Г. Г. к Высокому совету. Становится ясно, что Лео не просто слуга, а сам Высший из Высших. Несмотря на все проступки, героя оправдывают и возвращают звание члена Ордена, а также предоставляют возможность работы в архиве. Постепенно Г. Г. все больше и больше углубляется в различные отделы и документы, пока не находит собственный отдел, где замечает стоящую в нише статуэтку, изображающую две сросшихся спинами фигуры — фигуру увядающего Г. Г. и фигуру цветущего и полного сил Лео.'),
+('Игра в бисер', (SELECT author_id FROM authors WHERE name = 'Герман Гессе'), '500.00', 'RUB', 'Роман Игра в бисер Германа Гессе повествует о жизни и духовном развитии главного героя, мальчика по имени Йозеф Кнундер, который сталкивается с трудностями и страданиями в мире взрослых. Отрываясь от реальности, Йозеф погружается в мир своих фантазий и воображения, создавая для себя убежище в искусстве и литературе. Он находит утешение и внутренний мир в искусстве бисероплетения, обнаруживая в этом занятии способ выразить свои эмоции и мысли. Роман исследует темы одиночества, творчества и поиска истинной ценности в жизни, предлагая читателю глубокий взгляд на внутренний мир человека и его стремление к самореализации и пониманию окружающего мира через искусство и воображение.'),
+('Дагон', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '870.00', 'RUB', 'Рассказ представляет собой предсмертную записку человека, который описывает произошедший с ним инцидент, когда он служил во флоте во время Первой мировой войны. Его грузовой корабль (пакетбот) был захвачен немецким рейдерским кораблем в одном из редко посещаемых уголков Тихого океана. Рассказчик сбежал на шлюпке и дрейфовал несколько дней. В одну из ночей он обнаружил себя тонущим в вязкой, чёрной трясине, что простиралась на несколько миль вокруг. Шлюпку выбросило на неизвестный остров, что поднялся со дна вследствие извержения вулкана. По всей равнине торчали зловонные останки рыб и неизвестных существ. Через три дня почва затвердела и моряк отправился в поход. Он прошел черную каменистую пустыню, ущелье, холм, обширную равнину и каньон. Ландшафт напоминал Потерянный рай или Восхождение Сатаны из проклятого царства тьмы, а ущелье — Стигийские глубины. Там он обнаружил циклопический монолит, сияющий белым светом, что миллионы лет таился в бездне моря, но имел следы искусной обработки и, видимо, служил объектом поклонения морских существ. В этот момент из река появляется Дагон...'),
+('Музыка Эриха Цана', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '1140.00', 'RUB', 'Студент, изучающий метафизику, снимает комнату в дешевом многоквартирном доме на улице д’Осейль (франц. Rue d’Auseil), которую он никогда раньше не видел. Улица граничит с большой стеной и студент не может увидеть, что находится по ту сторону. Только одно окно на верхнем этаже дома смотрит за стену. В этой комнате живет пожилой и немой музыкант Эрих Цанн, который играет чарующие мелодии на виоле. Студент просит разрешения послушать музыку в его комнате. Музыкант играет другую, обычную мелодию и нервно поглядывает на зашторенное окно. Студент пытается одернуть занавеску, чтобы увидеть что находится по ту сторону стены, но Цанн останавливает его и выставляет за дверь. После Цанн избегает встречи с ним. Цанн работает с оркестром, а по ночам играет неземные мелодии. Слушая их студент восхваляет музыкального гения. Здоровье Цанна ухудшается и однажды из его комнаты раздается крик. Студент спешит к нему, однако Цанн дает понять, что с ним все в порядке и начинает писать нечто важное в рукопись, о событии произошедшим с ним. Спустя час написания письма за окном раздается низкий звук, будто, от далеких пространств. Цанн начинает яростно играть на виоле пугающую венгерскую мелодию, словно, пытаясь отогнать от окна нечто страшное. Внезапно раздается еще один странный звук, который студент воспринимает за насмешку, и порыв ветра разрушает окно, унося все бумаги. Студент смотрит в окно, но вместо городских огней он видит лишь черноту бездны (англ. Blackness void).'),
+('Зов Ктулху', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '1170.00', 'RUB', 'Роман Зов Ктулху Говарда Лавкрафта рассказывает о мрачном и загадочном мире, где древний и могущественный Ктулху, забытое божество, пробуждается из своего долгого сна на дне океана и начинает возвращаться к миру людей, вызывая безумие и хаос. События разворачиваются через призму дневников и рассказов разных персонажей, связанных с исследованием тайны Ктулху и его культовых последователей, погружая читателя в атмосферу ужаса и неизвестности. Главные герои пытаются раскрыть тайны мифологии и попытаться остановить ужасное пробуждение Ктулху, но сталкиваются с невероятными ужасами и потусторонними силами, которые превосходят человеческое понимание.'),
+('Реаниматор', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '940.00', 'RUB', 'Роман Реаниматор Говарда Лавкрафта повествует о молодом студенте  по имени Герберт Вест, который развивает опасную и жуткую страсть к экспериментам с воскрешением мертвых. В поисках знаний и власти над жизнью и смертью, он вступает в контакт с мрачным доктором Гербертом Уэстом, который практикует аморальные методы реанимации. Вместе они проводят ужасные эксперименты, используя трупы, и приводят к жизни ужасных существ, лишенных человеческой души. Сюжет разворачивается через серию зловещих событий, и Вест и Уэст оказываются втянутыми в мрачный мир невероятных ужасов, где граница между живыми и мертвыми стирается, а последствия их дурных поступков становятся катастрофическими.'),
+('Модель для Пикмана', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '1130.00', 'RUB', 'История вращается вокруг бостонского художника по имени Ричард Аптон Пикман, который творит столь ужасающие картины, что люди просто не могут на них смотреть. Его работы блестяще выполнены и настолько натурально, и живописно, что одна только картина Обед гулей вызывает на выставке вал критики, после чего Пикмана выгоняют из художественного клуба, а его коллеги-художники избегают его. Рассказчик — Турбер, друг Пикмана, который после загадочного исчезновения художника рассказывает Элиоту, своему знакомому, о том как он побывал на экскурсии в личной галерее Пикмана, сокрытой в городских трущобах Норт-Энд. Турбер восхищается талантом Пикмана, но считает, что его место в сумасшедшем доме, и подозревает, что на самом деле тот вовсе не человек. Нужно быть настоящим художником и уметь, как никто другой, проникнуть в природу вещей, чтобы создавать полотна, подобные тем, что творил Пикман. Любой может назвать картину Кошмар, Шабаш ведьм, Портрет дьявола, но лишь подлинный мастер может создать произведение, что окажется по-настоящему пугающим и действительно возьмёт вас за живое. Накануне своего исчезновения Пикман пригласил Турбера в свою новую галерею на Беттери-стрит, чтобы тот мог увидеть особые картины, которые он не показывал другим. По его словам, только там его душа переносится на века. Пройдя по набережной Конституции, они свернули в узкую пустынную аллею, где нет света и стоят старинные дома времён Андроса и Фипса. Там-то и была сокрыта галерея Пикмана.'),
+('Цвет из иных миров', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '840.00', 'RUB', 'Действие рассказа происходит в местности к западу от Аркхема, штат Массачусетс, где повсюду царит запустение и стоят лишь одинокие фермы на склонах холмов. Рассказчик, неназванный геодезист из Бостона, прибывает наметить место для водохранилища у реки Мискатоник (англ. Miskatonic) и натыкается на Испепеленную Пустошь (англ. Blasted heath) — проплешину с невообразимой мешаниной ущелий и склонов, что породил загадочной природы катаклизм. Эту землю застилают груды серой пыли и пепла, который почему-то остается не тронут ветром. В её центре стоит колодец — это все, что осталось от фермы Гарднеров. Пустошь появилась после того, как здесь была убита вся их семья, — эти кошмарные события прозвали странные дни (или окаянные дни). Не имея возможности получить какую-либо информацию от горожан, которые покинули эти места, рассказчик посещает дом старого Эмми Пирса, единственного очевидца тех событий. Дальнейший рассказ представляет собой его историю.'),
+('Шепчущий во тьме', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '640.00', 'RUB', 'Рассказ идёт от лица Алберта Н. Уилмарта[комм. 1], американского фольклориста и преподавателя литературы в Мискатоникском университете Аркхема, штат Массачусетс. После сообщений в прессе о том, что вследствие небывалого наводнения в штате Вермонт, случившегося 3 ноября 1927 года, многие местные жители якобы видели в реках тела странных существ, похожих на крабов. Уилмарт оспаривает правдивость информации и относится к слухам скептически. Он пытается доказать, что эти истории пошли из старых местных легенд о неких Крылатых — летающих существах, будто бы, обитающих в горах Новой Англии, и похищающих людей, посмевших приблизиться к их территории.'),
+('Хребты безумия', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '950.00', 'RUB', 'Американский геолог Дайер [~ 1] рассказывает, почему он пытается убедить мировое сообщество не посылать новые научные группы вглубь Антарктиды и открывает новые шокирующие подробности экспедиции, в которой он участвовал, и о которых ранее умолчал. В 1930 году Мискатоникский университет города Аркхема отправляет в Антарктику исследовательскую экспедицию из 20 человек, оснащённую по последнему слову техники. У них имелось 5 самолётов, 2 корабля, собачьи упряжки и специальная буровая установка Пибоди, позволяющая добывать образцы почвы с большой глубины. По прибытии, ученые замечают огромные горы, словно, мираж из Мира грез, не уступающие по высоте Гималаям, — Дайер назвал их Хребтами безумия. Практически сразу же, при бурении сланцевых пород они находят отпечатки неизвестных существ докембрийского периода. Одна из двух групп исследователей, под руководством биолога Лейка разбивает лагерь у подножия гор и приступает к бурению.'),
+('Тень над Инсмутом', (SELECT author_id FROM authors WHERE name = 'Говард Лавкрафт'), '640.00', 'RUB', 'Повествование идёт от лица одного молодого человека, рассказывающего о причинах массовых полицейских арестов в американском портовом городке Иннсмут [комм. 1], штат Массачусетс, округ Эссекс, происходивших на протяжении зимы 1927—1928 годов. Причиной же этих арестов, стало его прошение к властям разобраться в ужасных и сверхъестественных делах, творившихся там, свидетелем которых стал он сам. Началось всё летом 1927 года, когда рассказчик, недавно достигший совершеннолетия, путешествовал по Новой Англии, посещая места, связанные с различными ветвями генеалогического древа его семьи. Направляясь из Ньюберипорт в Аркхем, откуда происходили предки его матери, он услышал про один автобусный рейс, которым мало кто ездит, идущий через таинственный полузаброшенный Иннсмут. Стоящий в устье реки Меньюксет Иннсмут со всех сторон окружён солончаковыми болотами. Якобы когда-то это был процветающий морской порт, но из-за некой эпидемии 1846 года, унёсшей жизни большинства его жителей, он пришёл в упадок. После этого в соседних поселениях стали распространяться слухи о трагических случаях в Иннсмуте и на Рифе Дьявола, вблизи гавани. Ныне большинство иннсмутцев занимались рыбной ловлей (а рыба у иннсмутских берегов всегда почему-то кишмя кишела) либо работали на местной золотообрабатывающей фабрике.'),
+('Клуб Дюма, или Тень Ришельё', (SELECT author_id FROM authors WHERE name = 'Артуро Перес-Реверте'), '820.00', 'RUB', 'Действие романа Клуб Дюма, или Тень Ришельё происходит в особом мире — мире книг. Герои этой истории — библиофилы, букинисты, переплётчики и просто страстные любители литературы. Одни из них отдают предпочтение романам плаща и шпаги, другие — детективам, третьи пытаются разгадать тайны, скрытые в трудах по демонологии. Один из библиофилов, Варо Борха, очень богатый человек, нанял Лукаса Корсо для того, чтобы сравнить между собой три известных экземпляра уникального издания Книга о девяти вратах в царство теней.  Книга о девяти вратах в царство теней (De Umbrarum Regni Novem Portis) была опубликована в 1666 году неким печатником Аристидом Торкьей. Аристид Торкья был обвинён Святой Инквизицией в ереси, а затем сожжён на костре. Весь тираж Девяти врат был изъят и уничтожен. Ценность этого издания в том, что в мировых каталогах числятся всего три уцелевших экземпляра этого тиража. Варо Борха говорит Лукасу, что ознакомился с протоколами допроса Аристида Торкьи, которые велись Святой Инквизицией. Из показаний Торкьи, данных под пытками, следует, что остался единственный экземпляр Девяти врат, который находится в недоступном месте. Это противоречие не даёт Борхе покоя. Хотя его экземпляр внесён во все каталоги мира, Борха заявляет Лукасу, что имеет основания считать свой экземпляр подделкой. Он хочет установить, какой из трёх экземпляров является настоящим. Один из экземпляров находится у самого Борхи, второй — у Виктора Фаргаша (живёт в Синтре, предместье Лиссабона) и третий — в хранилище Фонда Унгерна (в Париже). Попутно Лукас Корсо хочет выяснить аутентичность автографа Александра Дюма для своего приятеля Флавио Ла Понте. Однако простое поручение оборачивается для Корсо большими неприятностями. Кто-то идёт по следу Корсо и убивает тех, с кем он встретился. В конце романа большинству загадок даётся правдоподобное объяснение. Однако одной из загадок рационального объяснения нет, а тот вывод, который приходится сделать, на основании косвенных улик и намёков, является совершенно фантастическим…'),
+('Территория команчей', (SELECT author_id FROM authors WHERE name = 'Артуро Перес-Реверте'), '650.00', 'RUB', 'Два испанских журналиста, Барлес и Маркес, работающих на телекомпанию Торреспанья, ведут репортаж о военных действиях в бывшей Югославии. В погоне за редкими кадрами они готовы пробираться под огнём снайперов, сутками жить в окопе, мерзнуть и страдать от голода. Хотя они не участвуют в боевых действиях, их жизнь подвергается такой же опасности, как и у любого солдата на передовой, то есть не стоит ни гроша: потому что на войне сперва стреляют, а потом разбираются, в кого выстрелили. В связи с этим Барлес вспоминает об убитых коллегах, размышляет о везении и невезении на войне. Описывается быт военных корреспондентов: горы аппаратуры вперемешку со спальными мешками в гостинице, в каждой розетке торчит зарядное устройство для видеокамеры, неумолчный рев дизель-генераторов, пытающихся держать напряжение в сети гостиницы, попойки в баре, и постоянная спешка, чтобы успеть смонтировать и отправить материал к выпуску теленовостей.'),
+('Королева Юга', (SELECT author_id FROM authors WHERE name = 'Артуро Перес-Реверте'), '1180.00', 'RUB', 'Главной героиней книги является Тереза Мендоса — уроженка штата Синалоа, который известен в Мексике как один из центров незаконной наркоторговли. Парень Терезы — Блондин Давила, будучи одним из членов криминальной группы, подрабатывает в качестве пилота небольшого самолёта, используемого для транспортировки наркотиков. В результате внутренних разборок Давила становится жертвой наёмного убийцы. Заказчики убийства собираются также убить и саму Терезу, но Давила предупреждал её о возможном исходе дела. Она в спешке убегает из дома и скрывается в потайном домике. Там она находит секретный блокнот, который часто упоминал Давила. Он советовал для спасения её жизни не читать блокнот и взамен на жизнь отдать его Эпифанио Варгасу. Тереза собирается это сделать, но неожиданно в домик врываются два бандита, которым был дан приказ ликвидировать Терезу. Это Кот Фьеррос, и Поте Гальвес (Крапчатый). Кот Фьеррос насилует её, что оттягивает время её убийства. Это даёт ей возможность дотянуться до припрятанного в её сумке пистолета, ранить обоих бандитов и скрыться.'),
+('День гнева', (SELECT author_id FROM authors WHERE name = 'Артуро Перес-Реверте'), '970.00', 'RUB', 'Действие романа День гнева происходит в любимую эпоху романиста, в эпоху Наполеоновских войн. Роман День гнева посвящён краеугольному событию испанской истории — мадридскому восстанию 2 мая 1808 года против Наполеона, безжалостно подавленному французскими войсками и послужившему толчком к долгой партизанской войне; восстанию, известному всему миру по картине Ф. Гойи Расстрел повстанцев в ночь на 3 мая 1808 года. С непревзойденным мастерством автор разворачивает перед читателем панораму охваченного бунтом города, любовно выписывает каждое действующее лицо — каменотесов и прачек, придворных и офицеров гвардии, благородных дам и страстных кавалеров… Не скрывает автор и противоречий между необходимостью защищать родину и тем мракобесьем, жестокостью и варварством, которые приносила эта защита. Когда француз одновременно и враг и просветитель. Когда мятеж вызывает одновременно и чувство гордости за свободолюбивый народ и страх перед разнузданностью толпы…'),
+('Капитан Алатристе', (SELECT author_id FROM authors WHERE name = 'Артуро Перес-Реверте'), '540.00', 'RUB', 'Повествование излагается от имени Иньиго Бальбоа. Время действия — март 1622 или 1623 года. Иньиго говорит, что запамятовал точную дату, поскольку с тех пор прошло много лет. Диего Алатристе на самом деле воинского звания капитан не имеет. Это прозвище, которое он получил во Фландрии, после вылазки для завоевания плацдарма во время войны за независимость Нидерландов. Из этой вылазки живыми вернулись всего двое — сам Диего Алатристе и его друг Лопе Бальбоа, отец Иньиго Бальбоа. После войны, оставшись не у дел, капитан вынужден кормиться с острия шпаги. Он может заменить знатного дона во время дуэли, когда речь идет о чести дона. Иногда Алатристе выколачивает просроченные долги. Или объясняет невесть откуда взявшимся наследникам необоснованность их притязаний. Другими словами, его услуги требуются везде, где нужно рискнуть жизнью и испытать удачу.'),
+('Арманс', (SELECT author_id FROM authors WHERE name = 'Стендаль'), '810.00', 'RUB', 'Роман начинается с описания характера Октава де Маливер и его семьи. Пресная жизнь Октава меняется после известия о обсуждаемом законе о возмещении имущества лицам, потерявшим его в ходе революции, но сохранившим верность королю. Состояние семьи Октава в случае принятия закона вырастет во много раз. В салоне родственницы де Бонниве к нему приковано всеобщее внимание, но оно его раздражает, т. к. он сам ничего для этого не сделал. В то время, как все его поздравляют, его кузина Арманс Зоилова молчит. Её молчание в салоне комментируют как зависть. Октав уходит из дома госпожи де Бонниве и чуть не попадает под карету. После прихода домой он чувствует разочарование от перемены своего положения - ведь теперь все любят не его, а только его предполагаемое имущество. Он хочет застрелиться, но затем считает это признаком слабости. Им стали интересоваться в обществе и семье, и это его тяготит. Во время очередного визита в дом де Бонниве он подслушал фразу Арманс о себе Что поделать, он такой же, как все. Я считала, что у него необыкновенная душа, а он сам на себя не похож из-за этих двух миллионов. Это вызвало в нём как радость, так и огорчение. Он хочет объяснить Арманс, что ему это имущество и внимание к его особе противно. Октав постоянно приезжает в салон, разговаривает с посетителями и имеет большой успех, но ему никак не удается поговорить с Арманс. Так продолжается три месяца, пока случай не предоставил ему возможность объяснить своё поведение. Но реакция Арманс была неожиданной - сказав одну нейтральную фразу, она убежала. В своей комнате Арманс плачет. В следующие дни Октав снова не может поговорить с Арманс наедине. Когда они снова говорят, то Арманс говорит, что обсуждается вопрос об её браке. Эта уловка привлекает к ней ещё большее внимание Октава и он старается узнать кто её жених.'),
+('Красное и чёрное', (SELECT author_id FROM authors WHERE name = 'Стендаль'), '630.00', 'RUB', 'Сюжет взят из газетной статьи, которую Стендаль прочитал в гренобльской газете. Это типичный случай, характерен для того времени. Мэр небольшого французского города Верьер (Верьер-де-Жу в департаменте Ду) господин де Реналь берёт в дом гувернёра — молодого человека по имени Жюльен Сорель. Амбициозный и честолюбивый Жюльен изучает богословие, знает латынь и читает наизусть страницы из Библии, с детства он мечтает о славе и признании, а также восхищается Наполеоном. Он считает, что путь священника — верный способ сделать карьеру. Его вежливость и ум резко контрастируют с манерами и характером господина де Реналя, чья жена постепенно проникается к Жюльену симпатией, а затем и влюбляется в него. Они становятся любовниками, но госпожа де Реналь благочестива, её постоянно терзают муки совести, к тому же обманутому мужу приходит анонимное письмо с предупреждением об измене жены. Жюльен, по предварительному сговору с госпожой де Реналь, делает похожее письмо, как бы пришедшее ей. Но по городу ходят слухи, и Жюльену приходится уехать. Он устраивается в духовную семинарию в Безансоне, поразив ректора аббата Пирара знаниями. Когда приходит время выбрать себе духовника, он выбирает Пирара, которого, как потом выяснилось, подозревали в янсенизме.'),
+('Ванина Ванини', (SELECT author_id FROM authors WHERE name = 'Стендаль'), '850.00', 'RUB', 'Главная героиня новеллы — Ванина Ванини, молодая римская аристократка. Она влюбляется в карбонария Пьетро Миссирилли, который скрывается в её доме после убийства предателя. Несмотря на уговоры Ванины, Пьетро отказывается бросить товарищей. Тогда Ванина выдаёт карбонариев полиции. Она приходит к Пьетро в тюрьму и признаётся во всём.'),
+('Пармская обитель', (SELECT author_id FROM authors WHERE name = 'Стендаль'), '490.00', 'RUB', 'В начальных главах описывается восторг, с которым жители севера Италии встретили весной 1796 года французов, освободивших их от тягостного ига Габсбургов. Юный аристократ Фабрицио дель Донго, узнав в 1815 году о возвращении Наполеона с острова Эльба, покидает стоящий на берегу озера Комо замок отца-реакционера и спешит в Бельгию, чтобы на стороне своего кумира принять участие в битве при Ватерлоо.'),
+('Затерянный мир', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '600.00', 'RUB', 'Пытаясь добиться расположения красавицы Глэдис, молодой журналист-ирландец Эдуард Мэлоун просит своего издателя дать ему какое-нибудь опасное задание. Редактор Мак-Ардл даёт ему поручение взять интервью у нелюдимого и чудаковатого профессора Джорджа Челленджера, известного скандалиста и ненавистника журналистов. Профессор тотчас разоблачает научного исследователя и спускает его с лестницы, однако Мэлоун говорит прибывшему полицейскому, что отказывается от жалобы, благодаря чему завоёвывает расположение Челленджера. Профессор рассказывает журналисту о своём расколе с учёным миром. Однажды, во время поездки по Южной Америке, учёный обнаружил в маленькой деревне тело умершего от лихорадки американского натуралиста по имени Мепл-Уайт. Изучив дневник покойного, Челленджер обнаружил, что в нём есть зарисовки совершенно неисследованной местности, а также динозавров рядом с людьми. Пользуясь дневником, профессор совершил поход в указанное место, которого местные туземцы сильно боятся, считая его обиталищем злого духа Курупури, и обнаружил гигантское плато, где подстрелил животное, оказавшееся доисторическим птеродактилем. Однако его лодка перевернулась на пороге и у него остались только крыло ящера и несколько фотографий — довольно слабые доказательства в пользу его теории. На лекции в Музее естествознания Челленджера обвиняют в том, что он выдумал открытое им плато и фальсифицировал доказательства. Он заявляет о готовности совершить повторную экспедицию на плато с тем, чтобы объективные свидетели подтвердили его слова. В экспедицию вместе с ним вызываются ехать скептически настроенный учёный профессор Саммерли, знаменитый охотник и путешественник лорд Джон Рокстон и репортёр Мэлоун.'),
+('Подвиги бригадира Жерара', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '410.00', 'RUB', 'Подвиги бригадира Жерара (англ. The Exploits of Brigadier Gerard ) — один из сборников приключенческих рассказов английского писателя Артура Конан Дойля. Существует два сборника: Подвиги бригадира Жерара и Приключения бригадира Жерара (англ. The Adventures of Gerard). Однако рассказы печатались в хаотичном порядке и в разных сборниках, иногда только в одном из них. Подвиги… были опубликованы в 1894—1896 годах, а Приключения… в 1902—1903 годах. Сборники повествуют о событиях периода наполеоновских войн, повествование стилизовано под мемуары и ведётся от имени вымышленного кавалерийского офицера Жерара.'),
+('Белый отряд', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '750.00', 'RUB', 'Действие романа, повествующего о приключениях главного героя и английских лучников, происходит в середине XIV века (1366—1367) в Англии, Франции и Испании — в разгар Столетней войны. В центре сюжета — Пиренейский поход Эдуарда Чёрного Принца, завершившийся историческим сражением при Нахере 3 апреля 1367 года, которое, однако, в романе лишь упоминается. Автор подробно описывает фигуру английского лучника и йомена в целом, отводя ему решающую роль в победах англичан над французами. Возвышенно описано рыцарство того времени; читатель попадает в эпоху его расцвета. Полное и яркое описание геральдики, природы, рыцарских законов, морских и сухопутных баталий, турниров, пылкой и верной любви — все это принесло роману не только огромную популярность среди читателей многих поколений, но и благожелательные отзывы историков. События, которые предшествовали происходящим в Белом отряде, легли в основу сюжета другого исторического романа А. Конан Дойля — Сэр Найджел, изданного в 1906 году.'),
+('Изгнанники', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '590.00', 'RUB', 'Роман про жизнь в Старом и Новом свете — своеобразный Затерянный мир того же автора. Действие первой части франко-канадского романа, наполненного удивительными и полными опасностей приключениями капитана Амори де Катината и жителя Нью-Йорка Эймоса Грина начинается в Старом Свете, во французской столице, где главные герои исполняют тайное поручение короля Людовика XIV, касающегося его будущего брака. Женившись на своей избраннице, король вынужден исполнить данное епископу обещание очистить Францию от гугенотов, к которым принадлежат капитан де Катинат и его семья. После отмены в 1687 году Нантского эдикта, Эймос Грин предлагает товарищу бежать от королевского гнева в Новый Свет, где Амори и Адели удаётся найти новых друзей среди французских поселенцев.'),
+('Маракотова бездна', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '820.00', 'RUB', 'В романе описывается экспедиция профессора Маракота, который со своими спутниками — биологом Сайресом Хедли и механиком Биллом Сканлэном — опускается с помощью аппарата типа батисферы в глубоководную впадину Атлантического океана (находящуюся в 200 милях юго-западнее Канарских островов), где открывает затонувший город, населённый потомками атлантов. Исследователи знакомятся с культурой и бытом жителей глубин, а также с флорой и фауной дна океана. Затем они поднимаются на поверхность океана с помощью шаров, наполненных лёгким газом. Последние две главы романа (иногда считается, что это отдельный рассказ, сюжетно примыкающий к роману) посвящены главным образом описанию драматического эпизода, произошедшего во время пребывания героев на дне океана. В одном из заброшенных храмов они встречают зловещего Владыку Тёмной Стороны — бессмертного и практически всемогущего — который сообщает им о своём намерении уничтожить потомков атлантов. Однако профессору Маракоту с помощью мистических сил удаётся изгнать это воплощение зла. По стилистике эта часть романа значительно отличается от рационалистической первой части, при этом сюжетно слабее.'),
+('Приключения Шерлока Холмса', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '1100.00', 'RUB', 'Приключения Шерлока Хо́лмса (англ. The Adventures of Sherlock Holmes) — сборник из 12 детективных рассказов, созданных Артуром Конаном Дойлом. После создания двух повестей о Шерлоке Холмсе Этюд в багровых тонах (первая публикация в 1887 году) и Знак четырёх (1890) Конан Дойл решил переключиться на короткие рассказы о Холмсе. Рассказы публиковались раз в месяц в номерах с июля 1891 года по июнь 1892 года в недавно появившемся Strand Magazine. Дойлу платили по 30 гиней за каждый рассказ (за целую повесть Этюд в багровых тонах писатель получил от издательства Ward, Lock & Co. всего 25 фунтов). Первым 25 июня 1891 года в июльском номере журнала был опубликован рассказ Скандал в Богемии (он же затем был первым и в сборнике). Именно в этом рассказе впервые появился персонаж Ирэн Адлер. Публикация рассказов способствовала росту популярности журнала. Целиком сборник был опубликован 14 октября 1892 года издателем Strand Magazine Джорджем Ньюнсом и является первым сборником рассказов о Холмсе. Первый тираж составил 10 000 экземпляров, ещё 4500 копий было напечатано в США издательством Harper Brothers.'),
+('Воспоминания Шерлока Холмса', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '1030.00', 'RUB', 'Записки о Шерлоке Холмсе или Воспоминания Шерлока Холмса (англ. The Memoirs of Sherlock Holmes) — сборник из 11 детективных рассказов Артура Конана Дойла, опубликованный в 1893 году. Как и рассказы из первого сборника о Холмсе Приключения Шерлока Холмса, истории сначала публиковались в Strand Magazine с декабря 1892 по декабрь 1893 года. Единым сборником рассказы в Великобритании вышли в конце 1893 года в издательстве G. Newnes Ltd. при том, что на издании был указан 1894 год. В США издательство Harper & Brothers выпустило сборник в феврале 1894 года. После этого сборника Конан Дойл планировал завершить историю похождений Холмса, убив персонажа в рассказе Последнее дело Холмса. Однако настойчивые требования читателей вынудили писателя вернуться к своему персонажу, написав в 1901—1902 годах повесть Собака Баскервилей, действие которой происходит до событий Последнего дела Холмса. В 1903 году писатель приступил к новому сборнику рассказов Возвращение Шерлока Холмса, где выяснилось, что Холмс не погиб в рассказе Последнее дело Холмса.'),
+('Сэр Найджел Лоринг', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '950.00', 'RUB', 'Роман повествует о юности главного героя книги Найджела Лоринга на службе у короля Англии, Нормандии и Аквитании Эдуарда III Английского (1327—1370) в начале Столетней Войны. Действие происходит на территории Англии, Франции, Бретани и в проливе Ла-Манш в 1349—1356 годах, во времена расцвета рыцарства. В романе, написанном ярким живым языком, весьма интересно, но несколько идеализированно передана жизнь английских лучников и рыцарей того времени. В частности сам сэр Найджел Лоринг, небогатый дворянин, разоренный после неурожаев Великой Чумы и притесняемый местным аббатством, вынужденный продать часть семейных реликвий для покупки снаряжения и обрученный с невестой, на протяжении всего произведения отказывается брать пленников для получения выкупа (и даже часть добычи, собранной Эйлвардом в замке барона-разбойника) ради поддержания чести и участия в поединках и сражениях.'),
+('Этюд в багровых тонах', (SELECT author_id FROM authors WHERE name = 'Артур Конан Дойл'), '600.00', 'RUB', 'Этюд в багровых тонах, в другом распространённом переводе — Красным по белому (англ. A Study in Scarlet) — детективная повесть Артура Конана Дойла, опубликованная в 1887 году. Именно в этом произведении впервые появляется Шерлок Холмс. Первое издание в журнале было проиллюстрировано Дэвидом Генри Фристоном. Первое издание в качестве книги было проиллюстрировано отцом Артура, Чарльзом Дойлом, а второе — Джорджем Хатчинсоном. 27-летний Артур Конан Дойл написал повесть всего за три недели. После ряда отказов повесть впервые была опубликована издательством Ward and Lock в журнале Beeton’s Christmas Annual за 1887 год. Автор получил 25 фунтов стерлингов в обмен на все права на повесть (сам Дойл настаивал на роялти). Уже в следующем 1888 году то же издательство выпустило повесть в качестве отдельной книги, а ещё через год увидело свет второе издание произведения.');
@@ -0,0 +1,8 @@
+// Package seed holds the sample catalogue the storage can be filled with,
+// it is kept apart from the schema migrations and works for every sql backend
+package seed
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
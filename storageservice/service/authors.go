@@ -0,0 +1,127 @@
+package storageservice
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pkg/errors"
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var ErrNoSuchAuthor = errors.New("no author with given id")
+
+func (serv *StorageService) GetAuthor(ctx context.Context, req *GetAuthorRequest) (*Author, error) {
+	ctx, span, err := serv.startSpan(ctx, "GetAuthor")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	data, err := serv.repo.GetAuthorById(ctx, req.Id)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrNoSuchAuthor
+	}
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return authorToResponse(data), nil
+}
+
+func (serv *StorageService) ListAuthors(ctx context.Context, req *ListAuthorsRequest) (*ListAuthorsResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "ListAuthors")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	cur, err := decodeCursor(req.Cursor, authorsSortKey, false, "")
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	limit := pageLimit(req.PageSize)
+
+	params := storagedb.ListAuthorsParams{PageLimit: limit + 1}
+	if cur != nil {
+		params.AfterID = sql.NullInt32{Int32: cur.Id, Valid: true}
+	}
+	if req.NameContains != nil {
+		params.NamePattern = sql.NullString{String: likeContains(*req.NameContains), Valid: true}
+	}
+
+	data, err := serv.repo.ListAuthors(ctx, params)
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	resp := &ListAuthorsResponse{}
+
+	if len(data) > int(limit) {
+		data = data[:limit]
+		resp.NextCursor = encodeIdCursor(authorsSortKey, data[len(data)-1].AuthorID)
+	}
+
+	for _, author := range data {
+		resp.Authors = append(resp.Authors, authorToResponse(author))
+	}
+
+	return resp, nil
+}
+
+// ListAuthorBooks is ListBooks filtered by author, unknown author is NotFound rather than an empty page
+func (serv *StorageService) ListAuthorBooks(ctx context.Context, req *ListAuthorBooksRequest) (*ListValuesResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "ListAuthorBooks")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("author.id", int(req.AuthorId)))
+
+	if _, err := serv.repo.GetAuthorById(ctx, req.AuthorId); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = ErrNoSuchAuthor
+		}
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	resp, err := serv.listBooks(ctx, &ListValuesRequest{
+		PageSize:   req.PageSize,
+		Cursor:     req.Cursor,
+		Filter:     &BookFilter{AuthorId: &req.AuthorId},
+		SortBy:     req.SortBy,
+		Descending: req.Descending,
+	})
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return resp, nil
+}
+
+// resolveAuthor returns id of the existing author, or of the author called name
+// which is created with bio if there is none
+func resolveAuthor(ctx context.Context, queries storagedb.Querier, id int32, name, bio string) (int32, error) {
+	if id != 0 {
+		author, err := queries.GetAuthorById(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrNoSuchAuthor
+		}
+		return author.AuthorID, err
+	}
+
+	return queries.UpsertAuthor(ctx, storagedb.UpsertAuthorParams{
+		Name: name,
+		Bio:  sql.NullString{String: bio, Valid: bio != ""},
+	})
+}
+
+func authorToResponse(data storagedb.Author) *Author {
+	return &Author{
+		Id:   data.AuthorID,
+		Name: data.Name,
+		Bio:  data.Bio.String,
+	}
+}
@@ -0,0 +1,87 @@
+package storageservice
+
+import (
+	"context"
+
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
+)
+
+// DefaultCurrency is the currency of books added without one, the seed catalogue is priced in roubles
+const DefaultCurrency = "RUB"
+
+// requestCurrency is ISO 4217 code of the base price of a new book
+func requestCurrency(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+
+	return currency
+}
+
+// updatePrices applies native prices of UpdateValueRequest to book of base currency
+func updatePrices(ctx context.Context, queries storagedb.Querier, req *UpdateValueRequest, base string) error {
+	if req.ReplacePrices {
+		keep := make([]string, 0, len(req.Prices))
+		for currency, amount := range req.Prices {
+			if amount != "" {
+				keep = append(keep, currency)
+			}
+		}
+
+		if err := queries.DeleteOtherBookPrices(ctx, storagedb.DeleteOtherBookPricesParams{
+			BookID: req.Id,
+			Keep:   keep,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for currency, amount := range req.Prices {
+		var err error
+		if amount == "" || currency == base {
+			err = queries.DeleteBookPrice(ctx, storagedb.DeleteBookPriceParams{BookID: req.Id, Currency: currency})
+		} else {
+			err = queries.UpsertBookPrice(ctx, storagedb.UpsertBookPriceParams{
+				BookID:   req.Id,
+				Currency: currency,
+				Amount:   nullPrice(amount).String,
+			})
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	// a native price may become the base one when currency changes
+	return queries.DeleteBookPrice(ctx, storagedb.DeleteBookPriceParams{BookID: req.Id, Currency: base})
+}
+
+// attachPrices fills native prices of books with one query
+func attachPrices(ctx context.Context, queries storagedb.Querier, books ...*GetValueResponse) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	byId := make(map[int32]*GetValueResponse, len(books))
+	ids := make([]int32, 0, len(books))
+	for _, book := range books {
+		byId[book.Id] = book
+		ids = append(ids, book.Id)
+	}
+
+	rows, err := queries.ListBookPrices(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		book := byId[row.BookID]
+		if book.Prices == nil {
+			book.Prices = map[string]string{}
+		}
+		book.Prices[row.Currency] = row.Amount
+	}
+
+	return nil
+}
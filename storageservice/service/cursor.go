@@ -0,0 +1,137 @@
+package storageservice
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var (
+	ErrBadCursor = errors.New("malformed page cursor")
+)
+
+// pageCursor points at the last row of the page together with its sort key
+// and the filter of the listing, the client gets it base64-encoded and must
+// not rely on its contents
+type pageCursor struct {
+	SortBy string `json:"s"`
+	Desc   bool   `json:"d,omitempty"`
+	Filter string `json:"f,omitempty"`
+	Id     int32  `json:"i"`
+	Title  string `json:"t,omitempty"`
+	Price  string `json:"p,omitempty"`
+}
+
+func encodeCursor(sortBy string, desc bool, filter string, last storagedb.BookDetail) string {
+	cur := pageCursor{
+		SortBy: sortBy,
+		Desc:   desc,
+		Filter: filter,
+		Id:     last.BookID,
+	}
+
+	switch sortBy {
+	case "title":
+		cur.Title = last.Title
+	case "price":
+		cur.Price = responsePrice(last.Price) // same as COALESCE(price, 0) of the query
+	}
+
+	raw, _ := json.Marshal(cur)
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// sort keys of listings in id order only, they keep cursors of one listing from being used in another
+const (
+	authorsSortKey = "author_id"
+	trashSortKey   = "deleted"
+)
+
+// encodeIdCursor points after the last row of a listing in id order
+func encodeIdCursor(sortKey string, lastId int32) string {
+	raw, _ := json.Marshal(pageCursor{SortBy: sortKey, Id: lastId})
+
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor returns nil for the first page
+func decodeCursor(cursor, sortBy string, desc bool, filter string) (*pageCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrBadCursor
+	}
+
+	var cur pageCursor
+	if err := json.Unmarshal(raw, &cur); err != nil {
+		return nil, ErrBadCursor
+	}
+
+	// cursor from a listing with another order points to a wrong place
+	if cur.SortBy != sortBy || cur.Desc != desc {
+		return nil, ErrBadCursor
+	}
+
+	// with another filter rows between the cursor and the next match are skipped
+	if cur.Filter != filter {
+		return nil, ErrBadCursor
+	}
+
+	return &cur, nil
+}
+
+// filterHash identifies the filter of a book listing for its cursors,
+// it is taken from normalized params so that "5" and "5.00" are the same bound
+func filterHash(params storagedb.ListBooksParams) string {
+	raw, _ := json.Marshal([]any{
+		params.Author,
+		params.AuthorID,
+		params.TitlePattern,
+		params.MinPrice,
+		params.MaxPrice,
+	})
+	sum := sha256.Sum256(raw)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
+
+func pageLimit(pageSize int32) int32 {
+	switch {
+	case pageSize <= 0:
+		return defaultPageSize
+	case pageSize > maxPageSize:
+		return maxPageSize
+	default:
+		return pageSize
+	}
+}
+
+func sortColumn(field SortField) string {
+	switch field {
+	case SortField_SORT_FIELD_TITLE:
+		return "title"
+	case SortField_SORT_FIELD_PRICE:
+		return "price"
+	default:
+		return "id"
+	}
+}
+
+// likeContains builds ILIKE pattern matching s literally anywhere in the value
+func likeContains(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+	return "%" + s + "%"
+}
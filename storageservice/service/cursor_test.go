@@ -0,0 +1,240 @@
+package storageservice
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// addPagingBooks adds books with repeated titles and prices so that
+// pages break inside runs of equal sort keys
+func addPagingBooks(t *testing.T, serv *StorageService) []*GetValueResponse {
+	t.Helper()
+
+	books := []struct{ title, author, price string }{
+		{"Emma", "Austen", "9.99"},
+		{"Dune", "Herbert", "15.00"},
+		{"Emma", "Other", "9.99"},
+		{"Anna", "Tolstoy", "100.00"},
+		{"Dune", "Other", "2.50"},
+		{"Zorba", "Kazantzakis", "15.00"},
+		{"Beloved", "Morrison", "9.99"},
+	}
+
+	var added []*GetValueResponse
+	for _, b := range books {
+		resp, err := serv.AddBook(context.Background(), &SetValueRequest{
+			Title:        b.title,
+			Author:       b.author,
+			PriceDecimal: b.price,
+		})
+		if err != nil {
+			t.Fatalf("AddBook(%q) error = %v", b.title, err)
+		}
+
+		book, err := serv.GetBookById(context.Background(), &GetValueRequest{Id: resp.Id})
+		if err != nil {
+			t.Fatalf("GetBookById(%d) error = %v", resp.Id, err)
+		}
+		added = append(added, book)
+	}
+
+	return added
+}
+
+// listAll walks the listing page by page and returns ids in the order received
+func listAll(t *testing.T, serv *StorageService, req *ListValuesRequest) []int32 {
+	t.Helper()
+
+	var ids []int32
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatalf("ListBooks() does not reach the last page")
+		}
+
+		resp, err := serv.ListBooks(context.Background(), req)
+		if err != nil {
+			t.Fatalf("ListBooks() error = %v", err)
+		}
+		if len(resp.Books) > int(req.PageSize) {
+			t.Fatalf("ListBooks() returned %d books, page size is %d", len(resp.Books), req.PageSize)
+		}
+
+		for _, book := range resp.Books {
+			ids = append(ids, book.Id)
+		}
+
+		if resp.NextCursor == "" {
+			return ids
+		}
+		req.Cursor = resp.NextCursor
+	}
+}
+
+func TestListBooksPaging(t *testing.T) {
+	serv := newMemoryStorageService(t)
+	books := addPagingBooks(t, serv)
+
+	price := func(b *GetValueResponse) float64 {
+		f, _ := strconv.ParseFloat(b.PriceDecimal, 64)
+		return f
+	}
+
+	tests := []struct {
+		sortBy SortField
+		less   func(a, b *GetValueResponse) bool
+	}{
+		{SortField_SORT_FIELD_ID, func(a, b *GetValueResponse) bool { return false }},
+		{SortField_SORT_FIELD_TITLE, func(a, b *GetValueResponse) bool { return a.Title < b.Title }},
+		{SortField_SORT_FIELD_PRICE, func(a, b *GetValueResponse) bool { return price(a) < price(b) }},
+	}
+
+	for _, tt := range tests {
+		for _, desc := range []bool{false, true} {
+			t.Run(tt.sortBy.String()+"/desc="+strconv.FormatBool(desc), func(t *testing.T) {
+				// ties are broken by id in the direction of the listing
+				want := append([]*GetValueResponse(nil), books...)
+				sort.SliceStable(want, func(i, j int) bool {
+					a, b := want[i], want[j]
+					if desc {
+						a, b = b, a
+					}
+					if tt.less(a, b) {
+						return true
+					}
+					if tt.less(b, a) {
+						return false
+					}
+					return a.Id < b.Id
+				})
+
+				var wantIds []int32
+				for _, b := range want {
+					wantIds = append(wantIds, b.Id)
+				}
+
+				for _, pageSize := range []int32{1, 2, 3, 100} {
+					got := listAll(t, serv, &ListValuesRequest{PageSize: pageSize, SortBy: tt.sortBy, Descending: desc})
+					if !equalIds(got, wantIds) {
+						t.Fatalf("page size %d: ids = %v, want %v", pageSize, got, wantIds)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestListBooksFilteredPaging(t *testing.T) {
+	serv := newMemoryStorageService(t)
+	books := addPagingBooks(t, serv)
+
+	minPrice := "9.99"
+	got := listAll(t, serv, &ListValuesRequest{
+		PageSize: 2,
+		SortBy:   SortField_SORT_FIELD_TITLE,
+		Filter:   &BookFilter{MinPriceDecimal: &minPrice},
+	})
+
+	// books are added in id order, all but "Dune" for 2.50 pass the filter
+	var want []*GetValueResponse
+	for _, b := range books {
+		if b.PriceDecimal != "2.50" {
+			want = append(want, b)
+		}
+	}
+	sort.SliceStable(want, func(i, j int) bool { return want[i].Title < want[j].Title })
+
+	var wantIds []int32
+	for _, b := range want {
+		wantIds = append(wantIds, b.Id)
+	}
+
+	if !equalIds(got, wantIds) {
+		t.Fatalf("ids = %v, want %v", got, wantIds)
+	}
+}
+
+func TestListBooksCursorBoundToListing(t *testing.T) {
+	serv := newMemoryStorageService(t)
+	addPagingBooks(t, serv)
+
+	author := "Austen"
+	minPrice, samePrice, otherPrice := "9.99", "09.99", "2.50"
+
+	first, err := serv.ListBooks(context.Background(), &ListValuesRequest{
+		PageSize: 1,
+		SortBy:   SortField_SORT_FIELD_PRICE,
+		Filter:   &BookFilter{MinPriceDecimal: &minPrice},
+	})
+	if err != nil {
+		t.Fatalf("ListBooks() error = %v", err)
+	}
+	if first.NextCursor == "" {
+		t.Fatalf("ListBooks() of several books has no next cursor")
+	}
+
+	tests := []struct {
+		name string
+		req  *ListValuesRequest
+		want codes.Code
+	}{
+		{
+			name: "same filter written otherwise",
+			req:  &ListValuesRequest{SortBy: SortField_SORT_FIELD_PRICE, Filter: &BookFilter{MinPriceDecimal: &samePrice}},
+			want: codes.OK,
+		},
+		{
+			name: "other bound",
+			req:  &ListValuesRequest{SortBy: SortField_SORT_FIELD_PRICE, Filter: &BookFilter{MinPriceDecimal: &otherPrice}},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "added author",
+			req:  &ListValuesRequest{SortBy: SortField_SORT_FIELD_PRICE, Filter: &BookFilter{MinPriceDecimal: &minPrice, Author: &author}},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "no filter",
+			req:  &ListValuesRequest{SortBy: SortField_SORT_FIELD_PRICE},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "other sorting",
+			req:  &ListValuesRequest{SortBy: SortField_SORT_FIELD_TITLE, Filter: &BookFilter{MinPriceDecimal: &minPrice}},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "other direction",
+			req:  &ListValuesRequest{SortBy: SortField_SORT_FIELD_PRICE, Descending: true, Filter: &BookFilter{MinPriceDecimal: &minPrice}},
+			want: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.PageSize = 1
+			tt.req.Cursor = first.NextCursor
+
+			_, err := serv.ListBooks(context.Background(), tt.req)
+			if status.Code(err) != tt.want {
+				t.Fatalf("ListBooks() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func equalIds(a, b []int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
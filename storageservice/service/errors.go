@@ -0,0 +1,97 @@
+package storageservice
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net"
+
+	"github.com/lib/pq"
+	"github.com/ncruces/go-sqlite3"
+	"github.com/pkg/errors"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError converts err to grpc status, marks span as failed
+// and logs errors the client can do nothing about
+func (serv *StorageService) statusError(ctx context.Context, span trace.Span, err error) error {
+	st := toStatus(err)
+
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, st.Message())
+
+	switch st.Code() {
+	case codes.Internal, codes.Unavailable, codes.Unknown:
+		principal, _, _ := principalFromContext(ctx)
+		serv.logger.Error().Err(err).
+			Str("code", st.Code().String()).
+			Str("principal", principal).
+			Str("trace_id", span.SpanContext().TraceID().String()).
+			Msg("storage request failed")
+	}
+
+	return st.Err()
+}
+
+func toStatus(err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, ErrNoSuchKey):
+		return status.New(codes.NotFound, ErrNoSuchKey.Error())
+	case errors.Is(err, ErrNoSuchAuthor), errors.Is(err, ErrNotInTrash):
+		return status.New(codes.NotFound, err.Error())
+	case errors.Is(err, ErrPermissionDenied):
+		return status.New(codes.PermissionDenied, err.Error())
+	case errors.Is(err, ErrBadCursor), errors.Is(err, ErrEmptyQuery), errors.Is(err, ErrBadTraceId),
+		errors.Is(err, ErrBadIdempotencyKey), errors.Is(err, ErrBadPrice), errors.Is(err, ErrAmbiguousPrincipal):
+		return status.New(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrVersionMismatch):
+		return status.New(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.New(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.New(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return status.New(codes.Unavailable, "storage is unavailable")
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Class() {
+		case "23": // integrity constraint violation
+			if pqErr.Code.Name() == "unique_violation" {
+				return status.New(codes.AlreadyExists, pqErr.Message)
+			}
+			return status.New(codes.InvalidArgument, pqErr.Message)
+		case "22": // data exception, e.g. value too long for the column
+			return status.New(codes.InvalidArgument, pqErr.Message)
+		case "08", "53", "57": // connection, insufficient resources, operator intervention
+			return status.New(codes.Unavailable, "storage is unavailable")
+		}
+	}
+
+	var sqliteErr *sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch {
+		case errors.Is(err, sqlite3.CONSTRAINT_UNIQUE), errors.Is(err, sqlite3.CONSTRAINT_PRIMARYKEY):
+			return status.New(codes.AlreadyExists, sqliteErr.Error())
+		case errors.Is(err, sqlite3.CONSTRAINT):
+			return status.New(codes.InvalidArgument, sqliteErr.Error())
+		case errors.Is(err, sqlite3.BUSY), errors.Is(err, sqlite3.LOCKED), errors.Is(err, sqlite3.FULL):
+			return status.New(codes.Unavailable, "storage is unavailable")
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return status.New(codes.Unavailable, "storage is unavailable")
+	}
+
+	return status.New(codes.Internal, "internal storage error")
+}
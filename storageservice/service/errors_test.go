@@ -0,0 +1,46 @@
+package storageservice
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{"no rows", errors.Wrap(sql.ErrNoRows, "got err from sql db"), codes.NotFound},
+		{"no such key", ErrNoSuchKey, codes.NotFound},
+		{"no such author", ErrNoSuchAuthor, codes.NotFound},
+		{"not in trash", ErrNotInTrash, codes.NotFound},
+		{"permission denied", ErrPermissionDenied, codes.PermissionDenied},
+		{"bad cursor", ErrBadCursor, codes.InvalidArgument},
+		{"version mismatch", ErrVersionMismatch, codes.FailedPrecondition},
+		{"empty query", ErrEmptyQuery, codes.InvalidArgument},
+		{"unique violation", &pq.Error{Code: "23505"}, codes.AlreadyExists},
+		{"not null violation", &pq.Error{Code: "23502"}, codes.InvalidArgument},
+		{"value too long", &pq.Error{Code: "22001"}, codes.InvalidArgument},
+		{"connection failure", &pq.Error{Code: "08006"}, codes.Unavailable},
+		{"admin shutdown", &pq.Error{Code: "57P01"}, codes.Unavailable},
+		{"conn done", sql.ErrConnDone, codes.Unavailable},
+		{"deadline", context.DeadlineExceeded, codes.DeadlineExceeded},
+		{"already status", status.Error(codes.PermissionDenied, "denied"), codes.PermissionDenied},
+		{"unknown", errors.New("boom"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toStatus(tt.err).Code(); got != tt.want {
+				t.Errorf("toStatus(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
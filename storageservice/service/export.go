@@ -0,0 +1,67 @@
+package storageservice
+
+import (
+	"database/sql"
+
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// books are read from db in pages of this size
+const exportPageSize = 500
+
+// ExportBooks streams filtered books in id order, reading them page by page
+// so the whole table is never held in memory
+func (serv *StorageService) ExportBooks(req *ExportRequest, stream StorageService_ExportBooksServer) error {
+	ctx, span, err := serv.startSpan(stream.Context(), "ExportBooks")
+	if err != nil {
+		return err
+	}
+	defer span.End()
+
+	params := storagedb.ListBooksParams{
+		SortBy:    sortColumn(SortField_SORT_FIELD_ID),
+		PageLimit: exportPageSize,
+	}
+	if err := applyFilter(&params, req.Filter); err != nil {
+		return serv.statusError(ctx, span, err)
+	}
+
+	sent, pages := 0, 0
+	defer func() {
+		span.SetAttributes(
+			attribute.Int("export.rows", sent),
+			attribute.Int("export.pages", pages),
+		)
+	}()
+
+	for {
+		data, err := serv.repo.ListBooks(ctx, params)
+		if err != nil {
+			return serv.statusError(ctx, span, err)
+		}
+		pages++
+
+		books := make([]*GetValueResponse, 0, len(data))
+		for _, book := range data {
+			books = append(books, bookToResponse(book))
+		}
+
+		if err := attachPrices(ctx, serv.repo, books...); err != nil {
+			return serv.statusError(ctx, span, err)
+		}
+
+		for _, book := range books {
+			if err := stream.Send(book); err != nil {
+				return serv.statusError(ctx, span, err)
+			}
+			sent++
+		}
+
+		if len(data) < exportPageSize {
+			return nil
+		}
+
+		params.AfterID = sql.NullInt32{Int32: data[len(data)-1].BookID, Valid: true}
+	}
+}
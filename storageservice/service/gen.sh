@@ -2,6 +2,9 @@
 
 
 # gRPC server
-protoc --go_out=. --go_opt=paths=source_relative \
+# google/api protos for REST transcoding are in third_party
+protoc -I . -I ./third_party \
+    --go_out=. --go_opt=paths=source_relative \
     --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+    --grpc-gateway_out=. --grpc-gateway_opt=paths=source_relative \
     ./storageservice.proto
@@ -0,0 +1,173 @@
+package storageservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// IdempotencyKeyHeader is the metadata key clients pass to make AddBook retry safe
+	IdempotencyKeyHeader = "idempotency-key"
+	// IdempotentReplayHeader is set in response header metadata when the stored result is returned
+	IdempotentReplayHeader = "idempotent-replay"
+
+	// ReasonIdempotencyKeyReused is ErrorInfo reason of AddBook called
+	// with a known idempotency key and a different request
+	ReasonIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	ErrorDomain                = "storageservice"
+
+	DefaultIdempotencyKeyTTL = 24 * time.Hour
+	maxIdempotencyKeyLen     = 255
+)
+
+var ErrBadIdempotencyKey = errors.New("idempotency key must be 1 to 255 characters long")
+
+func idempotencyKeyFromContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[IdempotencyKeyHeader]) == 0 {
+		return "", nil
+	}
+
+	key := md[IdempotencyKeyHeader][0]
+	if key == "" || len(key) > maxIdempotencyKeyLen {
+		return "", ErrBadIdempotencyKey
+	}
+
+	return key, nil
+}
+
+// requestHash identifies request body stored along with idempotency key
+func requestHash(req proto.Message) (string, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal request")
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// scopedIdempotencyKey is the stored key, prefixed with the principal so that
+// clients cannot replay results of each other; the escaped principal has no colon
+func scopedIdempotencyKey(ctx context.Context, key string) (string, error) {
+	subject, _, err := principalFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return url.QueryEscape(subject) + ":" + key, nil
+}
+
+// addBookIdempotent inserts the book once per key: concurrent requests with
+// the same key are serialized by an advisory lock, repeated ones get the stored id
+func (serv *StorageService) addBookIdempotent(ctx context.Context, span trace.Span, key string, req *SetValueRequest) (int32, error) {
+	hash, err := requestHash(req)
+	if err != nil {
+		return 0, err
+	}
+
+	scoped, err := scopedIdempotencyKey(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		id       int32
+		replayed bool
+	)
+
+	err = serv.repo.InTx(ctx, func(queries storagedb.Tx) error {
+		if err := queries.LockIdempotencyKey(ctx, scoped); err != nil {
+			return err
+		}
+
+		stored, err := queries.GetIdempotencyKey(ctx, scoped)
+		switch {
+		case err == nil:
+			if stored.RequestHash != hash {
+				return idempotencyKeyReusedError(key)
+			}
+			id, replayed = stored.BookID, true
+			return nil
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		}
+
+		if id, err = insertBook(ctx, queries, req); err != nil {
+			return err
+		}
+
+		return queries.SaveIdempotencyKey(ctx, storagedb.SaveIdempotencyKeyParams{
+			Key:         scoped,
+			RequestHash: hash,
+			BookID:      id,
+			TtlSeconds:  int32(serv.idempotencyKeyTTL / time.Second),
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Bool("idempotency.replayed", replayed))
+
+	if replayed {
+		_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayHeader, "true"))
+	}
+
+	return id, nil
+}
+
+func idempotencyKeyReusedError(key string) error {
+	st := status.New(codes.InvalidArgument, "idempotency key was used with a different request")
+
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   ReasonIdempotencyKeyReused,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{"key": key},
+	})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// PurgeIdempotencyKeys deletes expired keys every interval until ctx is done,
+// expired keys are ignored by AddBook anyway so this only bounds the table size
+func (serv *StorageService) PurgeIdempotencyKeys(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := serv.repo.DeleteExpiredIdempotencyKeys(ctx)
+		if err != nil {
+			serv.logger.Error().Err(err).Msg("failed to purge idempotency keys")
+			continue
+		}
+
+		if n > 0 {
+			serv.logger.Info().Int64("deleted", n).Msg("expired idempotency keys purged")
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package storageservice
+
+import (
+	"context"
+	"io"
+
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+)
+
+// rows are inserted in transactions of this size
+const importBatchSize = 500
+
+// ImportBooks inserts streamed rows in batches; rows failing validation or
+// constraints are reported in response, other errors abort the import
+// leaving already committed batches in place
+func (serv *StorageService) ImportBooks(stream StorageService_ImportBooksServer) error {
+	ctx, span, err := serv.startSpan(stream.Context(), "ImportBooks")
+	if err != nil {
+		return err
+	}
+	defer span.End()
+
+	resp := &ImportResponse{}
+	batch := make([]*ImportBookRow, 0, importBatchSize)
+	batchIndex := 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := serv.importBatch(ctx, resp, batch, batchIndex)
+		batch = batch[:0]
+		batchIndex++
+
+		return err
+	}
+
+	for {
+		row, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return serv.statusError(ctx, span, err)
+		}
+
+		resp.Received++
+
+		if row.Book == nil {
+			resp.Errors = append(resp.Errors, &ImportRowError{Row: row.Row, Message: "empty row"})
+			continue
+		}
+
+		if err := validateBook(newBookFromRequest(row.Book)); err != nil {
+			resp.Errors = append(resp.Errors, importRowError(row.Row, err))
+			continue
+		}
+
+		batch = append(batch, row)
+
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return serv.statusError(ctx, span, err)
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return serv.statusError(ctx, span, err)
+	}
+
+	resp.Failed = int32(len(resp.Errors))
+
+	span.SetAttributes(
+		attribute.Int("import.received", int(resp.Received)),
+		attribute.Int("import.imported", int(resp.Imported)),
+		attribute.Int("import.failed", int(resp.Failed)),
+		attribute.Int("import.batches", batchIndex),
+	)
+
+	return stream.SendAndClose(resp)
+}
+
+// importBatch inserts rows in one transaction, savepoint per row
+// lets the rest of the batch survive a rejected row
+func (serv *StorageService) importBatch(ctx context.Context, resp *ImportResponse, batch []*ImportBookRow, index int) error {
+	ctx, span := serv.tracer.Tracer("grpc-tracer").Start(ctx, "ImportBooks.batch",
+		trace.WithAttributes(
+			attribute.Int("batch.index", index),
+			attribute.Int("batch.size", len(batch)),
+		),
+	)
+	defer span.End()
+
+	var (
+		imported  int32
+		rowErrors []*ImportRowError
+	)
+
+	err := serv.repo.InTx(ctx, func(tx storagedb.Tx) error {
+		for _, row := range batch {
+			err := tx.Savepoint(ctx, func() error {
+				_, err := insertBook(ctx, tx, row.Book)
+				return err
+			})
+			if err == nil {
+				imported++
+				continue
+			}
+
+			switch toStatus(err).Code() {
+			case codes.InvalidArgument, codes.AlreadyExists:
+			default:
+				return err
+			}
+
+			rowErrors = append(rowErrors, importRowError(row.Row, err))
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "batch failed")
+		return err
+	}
+
+	span.SetAttributes(attribute.Int("batch.failed", len(rowErrors)))
+
+	resp.Imported += imported
+	resp.Errors = append(resp.Errors, rowErrors...)
+
+	return nil
+}
+
+func importRowError(row int32, err error) *ImportRowError {
+	st := toStatus(err)
+
+	rowErr := &ImportRowError{Row: row, Message: st.Message()}
+
+	for _, detail := range st.Details() {
+		badRequest, ok := detail.(*errdetails.BadRequest)
+		if !ok {
+			continue
+		}
+
+		rowErr.Fields = map[string]string{}
+		for _, v := range badRequest.FieldViolations {
+			rowErr.Fields[v.Field] = v.Description
+		}
+	}
+
+	return rowErr
+}
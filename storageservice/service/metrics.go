@@ -0,0 +1,59 @@
+package storageservice
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// pooledRepository is a BookRepository with a connection pool, the sql backends are
+type pooledRepository interface {
+	Stats() sql.DBStats
+}
+
+// dbStatsCollector exports sql.DBStats of the repository connection pool
+type dbStatsCollector struct {
+	repo pooledRepository
+
+	open         *prometheus.Desc
+	inUse        *prometheus.Desc
+	idle         *prometheus.Desc
+	waitCount    *prometheus.Desc
+	waitDuration *prometheus.Desc
+}
+
+func newDBStatsCollector(repo pooledRepository, backend string) *dbStatsCollector {
+	labels := prometheus.Labels{"backend": backend}
+
+	return &dbStatsCollector{
+		repo: repo,
+		open: prometheus.NewDesc("storage_db_connections_open",
+			"connections to the database, in use and idle", nil, labels),
+		inUse: prometheus.NewDesc("storage_db_connections_in_use",
+			"connections running queries", nil, labels),
+		idle: prometheus.NewDesc("storage_db_connections_idle",
+			"connections kept open in the pool", nil, labels),
+		waitCount: prometheus.NewDesc("storage_db_wait_count_total",
+			"queries which waited for a free connection", nil, labels),
+		waitDuration: prometheus.NewDesc("storage_db_wait_duration_seconds_total",
+			"time queries waited for a free connection", nil, labels),
+	}
+}
+
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+}
+
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.repo.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+}
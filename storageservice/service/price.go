@@ -0,0 +1,61 @@
+package storageservice
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// prices are stored as NUMERIC(12,2): 10 integer and 2 fraction digits
+const (
+	priceIntDigits  = 10
+	priceFracDigits = 2
+)
+
+var ErrBadPrice = errors.New("price must be a non-negative decimal with at most 2 fraction digits")
+
+// NormalizePrice checks decimal price and formats it with exactly 2 fraction digits,
+// e.g. "680" and "680.0" become "680.00"; an empty price is "0.00"
+func NormalizePrice(price string) (string, error) {
+	intPart, fracPart, hasPoint := strings.Cut(strings.TrimSpace(price), ".")
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	switch {
+	case !isDigits(intPart), len(intPart) > priceIntDigits,
+		hasPoint && fracPart == "", !isDigits(fracPart), len(fracPart) > priceFracDigits:
+		return "", ErrBadPrice
+	}
+
+	return intPart + "." + fracPart + strings.Repeat("0", priceFracDigits-len(fracPart)), nil
+}
+
+// requestPrice picks decimal price if set and falls back to the deprecated float one
+func requestPrice(decimal string, legacy float32) string {
+	if decimal != "" {
+		return decimal
+	}
+
+	// bitSize 32 gives the shortest decimal of float32, so 19.99 stays 19.99
+	return strconv.FormatFloat(float64(legacy), 'f', priceFracDigits, 32)
+}
+
+// priceFloat fills deprecated float fields of responses
+func priceFloat(decimal string) float32 {
+	f, _ := strconv.ParseFloat(decimal, 32)
+	return float32(f)
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,63 @@
+package storageservice
+
+import "testing"
+
+func TestNormalizePrice(t *testing.T) {
+	tests := []struct {
+		price   string
+		want    string
+		wantErr bool
+	}{
+		{"", "0.00", false}, // no price is a free book
+		{"0", "0.00", false},
+		{"680", "680.00", false},
+		{"680.0", "680.00", false},
+		{" 19.99 ", "19.99", false},
+		{".5", "0.50", false},
+		{"0007.50", "7.50", false},
+		{"00000000000001", "1.00", false},
+		{"9999999999.99", "9999999999.99", false},
+		{"10000000000", "", true}, // 11 integer digits
+		{"1.999", "", true},
+		{"1.", "", true},
+		{"1e3", "", true},
+		{"-1.00", "", true},
+		{"+1", "", true},
+		{"1,50", "", true},
+		{"abc", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizePrice(tt.price)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizePrice(%q) = %q, %v, want %q", tt.price, got, err, tt.want)
+		}
+		if tt.wantErr && err != ErrBadPrice {
+			t.Errorf("NormalizePrice(%q) error = %v, want ErrBadPrice", tt.price, err)
+		}
+	}
+}
+
+func TestRequestPrice(t *testing.T) {
+	tests := []struct {
+		decimal string
+		legacy  float32
+		want    string
+	}{
+		{"12.50", 99, "12.50"},
+		{"", 19.99, "19.99"},
+		{"", 0.1, "0.10"},
+		{"", 680, "680.00"},
+		{"", 0, "0.00"},
+	}
+
+	for _, tt := range tests {
+		if got := requestPrice(tt.decimal, tt.legacy); got != tt.want {
+			t.Errorf("requestPrice(%q, %v) = %q, want %q", tt.decimal, tt.legacy, got, tt.want)
+		}
+	}
+
+	if got := priceFloat("19.99"); got != float32(19.99) {
+		t.Errorf("priceFloat(19.99) = %v", got)
+	}
+}
@@ -0,0 +1,74 @@
+package storageservice
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// PrincipalHeader is the metadata key the gateway puts the authenticated client id in
+	PrincipalHeader = "x-principal"
+	// PrincipalScopesHeader holds space separated scopes granted to the principal
+	PrincipalScopesHeader = "x-principal-scopes"
+
+	// AdminScope is required for trash operations
+	AdminScope = "admin"
+)
+
+var (
+	ErrPermissionDenied   = errors.New("principal lacks required scope")
+	ErrAmbiguousPrincipal = errors.New("principal metadata must have a single value")
+)
+
+// principalFromContext returns the client the gateway has authenticated, empty if none.
+// The gateway sets single values, more of them did not all come from it
+func principalFromContext(ctx context.Context) (subject, scopes string, err error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	subjects, scopeValues := md.Get(PrincipalHeader), md.Get(PrincipalScopesHeader)
+	if len(subjects) > 1 || len(scopeValues) > 1 {
+		return "", "", ErrAmbiguousPrincipal
+	}
+
+	if len(subjects) == 1 {
+		subject = subjects[0]
+	}
+	if len(scopeValues) == 1 {
+		scopes = scopeValues[0]
+	}
+
+	return subject, scopes, nil
+}
+
+func setPrincipalAttributes(span trace.Span, subject, scopes string) {
+	if subject == "" {
+		return
+	}
+
+	span.SetAttributes(
+		attribute.String("enduser.id", subject),
+		attribute.String("enduser.scope", scopes),
+	)
+}
+
+// requireScope checks scopes forwarded by the gateway; calls without
+// a principal come from trusted internal clients or gateways without auth
+func requireScope(ctx context.Context, scope string) error {
+	subject, scopes, err := principalFromContext(ctx)
+	if err != nil || subject == "" {
+		return err
+	}
+
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return nil
+		}
+	}
+
+	return ErrPermissionDenied
+}
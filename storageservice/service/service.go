@@ -3,58 +3,184 @@ package storageservice
 import (
 	"context"
 	"database/sql"
+	"strings"
+	"time"
 
 	"github.com/s-vvardenfell/observer/storageservice/storagedb"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/pkg/errors"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
 	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var (
-	ErrNoSuchKey = errors.New("no value by given key stored")
+	ErrNoSuchKey  = errors.New("no value by given key stored")
+	ErrEmptyQuery = errors.New("empty search query")
+	ErrBadTraceId = errors.New("malformed x-trace-id")
+	// ErrVersionMismatch means the book was changed since the client read it
+	ErrVersionMismatch = errors.New("book version does not match expected")
+	ErrUnknownBackend  = errors.New("unknown storage backend")
+)
+
+// storage backends of StorageServiceOpts
+const (
+	BackendPostgres = "postgres"
+	BackendSQLite   = "sqlite"
+	BackendMemory   = "memory"
 )
 
 type StorageServiceOpts struct {
 	Tracer     *tracesdk.TracerProvider
 	Logger     *zerolog.Logger
 	SqlConnStr string
+	// Backend is BackendPostgres, BackendSQLite or BackendMemory, if empty
+	// it is BackendSQLite for sqlite:// SqlConnStr and BackendPostgres otherwise
+	Backend string
+	// DB configures the connection pool, waiting for the database on start
+	// and query tracing, queries are traced with Tracer unless it sets another provider
+	DB storagedb.DBOptions
+	// Repository is used instead of the one Backend asks for if set
+	Repository storagedb.BookRepository
+	// Metrics gets the connection pool metrics if set
+	Metrics prometheus.Registerer
+	// AutoMigrate migrates the schema of the sql backends up on start
+	AutoMigrate bool
+	// Seed puts the seed catalogue into an empty sql backend on start
+	Seed bool
+	// IdempotencyKeyTTL is how long AddBook results are kept for retries, DefaultIdempotencyKeyTTL if zero
+	IdempotencyKeyTTL time.Duration
+	// TrashRetention is how long deleted books can be restored, DefaultTrashRetention if zero
+	TrashRetention time.Duration
 }
 
 type StorageService struct {
-	tracer    *tracesdk.TracerProvider
-	logger    *zerolog.Logger
-	dbHandler *storagedb.StorageDbHandler
+	tracer            *tracesdk.TracerProvider
+	logger            *zerolog.Logger
+	repo              storagedb.BookRepository
+	idempotencyKeyTTL time.Duration
+	trashRetention    time.Duration
 	UnimplementedStorageServiceServer
 }
 
-func NewStorageService(opts StorageServiceOpts) (*StorageService, error) {
-	dbHdl, err := storagedb.NewStorageDbHandler(opts.SqlConnStr)
+// NewStorageService connects to the storage backend, ctx limits waiting for the database
+func NewStorageService(ctx context.Context, opts StorageServiceOpts) (*StorageService, error) {
+	backend := opts.Backend
+	if backend == "" {
+		backend = BackendPostgres
+		if storagedb.IsSQLiteURL(opts.SqlConnStr) {
+			backend = BackendSQLite
+		}
+	}
+
+	if opts.DB.TracerProvider == nil && opts.Tracer != nil {
+		opts.DB.TracerProvider = opts.Tracer
+	}
+
+	repo, err := newRepository(ctx, backend, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := prepareSchema(ctx, repo, opts); err != nil {
+		_ = repo.Close()
+		return nil, err
+	}
+
+	if pooled, ok := repo.(pooledRepository); ok && opts.Metrics != nil {
+		if err := opts.Metrics.Register(newDBStatsCollector(pooled, backend)); err != nil {
+			_ = repo.Close()
+			return nil, errors.Wrap(err, "failed to register db metrics")
+		}
+	}
+
+	if opts.IdempotencyKeyTTL <= 0 {
+		opts.IdempotencyKeyTTL = DefaultIdempotencyKeyTTL
+	}
+
+	if opts.TrashRetention <= 0 {
+		opts.TrashRetention = DefaultTrashRetention
+	}
+
 	return &StorageService{
-		tracer:    opts.Tracer,
-		logger:    opts.Logger,
-		dbHandler: dbHdl,
+		tracer:            opts.Tracer,
+		logger:            opts.Logger,
+		repo:              repo,
+		idempotencyKeyTTL: opts.IdempotencyKeyTTL,
+		trashRetention:    opts.TrashRetention,
 	}, nil
 }
 
-func (serv *StorageService) GetBookById(ctx context.Context, req *GetValueRequest) (*GetValueResponse, error) {
-	// Extract TraceID from header
-	md, ok := metadata.FromIncomingContext(ctx)
-	if ok {
-		var span trace.Span
+func newRepository(ctx context.Context, backend string, opts StorageServiceOpts) (storagedb.BookRepository, error) {
+	if opts.Repository != nil {
+		return opts.Repository, nil
+	}
+
+	switch backend {
+	case BackendPostgres:
+		return storagedb.NewStorageDbHandler(ctx, opts.SqlConnStr, opts.DB)
+	case BackendSQLite:
+		return storagedb.NewSQLiteRepository(ctx, opts.SqlConnStr, opts.DB)
+	case BackendMemory:
+		return storagedb.NewMemoryRepository(), nil
+	}
 
-		traceIdString := md["x-trace-id"][0]
+	return nil, errors.Wrap(ErrUnknownBackend, backend)
+}
+
+// migratingRepository is a BookRepository with a schema, the sql backends are
+type migratingRepository interface {
+	Migrator() (*storagedb.Migrator, error)
+}
+
+func prepareSchema(ctx context.Context, repo storagedb.BookRepository, opts StorageServiceOpts) error {
+	mr, ok := repo.(migratingRepository)
+	if !ok || (!opts.AutoMigrate && !opts.Seed) {
+		return nil
+	}
+
+	m, err := mr.Migrator()
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if opts.AutoMigrate {
+		if err := m.Up(ctx, 0); err != nil {
+			return errors.Wrap(err, "failed to migrate schema")
+		}
+	}
+
+	if opts.Seed {
+		if err := m.Seed(ctx); err != nil {
+			return errors.Wrap(err, "failed to seed")
+		}
+	}
+
+	return nil
+}
+
+// startSpan continues the trace passed by the gateway in the x-trace-id header,
+// callers end the returned span
+func (serv *StorageService) startSpan(ctx context.Context, name string) (context.Context, trace.Span, error) {
+	subject, scopes, err := principalFromContext(ctx)
+	if err != nil {
+		return ctx, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Extract TraceID from header
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md["x-trace-id"]) > 0 {
 		// Convert string to byte array
-		traceId, err := trace.TraceIDFromHex(traceIdString)
+		traceId, err := trace.TraceIDFromHex(md["x-trace-id"][0])
 		if err != nil {
-			return nil, err
+			return ctx, nil, status.Error(codes.InvalidArgument, ErrBadTraceId.Error())
 		}
 		// Creating a span context with a predefined trace-id
 		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
@@ -62,62 +188,444 @@ func (serv *StorageService) GetBookById(ctx context.Context, req *GetValueReques
 		})
 		// Embedding span config into the context
 		ctx = trace.ContextWithSpanContext(ctx, spanContext)
-
-		ctx, span = serv.tracer.Tracer("grpc-tracer").Start(ctx, "GetBookById")
-		defer span.End()
 	}
 
-	//-----------------------------------------
+	// without x-trace-id it is a child of the otelgrpc span, which its interceptor ends
+	ctx, span := serv.tracer.Tracer("grpc-tracer").Start(ctx, name)
+	setPrincipalAttributes(span, subject, scopes)
+	return ctx, span, nil
+}
 
-	data, err := serv.dbHandler.Queries.GetBookById(ctx, req.Id)
+func (serv *StorageService) GetBookById(ctx context.Context, req *GetValueRequest) (*GetValueResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "GetBookById")
 	if err != nil {
-		return nil, errors.Wrap(err, "got err from sql db")
+		return nil, err
 	}
+	defer span.End()
 
-	return &GetValueResponse{
-		Id:          data.BookID,
-		Title:       data.Title,
-		Author:      data.Author,
-		Price:       float32(data.Price.Float64),
-		Description: data.Description.String,
-		AuthorBio:   data.AuthorBio.String,
-	}, nil
+	data, err := serv.repo.GetBookById(ctx, req.Id)
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	resp := bookToResponse(data)
+	if err := attachPrices(ctx, serv.repo, resp); err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return resp, nil
 }
 
 func (serv *StorageService) AddBook(ctx context.Context, req *SetValueRequest) (*SetValueResponse, error) {
-	// Extract TraceID from header
-	md, ok := metadata.FromIncomingContext(ctx)
-	if ok {
-		var span trace.Span
+	ctx, span, err := serv.startSpan(ctx, "AddBook")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
 
-		traceIdString := md["x-trace-id"][0]
-		// Convert string to byte array
-		traceId, err := trace.TraceIDFromHex(traceIdString)
+	if err := validateBook(newBookFromRequest(req)); err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	key, err := idempotencyKeyFromContext(ctx)
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	var id int32
+	if key != "" {
+		id, err = serv.addBookIdempotent(ctx, span, key, req)
+	} else {
+		err = serv.repo.InTx(ctx, func(queries storagedb.Tx) error {
+			id, err = insertBook(ctx, queries, req)
+			return err
+		})
+	}
+
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return &SetValueResponse{Id: id}, nil
+}
+
+func (serv *StorageService) UpdateBook(ctx context.Context, req *UpdateValueRequest) (*GetValueResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "UpdateBook")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	var price *string
+	switch {
+	case req.PriceDecimal != nil:
+		price = req.PriceDecimal
+	case req.Price != nil:
+		legacy := requestPrice("", *req.Price)
+		price = &legacy
+	}
+
+	if err := validateBook(bookPatch{
+		Title:       req.Title,
+		Author:      req.Author,
+		AuthorID:    req.AuthorId,
+		Price:       price,
+		Currency:    req.Currency,
+		Prices:      req.Prices,
+		Description: req.Description,
+		AuthorBio:   req.AuthorBio,
+	}); err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	params := storagedb.UpdateBookParams{
+		BookID:      req.Id,
+		Title:       nullString(req.Title),
+		Currency:    nullString(req.Currency),
+		Description: nullString(req.Description),
+	}
+	if price != nil {
+		params.Price = nullPrice(*price)
+	}
+	if req.ExpectedVersion != nil {
+		params.ExpectedVersion = sql.NullInt32{Int32: *req.ExpectedVersion, Valid: true}
+	}
+
+	var resp *GetValueResponse
+
+	err = serv.repo.InTx(ctx, func(queries storagedb.Tx) error {
+		if req.AuthorId != nil || req.Author != nil {
+			var authorID int32
+			if req.AuthorId != nil {
+				authorID, err = resolveAuthor(ctx, queries, *req.AuthorId, "", "")
+			} else {
+				authorID, err = resolveAuthor(ctx, queries, 0, *req.Author, "")
+			}
+			if err != nil {
+				return err
+			}
+			params.AuthorID = sql.NullInt32{Int32: authorID, Valid: true}
+		}
+
+		data, err := queries.UpdateBook(ctx, params)
+		if errors.Is(err, sql.ErrNoRows) && req.ExpectedVersion != nil {
+			err = missingOrConflict(ctx, queries, req.Id)
+		}
 		if err != nil {
-			return nil, err
+			return err
 		}
-		// Creating a span context with a predefined trace-id
-		spanContext := trace.NewSpanContext(trace.SpanContextConfig{
-			TraceID: traceId,
+
+		// bio is shared by all books of the author
+		if req.AuthorBio != nil {
+			if err := queries.UpdateAuthorBio(ctx, storagedb.UpdateAuthorBioParams{
+				AuthorID: data.AuthorID,
+				Bio:      sql.NullString{String: *req.AuthorBio, Valid: true},
+			}); err != nil {
+				return err
+			}
+		}
+
+		if err := updatePrices(ctx, queries, req, data.Currency); err != nil {
+			return err
+		}
+
+		detail, err := queries.GetBookById(ctx, data.BookID)
+		if err != nil {
+			return err
+		}
+
+		resp = bookToResponse(detail)
+		return attachPrices(ctx, queries, resp)
+	})
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return resp, nil
+}
+
+func (serv *StorageService) DeleteBook(ctx context.Context, req *DeleteValueRequest) (*DeleteValueResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "DeleteBook")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	params := storagedb.DeleteBookParams{BookID: req.Id}
+	if req.ExpectedVersion != nil {
+		params.ExpectedVersion = sql.NullInt32{Int32: *req.ExpectedVersion, Valid: true}
+	}
+
+	deleted, err := serv.repo.DeleteBook(ctx, params)
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	if deleted == 0 {
+		err = ErrNoSuchKey
+		if req.ExpectedVersion != nil {
+			err = missingOrConflict(ctx, serv.repo, req.Id)
+		}
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return &DeleteValueResponse{Id: req.Id}, nil
+}
+
+func (serv *StorageService) ListBooks(ctx context.Context, req *ListValuesRequest) (*ListValuesResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "ListBooks")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	resp, err := serv.listBooks(ctx, req)
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return resp, nil
+}
+
+func (serv *StorageService) listBooks(ctx context.Context, req *ListValuesRequest) (*ListValuesResponse, error) {
+	sortBy := sortColumn(req.SortBy)
+	limit := pageLimit(req.PageSize)
+
+	params := storagedb.ListBooksParams{
+		SortBy:    sortBy,
+		SortDesc:  req.Descending,
+		PageLimit: limit + 1, // one extra row tells whether there is a next page
+	}
+
+	if err := applyFilter(&params, req.Filter); err != nil {
+		return nil, err
+	}
+
+	filter := filterHash(params)
+
+	cur, err := decodeCursor(req.Cursor, sortBy, req.Descending, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if cur != nil {
+		params.AfterID = sql.NullInt32{Int32: cur.Id, Valid: true}
+		params.AfterTitle = cur.Title
+		params.AfterPrice = sql.NullString{String: cur.Price, Valid: cur.Price != ""}
+	}
+
+	data, err := serv.repo.ListBooks(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ListValuesResponse{}
+
+	if len(data) > int(limit) {
+		data = data[:limit]
+		resp.NextCursor = encodeCursor(sortBy, req.Descending, filter, data[len(data)-1])
+	}
+
+	for _, book := range data {
+		resp.Books = append(resp.Books, bookToResponse(book))
+	}
+
+	if err := attachPrices(ctx, serv.repo, resp.Books...); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (serv *StorageService) SearchBooks(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "SearchBooks")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	span.SetAttributes(attribute.String("search.query", req.Query))
+
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, serv.statusError(ctx, span, ErrEmptyQuery)
+	}
+
+	start := time.Now()
+
+	data, err := serv.repo.SearchBooks(ctx, storagedb.SearchBooksParams{
+		Query:       req.Query,
+		ResultLimit: pageLimit(req.Limit),
+	})
+
+	span.SetAttributes(attribute.Int64("db.query_time_ms", time.Since(start).Milliseconds()))
+
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	span.SetAttributes(attribute.Int("search.hits", len(data)))
+
+	resp := &SearchResponse{}
+
+	for _, row := range data {
+		resp.Hits = append(resp.Hits, &SearchHit{
+			Book: &GetValueResponse{
+				Id:           row.BookID,
+				Title:        row.Title,
+				AuthorId:     row.AuthorID,
+				Author:       row.Author,
+				Price:        priceFloat(responsePrice(row.Price)),
+				PriceDecimal: responsePrice(row.Price),
+				Currency:     row.Currency,
+				Description:  row.Description.String,
+				AuthorBio:    row.AuthorBio.String,
+			},
+			Rank:           row.Rank,
+			TitleHighlight: row.TitleHighlight,
+			Snippet:        row.Snippet,
 		})
-		// Embedding span config into the context
-		ctx = trace.ContextWithSpanContext(ctx, spanContext)
+	}
+
+	books := make([]*GetValueResponse, 0, len(resp.Hits))
+	for _, hit := range resp.Hits {
+		books = append(books, hit.Book)
+	}
+
+	if err := attachPrices(ctx, serv.repo, books...); err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return resp, nil
+}
+
+// insertBook adds book with its native prices, prices in the base currency
+// are ignored as the base price already covers it
+func insertBook(ctx context.Context, queries storagedb.Querier, req *SetValueRequest) (int32, error) {
+	authorID, err := resolveAuthor(ctx, queries, req.AuthorId, req.Author, req.AuthorBio)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := queries.InsertBook(ctx, insertBookParams(req, authorID))
+	if err != nil {
+		return 0, err
+	}
 
-		ctx, span = serv.tracer.Tracer("grpc-tracer").Start(ctx, "AddBook")
-		defer span.End()
+	base := requestCurrency(req.Currency)
+	for currency, amount := range req.Prices {
+		if currency == base {
+			continue
+		}
+
+		if err := queries.UpsertBookPrice(ctx, storagedb.UpsertBookPriceParams{
+			BookID:   id,
+			Currency: currency,
+			Amount:   nullPrice(amount).String,
+		}); err != nil {
+			return 0, err
+		}
 	}
-	//-----------------------------------------
 
-	id, err := serv.dbHandler.Queries.InsertBook(ctx, storagedb.InsertBookParams{
+	return id, nil
+}
+
+func insertBookParams(req *SetValueRequest, authorID int32) storagedb.InsertBookParams {
+	return storagedb.InsertBookParams{
 		Title:       req.Title,
-		Author:      req.Author,
-		Price:       sql.NullFloat64{Float64: float64(req.Price), Valid: true},
+		AuthorID:    authorID,
+		Price:       nullPrice(requestPrice(req.PriceDecimal, req.Price)),
+		Currency:    requestCurrency(req.Currency),
 		Description: sql.NullString{String: req.Description, Valid: true},
-		AuthorBio:   sql.NullString{String: req.AuthorBio, Valid: true},
-	})
+	}
+}
+
+func bookToResponse(data storagedb.BookDetail) *GetValueResponse {
+	return &GetValueResponse{
+		Id:           data.BookID,
+		Title:        data.Title,
+		AuthorId:     data.AuthorID,
+		Author:       data.Author,
+		Price:        priceFloat(responsePrice(data.Price)),
+		PriceDecimal: responsePrice(data.Price),
+		Currency:     data.Currency,
+		Description:  data.Description.String,
+		AuthorBio:    data.AuthorBio.String,
+		Version:      data.Version,
+	}
+}
+
+// missingOrConflict tells why a versioned write matched no rows
+func missingOrConflict(ctx context.Context, queries storagedb.Querier, id int32) error {
+	_, err := queries.GetBookById(ctx, id)
 	if err != nil {
-		return nil, errors.Wrap(err, "got err from sql db")
+		return err
 	}
 
-	return &SetValueResponse{Id: id}, nil
+	return ErrVersionMismatch
+}
+
+// applyFilter sets ListBooks filter params, nil filter matches all books
+func applyFilter(params *storagedb.ListBooksParams, f *BookFilter) error {
+	if f == nil {
+		return nil
+	}
+
+	params.Author = nullString(f.Author)
+	if f.AuthorId != nil {
+		params.AuthorID = sql.NullInt32{Int32: *f.AuthorId, Valid: true}
+	}
+	if f.TitleContains != nil {
+		params.TitlePattern = sql.NullString{String: likeContains(*f.TitleContains), Valid: true}
+	}
+
+	for _, bound := range []struct {
+		decimal *string
+		legacy  *float32
+		dst     *sql.NullString
+	}{
+		{f.MinPriceDecimal, f.MinPrice, &params.MinPrice},
+		{f.MaxPriceDecimal, f.MaxPrice, &params.MaxPrice},
+	} {
+		var price string
+		switch {
+		case bound.decimal != nil:
+			price = *bound.decimal
+		case bound.legacy != nil:
+			price = requestPrice("", *bound.legacy)
+		default:
+			continue
+		}
+
+		normalized, err := NormalizePrice(price)
+		if err != nil {
+			return err
+		}
+		*bound.dst = sql.NullString{String: normalized, Valid: true}
+	}
+
+	return nil
+}
+
+// nullPrice converts request price checked by validateBook to column value
+func nullPrice(price string) sql.NullString {
+	normalized, err := NormalizePrice(price)
+	return sql.NullString{String: normalized, Valid: err == nil}
+}
+
+// responsePrice is the decimal price of a row, books without price cost 0.00
+// as they did when price was FLOAT
+func responsePrice(price sql.NullString) string {
+	if !price.Valid {
+		return "0.00"
+	}
+
+	return price.String
+}
+
+func nullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: *s, Valid: true}
 }
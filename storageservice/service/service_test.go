@@ -0,0 +1,150 @@
+package storageservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newMemoryStorageService(t *testing.T) *StorageService {
+	t.Helper()
+
+	logger := zerolog.Nop()
+
+	serv, err := NewStorageService(context.Background(), StorageServiceOpts{
+		Tracer:  tracesdk.NewTracerProvider(),
+		Logger:  &logger,
+		Backend: BackendMemory,
+	})
+	if err != nil {
+		t.Fatalf("NewStorageService() error = %v", err)
+	}
+
+	return serv
+}
+
+func TestStorageServiceBookLifecycle(t *testing.T) {
+	ctx := context.Background()
+	serv := newMemoryStorageService(t)
+
+	added, err := serv.AddBook(ctx, &SetValueRequest{
+		Title:        "Book",
+		Author:       "Author",
+		PriceDecimal: "12.5",
+		Currency:     "EUR",
+		Prices:       map[string]string{"USD": "13.99"},
+	})
+	if err != nil {
+		t.Fatalf("AddBook() error = %v", err)
+	}
+
+	book, err := serv.GetBookById(ctx, &GetValueRequest{Id: added.Id})
+	if err != nil {
+		t.Fatalf("GetBookById() error = %v", err)
+	}
+	if book.PriceDecimal != "12.50" || book.Prices["USD"] != "13.99" || book.Author != "Author" {
+		t.Fatalf("GetBookById() = %v", book)
+	}
+
+	if _, err := serv.DeleteBook(ctx, &DeleteValueRequest{Id: added.Id}); err != nil {
+		t.Fatalf("DeleteBook() error = %v", err)
+	}
+
+	_, err = serv.GetBookById(ctx, &GetValueRequest{Id: added.Id})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("GetBookById() of deleted book error = %v, want NotFound", err)
+	}
+
+	restored, err := serv.RestoreBook(ctx, &RestoreBookRequest{Id: added.Id})
+	if err != nil {
+		t.Fatalf("RestoreBook() error = %v", err)
+	}
+	if restored.Version != book.Version+2 {
+		t.Fatalf("RestoreBook() version = %d, want %d", restored.Version, book.Version+2)
+	}
+
+	expected := book.Version
+	_, err = serv.UpdateBook(ctx, &UpdateValueRequest{Id: added.Id, ExpectedVersion: &expected})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("UpdateBook() with stale version error = %v, want FailedPrecondition", err)
+	}
+}
+
+func TestStorageServiceIdempotencyKeyPerPrincipal(t *testing.T) {
+	serv := newMemoryStorageService(t)
+
+	addBook := func(principal string) int32 {
+		t.Helper()
+
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+			IdempotencyKeyHeader, "key",
+			PrincipalHeader, principal,
+			PrincipalScopesHeader, "books:write",
+		))
+
+		resp, err := serv.AddBook(ctx, &SetValueRequest{Title: "Book", Author: "Author", PriceDecimal: "1.00"})
+		if err != nil {
+			t.Fatalf("AddBook() as %s error = %v", principal, err)
+		}
+
+		return resp.Id
+	}
+
+	first := addBook("alice")
+	if replayed := addBook("alice"); replayed != first {
+		t.Fatalf("AddBook() retried by alice = %d, want replayed %d", replayed, first)
+	}
+	if other := addBook("bob"); other == first {
+		t.Fatalf("AddBook() with the key of alice by bob replayed %d", first)
+	}
+}
+
+func TestStorageServiceRejectsAmbiguousPrincipal(t *testing.T) {
+	serv := newMemoryStorageService(t)
+
+	for _, md := range []metadata.MD{
+		metadata.Pairs(PrincipalHeader, "someone-else", PrincipalHeader, "alice"),
+		metadata.Pairs(PrincipalHeader, "alice", PrincipalScopesHeader, AdminScope, PrincipalScopesHeader, "books:read"),
+	} {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+
+		if _, err := serv.ListDeletedBooks(ctx, &ListDeletedBooksRequest{}); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("ListDeletedBooks() with %v error = %v, want InvalidArgument", md, err)
+		}
+
+		_, err := serv.AddBook(metadata.NewIncomingContext(ctx, metadata.Join(md, metadata.Pairs(IdempotencyKeyHeader, "key"))),
+			&SetValueRequest{Title: "Book", Author: "Author", PriceDecimal: "1.00"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("AddBook() with %v error = %v, want InvalidArgument", md, err)
+		}
+	}
+}
+
+func TestStorageServiceSpanWithoutTraceId(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	serv := newMemoryStorageService(t)
+	serv.tracer = tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))
+
+	// the span otelgrpc starts for the call
+	ctx, rpcSpan := serv.tracer.Tracer("otelgrpc").Start(context.Background(), "StorageService/GetBookById")
+
+	if _, err := serv.GetBookById(ctx, &GetValueRequest{Id: 1}); status.Code(err) != codes.NotFound {
+		t.Fatalf("GetBookById() error = %v, want NotFound", err)
+	}
+
+	ended := recorder.Ended()
+	if len(ended) != 1 || ended[0].Name() != "GetBookById" {
+		t.Fatalf("ended spans = %v, want GetBookById only", ended)
+	}
+	if got, want := ended[0].Parent().SpanID(), rpcSpan.SpanContext().SpanID(); got != want {
+		t.Errorf("parent of GetBookById = %s, want the rpc span %s", got, want)
+	}
+
+	rpcSpan.End()
+}
@@ -19,8 +19,19 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	StorageService_GetBookById_FullMethodName = "/storageservice.StorageService/GetBookById"
-	StorageService_AddBook_FullMethodName     = "/storageservice.StorageService/AddBook"
+	StorageService_GetBookById_FullMethodName      = "/storageservice.StorageService/GetBookById"
+	StorageService_AddBook_FullMethodName          = "/storageservice.StorageService/AddBook"
+	StorageService_UpdateBook_FullMethodName       = "/storageservice.StorageService/UpdateBook"
+	StorageService_DeleteBook_FullMethodName       = "/storageservice.StorageService/DeleteBook"
+	StorageService_ListBooks_FullMethodName        = "/storageservice.StorageService/ListBooks"
+	StorageService_SearchBooks_FullMethodName      = "/storageservice.StorageService/SearchBooks"
+	StorageService_ImportBooks_FullMethodName      = "/storageservice.StorageService/ImportBooks"
+	StorageService_ExportBooks_FullMethodName      = "/storageservice.StorageService/ExportBooks"
+	StorageService_RestoreBook_FullMethodName      = "/storageservice.StorageService/RestoreBook"
+	StorageService_ListDeletedBooks_FullMethodName = "/storageservice.StorageService/ListDeletedBooks"
+	StorageService_GetAuthor_FullMethodName        = "/storageservice.StorageService/GetAuthor"
+	StorageService_ListAuthors_FullMethodName      = "/storageservice.StorageService/ListAuthors"
+	StorageService_ListAuthorBooks_FullMethodName  = "/storageservice.StorageService/ListAuthorBooks"
 )
 
 // StorageServiceClient is the client API for StorageService service.
@@ -29,6 +40,20 @@ const (
 type StorageServiceClient interface {
 	GetBookById(ctx context.Context, in *GetValueRequest, opts ...grpc.CallOption) (*GetValueResponse, error)
 	AddBook(ctx context.Context, in *SetValueRequest, opts ...grpc.CallOption) (*SetValueResponse, error)
+	UpdateBook(ctx context.Context, in *UpdateValueRequest, opts ...grpc.CallOption) (*GetValueResponse, error)
+	DeleteBook(ctx context.Context, in *DeleteValueRequest, opts ...grpc.CallOption) (*DeleteValueResponse, error)
+	ListBooks(ctx context.Context, in *ListValuesRequest, opts ...grpc.CallOption) (*ListValuesResponse, error)
+	SearchBooks(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	ImportBooks(ctx context.Context, opts ...grpc.CallOption) (StorageService_ImportBooksClient, error)
+	ExportBooks(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (StorageService_ExportBooksClient, error)
+	// restores a book deleted by DeleteBook until it is purged
+	RestoreBook(ctx context.Context, in *RestoreBookRequest, opts ...grpc.CallOption) (*GetValueResponse, error)
+	// trash is for admins only and is not exposed by transcoding,
+	// which does not tell admins from other writers
+	ListDeletedBooks(ctx context.Context, in *ListDeletedBooksRequest, opts ...grpc.CallOption) (*ListDeletedBooksResponse, error)
+	GetAuthor(ctx context.Context, in *GetAuthorRequest, opts ...grpc.CallOption) (*Author, error)
+	ListAuthors(ctx context.Context, in *ListAuthorsRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error)
+	ListAuthorBooks(ctx context.Context, in *ListAuthorBooksRequest, opts ...grpc.CallOption) (*ListValuesResponse, error)
 }
 
 type storageServiceClient struct {
@@ -57,12 +82,173 @@ func (c *storageServiceClient) AddBook(ctx context.Context, in *SetValueRequest,
 	return out, nil
 }
 
+func (c *storageServiceClient) UpdateBook(ctx context.Context, in *UpdateValueRequest, opts ...grpc.CallOption) (*GetValueResponse, error) {
+	out := new(GetValueResponse)
+	err := c.cc.Invoke(ctx, StorageService_UpdateBook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) DeleteBook(ctx context.Context, in *DeleteValueRequest, opts ...grpc.CallOption) (*DeleteValueResponse, error) {
+	out := new(DeleteValueResponse)
+	err := c.cc.Invoke(ctx, StorageService_DeleteBook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) ListBooks(ctx context.Context, in *ListValuesRequest, opts ...grpc.CallOption) (*ListValuesResponse, error) {
+	out := new(ListValuesResponse)
+	err := c.cc.Invoke(ctx, StorageService_ListBooks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) SearchBooks(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, StorageService_SearchBooks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) ImportBooks(ctx context.Context, opts ...grpc.CallOption) (StorageService_ImportBooksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[0], StorageService_ImportBooks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageServiceImportBooksClient{stream}
+	return x, nil
+}
+
+type StorageService_ImportBooksClient interface {
+	Send(*ImportBookRow) error
+	CloseAndRecv() (*ImportResponse, error)
+	grpc.ClientStream
+}
+
+type storageServiceImportBooksClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageServiceImportBooksClient) Send(m *ImportBookRow) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *storageServiceImportBooksClient) CloseAndRecv() (*ImportResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(ImportResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageServiceClient) ExportBooks(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (StorageService_ExportBooksClient, error) {
+	stream, err := c.cc.NewStream(ctx, &StorageService_ServiceDesc.Streams[1], StorageService_ExportBooks_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &storageServiceExportBooksClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type StorageService_ExportBooksClient interface {
+	Recv() (*GetValueResponse, error)
+	grpc.ClientStream
+}
+
+type storageServiceExportBooksClient struct {
+	grpc.ClientStream
+}
+
+func (x *storageServiceExportBooksClient) Recv() (*GetValueResponse, error) {
+	m := new(GetValueResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *storageServiceClient) RestoreBook(ctx context.Context, in *RestoreBookRequest, opts ...grpc.CallOption) (*GetValueResponse, error) {
+	out := new(GetValueResponse)
+	err := c.cc.Invoke(ctx, StorageService_RestoreBook_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) ListDeletedBooks(ctx context.Context, in *ListDeletedBooksRequest, opts ...grpc.CallOption) (*ListDeletedBooksResponse, error) {
+	out := new(ListDeletedBooksResponse)
+	err := c.cc.Invoke(ctx, StorageService_ListDeletedBooks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) GetAuthor(ctx context.Context, in *GetAuthorRequest, opts ...grpc.CallOption) (*Author, error) {
+	out := new(Author)
+	err := c.cc.Invoke(ctx, StorageService_GetAuthor_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) ListAuthors(ctx context.Context, in *ListAuthorsRequest, opts ...grpc.CallOption) (*ListAuthorsResponse, error) {
+	out := new(ListAuthorsResponse)
+	err := c.cc.Invoke(ctx, StorageService_ListAuthors_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *storageServiceClient) ListAuthorBooks(ctx context.Context, in *ListAuthorBooksRequest, opts ...grpc.CallOption) (*ListValuesResponse, error) {
+	out := new(ListValuesResponse)
+	err := c.cc.Invoke(ctx, StorageService_ListAuthorBooks_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // StorageServiceServer is the server API for StorageService service.
 // All implementations must embed UnimplementedStorageServiceServer
 // for forward compatibility
 type StorageServiceServer interface {
 	GetBookById(context.Context, *GetValueRequest) (*GetValueResponse, error)
 	AddBook(context.Context, *SetValueRequest) (*SetValueResponse, error)
+	UpdateBook(context.Context, *UpdateValueRequest) (*GetValueResponse, error)
+	DeleteBook(context.Context, *DeleteValueRequest) (*DeleteValueResponse, error)
+	ListBooks(context.Context, *ListValuesRequest) (*ListValuesResponse, error)
+	SearchBooks(context.Context, *SearchRequest) (*SearchResponse, error)
+	ImportBooks(StorageService_ImportBooksServer) error
+	ExportBooks(*ExportRequest, StorageService_ExportBooksServer) error
+	// restores a book deleted by DeleteBook until it is purged
+	RestoreBook(context.Context, *RestoreBookRequest) (*GetValueResponse, error)
+	// trash is for admins only and is not exposed by transcoding,
+	// which does not tell admins from other writers
+	ListDeletedBooks(context.Context, *ListDeletedBooksRequest) (*ListDeletedBooksResponse, error)
+	GetAuthor(context.Context, *GetAuthorRequest) (*Author, error)
+	ListAuthors(context.Context, *ListAuthorsRequest) (*ListAuthorsResponse, error)
+	ListAuthorBooks(context.Context, *ListAuthorBooksRequest) (*ListValuesResponse, error)
 	mustEmbedUnimplementedStorageServiceServer()
 }
 
@@ -76,6 +262,39 @@ func (UnimplementedStorageServiceServer) GetBookById(context.Context, *GetValueR
 func (UnimplementedStorageServiceServer) AddBook(context.Context, *SetValueRequest) (*SetValueResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddBook not implemented")
 }
+func (UnimplementedStorageServiceServer) UpdateBook(context.Context, *UpdateValueRequest) (*GetValueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateBook not implemented")
+}
+func (UnimplementedStorageServiceServer) DeleteBook(context.Context, *DeleteValueRequest) (*DeleteValueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteBook not implemented")
+}
+func (UnimplementedStorageServiceServer) ListBooks(context.Context, *ListValuesRequest) (*ListValuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBooks not implemented")
+}
+func (UnimplementedStorageServiceServer) SearchBooks(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchBooks not implemented")
+}
+func (UnimplementedStorageServiceServer) ImportBooks(StorageService_ImportBooksServer) error {
+	return status.Errorf(codes.Unimplemented, "method ImportBooks not implemented")
+}
+func (UnimplementedStorageServiceServer) ExportBooks(*ExportRequest, StorageService_ExportBooksServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportBooks not implemented")
+}
+func (UnimplementedStorageServiceServer) RestoreBook(context.Context, *RestoreBookRequest) (*GetValueResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreBook not implemented")
+}
+func (UnimplementedStorageServiceServer) ListDeletedBooks(context.Context, *ListDeletedBooksRequest) (*ListDeletedBooksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDeletedBooks not implemented")
+}
+func (UnimplementedStorageServiceServer) GetAuthor(context.Context, *GetAuthorRequest) (*Author, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAuthor not implemented")
+}
+func (UnimplementedStorageServiceServer) ListAuthors(context.Context, *ListAuthorsRequest) (*ListAuthorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuthors not implemented")
+}
+func (UnimplementedStorageServiceServer) ListAuthorBooks(context.Context, *ListAuthorBooksRequest) (*ListValuesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuthorBooks not implemented")
+}
 func (UnimplementedStorageServiceServer) mustEmbedUnimplementedStorageServiceServer() {}
 
 // UnsafeStorageServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -125,6 +344,215 @@ func _StorageService_AddBook_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _StorageService_UpdateBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).UpdateBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_UpdateBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).UpdateBook(ctx, req.(*UpdateValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_DeleteBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).DeleteBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_DeleteBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).DeleteBook(ctx, req.(*DeleteValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_ListBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListValuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).ListBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_ListBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).ListBooks(ctx, req.(*ListValuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_SearchBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).SearchBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_SearchBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).SearchBooks(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_ImportBooks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(StorageServiceServer).ImportBooks(&storageServiceImportBooksServer{stream})
+}
+
+type StorageService_ImportBooksServer interface {
+	SendAndClose(*ImportResponse) error
+	Recv() (*ImportBookRow, error)
+	grpc.ServerStream
+}
+
+type storageServiceImportBooksServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageServiceImportBooksServer) SendAndClose(m *ImportResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *storageServiceImportBooksServer) Recv() (*ImportBookRow, error) {
+	m := new(ImportBookRow)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _StorageService_ExportBooks_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StorageServiceServer).ExportBooks(m, &storageServiceExportBooksServer{stream})
+}
+
+type StorageService_ExportBooksServer interface {
+	Send(*GetValueResponse) error
+	grpc.ServerStream
+}
+
+type storageServiceExportBooksServer struct {
+	grpc.ServerStream
+}
+
+func (x *storageServiceExportBooksServer) Send(m *GetValueResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _StorageService_RestoreBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).RestoreBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_RestoreBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).RestoreBook(ctx, req.(*RestoreBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_ListDeletedBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDeletedBooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).ListDeletedBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_ListDeletedBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).ListDeletedBooks(ctx, req.(*ListDeletedBooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_GetAuthor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAuthorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).GetAuthor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_GetAuthor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).GetAuthor(ctx, req.(*GetAuthorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_ListAuthors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuthorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).ListAuthors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_ListAuthors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).ListAuthors(ctx, req.(*ListAuthorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _StorageService_ListAuthorBooks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuthorBooksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StorageServiceServer).ListAuthorBooks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: StorageService_ListAuthorBooks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StorageServiceServer).ListAuthorBooks(ctx, req.(*ListAuthorBooksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // StorageService_ServiceDesc is the grpc.ServiceDesc for StorageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -140,7 +568,54 @@ var StorageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AddBook",
 			Handler:    _StorageService_AddBook_Handler,
 		},
+		{
+			MethodName: "UpdateBook",
+			Handler:    _StorageService_UpdateBook_Handler,
+		},
+		{
+			MethodName: "DeleteBook",
+			Handler:    _StorageService_DeleteBook_Handler,
+		},
+		{
+			MethodName: "ListBooks",
+			Handler:    _StorageService_ListBooks_Handler,
+		},
+		{
+			MethodName: "SearchBooks",
+			Handler:    _StorageService_SearchBooks_Handler,
+		},
+		{
+			MethodName: "RestoreBook",
+			Handler:    _StorageService_RestoreBook_Handler,
+		},
+		{
+			MethodName: "ListDeletedBooks",
+			Handler:    _StorageService_ListDeletedBooks_Handler,
+		},
+		{
+			MethodName: "GetAuthor",
+			Handler:    _StorageService_GetAuthor_Handler,
+		},
+		{
+			MethodName: "ListAuthors",
+			Handler:    _StorageService_ListAuthors_Handler,
+		},
+		{
+			MethodName: "ListAuthorBooks",
+			Handler:    _StorageService_ListAuthorBooks_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportBooks",
+			Handler:       _StorageService_ImportBooks_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportBooks",
+			Handler:       _StorageService_ExportBooks_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "storageservice.proto",
 }
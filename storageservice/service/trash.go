@@ -0,0 +1,144 @@
+package storageservice
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/s-vvardenfell/observer/storageservice/storagedb"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultTrashRetention is how long deleted books can be restored before they are purged
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+var ErrNotInTrash = errors.New("no deleted book with given id")
+
+func (serv *StorageService) RestoreBook(ctx context.Context, req *RestoreBookRequest) (*GetValueResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "RestoreBook")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("book.id", int(req.Id)))
+
+	var resp *GetValueResponse
+
+	err = serv.repo.InTx(ctx, func(queries storagedb.Tx) error {
+		restored, err := queries.RestoreBook(ctx, req.Id)
+		if err != nil {
+			return err
+		}
+		if restored == 0 {
+			return ErrNotInTrash
+		}
+
+		data, err := queries.GetBookById(ctx, req.Id)
+		if err != nil {
+			return err
+		}
+
+		resp = bookToResponse(data)
+		return attachPrices(ctx, queries, resp)
+	})
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	return resp, nil
+}
+
+func (serv *StorageService) ListDeletedBooks(ctx context.Context, req *ListDeletedBooksRequest) (*ListDeletedBooksResponse, error) {
+	ctx, span, err := serv.startSpan(ctx, "ListDeletedBooks")
+	if err != nil {
+		return nil, err
+	}
+	defer span.End()
+
+	if err := requireScope(ctx, AdminScope); err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	cur, err := decodeCursor(req.Cursor, trashSortKey, false, "")
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	limit := pageLimit(req.PageSize)
+
+	params := storagedb.ListDeletedBooksParams{PageLimit: limit + 1}
+	if cur != nil {
+		params.AfterID = sql.NullInt32{Int32: cur.Id, Valid: true}
+	}
+
+	data, err := serv.repo.ListDeletedBooks(ctx, params)
+	if err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	resp := &ListDeletedBooksResponse{}
+
+	if len(data) > int(limit) {
+		data = data[:limit]
+		resp.NextCursor = encodeIdCursor(trashSortKey, data[len(data)-1].BookID)
+	}
+
+	books := make([]*GetValueResponse, 0, len(data))
+	for _, row := range data {
+		book := bookToResponse(row)
+		books = append(books, book)
+		resp.Books = append(resp.Books, &DeletedBook{
+			Book:      book,
+			DeletedAt: timestamppb.New(row.DeletedAt.Time),
+		})
+	}
+
+	if err := attachPrices(ctx, serv.repo, books...); err != nil {
+		return nil, serv.statusError(ctx, span, err)
+	}
+
+	span.SetAttributes(attribute.Int("trash.books", len(books)))
+
+	return resp, nil
+}
+
+// PurgeDeletedBooks removes books deleted longer than trash retention ago every interval until ctx is done
+func (serv *StorageService) PurgeDeletedBooks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		serv.purgeDeletedBooks(ctx)
+	}
+}
+
+func (serv *StorageService) purgeDeletedBooks(ctx context.Context) {
+	ctx, span := serv.tracer.Tracer("grpc-tracer").Start(ctx, "PurgeDeletedBooks")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("trash.retention", serv.trashRetention.String()))
+
+	n, err := serv.repo.PurgeDeletedBooks(ctx, int32(serv.trashRetention/time.Second))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, "purge failed")
+		serv.logger.Error().Err(err).Msg("failed to purge deleted books")
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("trash.purged", n))
+
+	if n > 0 {
+		serv.logger.Info().Int64("purged", n).Msg("deleted books purged")
+	}
+}
@@ -0,0 +1,186 @@
+package storageservice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func principalContext(subject, scopes string) context.Context {
+	return metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(PrincipalHeader, subject, PrincipalScopesHeader, scopes))
+}
+
+func addTrashBooks(t *testing.T, serv *StorageService, titles ...string) []int32 {
+	t.Helper()
+
+	var ids []int32
+	for _, title := range titles {
+		resp, err := serv.AddBook(context.Background(), &SetValueRequest{Title: title, Author: "Author", PriceDecimal: "10"})
+		if err != nil {
+			t.Fatalf("AddBook(%q) error = %v", title, err)
+		}
+		ids = append(ids, resp.Id)
+	}
+
+	return ids
+}
+
+func listedIds(t *testing.T, serv *StorageService) []int32 {
+	t.Helper()
+
+	resp, err := serv.ListBooks(context.Background(), &ListValuesRequest{})
+	if err != nil {
+		t.Fatalf("ListBooks() error = %v", err)
+	}
+
+	var ids []int32
+	for _, book := range resp.Books {
+		ids = append(ids, book.Id)
+	}
+
+	return ids
+}
+
+func trashIds(t *testing.T, serv *StorageService) []int32 {
+	t.Helper()
+
+	resp, err := serv.ListDeletedBooks(principalContext("admin-user", AdminScope), &ListDeletedBooksRequest{})
+	if err != nil {
+		t.Fatalf("ListDeletedBooks() error = %v", err)
+	}
+
+	var ids []int32
+	for _, deleted := range resp.Books {
+		if deleted.DeletedAt == nil || deleted.DeletedAt.AsTime().IsZero() {
+			t.Fatalf("ListDeletedBooks() book %d has no deletion time", deleted.Book.Id)
+		}
+		ids = append(ids, deleted.Book.Id)
+	}
+
+	return ids
+}
+
+func TestTrashDeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	serv := newMemoryStorageService(t)
+
+	ids := addTrashBooks(t, serv, "Kept", "Trashed")
+	kept, trashed := ids[0], ids[1]
+
+	before, err := serv.GetBookById(ctx, &GetValueRequest{Id: trashed})
+	if err != nil {
+		t.Fatalf("GetBookById() error = %v", err)
+	}
+
+	if _, err := serv.DeleteBook(ctx, &DeleteValueRequest{Id: trashed}); err != nil {
+		t.Fatalf("DeleteBook() error = %v", err)
+	}
+
+	// deleted book is gone for everything but the trash
+	if _, err := serv.GetBookById(ctx, &GetValueRequest{Id: trashed}); status.Code(err) != codes.NotFound {
+		t.Fatalf("GetBookById() of deleted book error = %v, want NotFound", err)
+	}
+	if got := listedIds(t, serv); !equalIds(got, []int32{kept}) {
+		t.Fatalf("ListBooks() ids = %v, want %v", got, []int32{kept})
+	}
+	found, err := serv.SearchBooks(ctx, &SearchRequest{Query: "trashed"})
+	if err != nil || len(found.Hits) != 0 {
+		t.Fatalf("SearchBooks() = %v, %v, want no results", found, err)
+	}
+	if got := trashIds(t, serv); !equalIds(got, []int32{trashed}) {
+		t.Fatalf("ListDeletedBooks() ids = %v, want %v", got, []int32{trashed})
+	}
+
+	// deleting again does not find the book
+	if _, err := serv.DeleteBook(ctx, &DeleteValueRequest{Id: trashed}); status.Code(err) != codes.NotFound {
+		t.Fatalf("DeleteBook() of deleted book error = %v, want NotFound", err)
+	}
+
+	restored, err := serv.RestoreBook(ctx, &RestoreBookRequest{Id: trashed})
+	if err != nil {
+		t.Fatalf("RestoreBook() error = %v", err)
+	}
+	// delete and restore are both changes clients holding the old version must see
+	if restored.Version != before.Version+2 || restored.Title != "Trashed" || restored.PriceDecimal != "10.00" {
+		t.Fatalf("RestoreBook() = %v, want the book at version %d", restored, before.Version+2)
+	}
+
+	if got := listedIds(t, serv); !equalIds(got, []int32{kept, trashed}) {
+		t.Fatalf("ListBooks() ids after restore = %v, want %v", got, []int32{kept, trashed})
+	}
+	if got := trashIds(t, serv); len(got) != 0 {
+		t.Fatalf("ListDeletedBooks() ids after restore = %v, want none", got)
+	}
+
+	// books not in the trash cannot be restored
+	for _, id := range []int32{kept, trashed, trashed + 100} {
+		if _, err := serv.RestoreBook(ctx, &RestoreBookRequest{Id: id}); status.Code(err) != codes.NotFound {
+			t.Fatalf("RestoreBook(%d) error = %v, want NotFound", id, err)
+		}
+	}
+}
+
+func TestPurgeDeletedBooksRetention(t *testing.T) {
+	ctx := context.Background()
+	serv := newMemoryStorageService(t)
+
+	ids := addTrashBooks(t, serv, "Kept", "Trashed")
+	kept, trashed := ids[0], ids[1]
+
+	if _, err := serv.DeleteBook(ctx, &DeleteValueRequest{Id: trashed}); err != nil {
+		t.Fatalf("DeleteBook() error = %v", err)
+	}
+
+	// the book has been deleted less than DefaultTrashRetention ago
+	serv.purgeDeletedBooks(ctx)
+
+	if got := trashIds(t, serv); !equalIds(got, []int32{trashed}) {
+		t.Fatalf("ListDeletedBooks() ids after purge within retention = %v, want %v", got, []int32{trashed})
+	}
+
+	serv.trashRetention = time.Second
+	time.Sleep(1100 * time.Millisecond)
+
+	serv.purgeDeletedBooks(ctx)
+
+	if got := trashIds(t, serv); len(got) != 0 {
+		t.Fatalf("ListDeletedBooks() ids after retention = %v, want none", got)
+	}
+	if _, err := serv.RestoreBook(ctx, &RestoreBookRequest{Id: trashed}); status.Code(err) != codes.NotFound {
+		t.Fatalf("RestoreBook() of purged book error = %v, want NotFound", err)
+	}
+	if got := listedIds(t, serv); !equalIds(got, []int32{kept}) {
+		t.Fatalf("ListBooks() ids after purge = %v, want %v", got, []int32{kept})
+	}
+}
+
+func TestListDeletedBooksRequiresAdmin(t *testing.T) {
+	serv := newMemoryStorageService(t)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{"admin", principalContext("alice", "books:read "+AdminScope), codes.OK},
+		{"read and write scopes", principalContext("bob", "books:read books:write"), codes.PermissionDenied},
+		{"no scopes", principalContext("carol", ""), codes.PermissionDenied},
+		{"scope prefix", principalContext("dave", "administrator"), codes.PermissionDenied},
+		// internal clients call storage without a principal
+		{"no principal", context.Background(), codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := serv.ListDeletedBooks(tt.ctx, &ListDeletedBooksRequest{})
+			if status.Code(err) != tt.want {
+				t.Fatalf("ListDeletedBooks() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package storageservice
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// limits follow column sizes in migrations/001_migrate.up.sql
+type newBook struct {
+	Title       string            `json:"title" validate:"required,max=100"`
+	AuthorID    int32             `json:"author_id"`
+	Author      string            `json:"author" validate:"required_without=AuthorID,max=100"`
+	Price       string            `json:"price" validate:"price"`
+	Currency    string            `json:"currency" validate:"omitempty,iso4217"`
+	Prices      map[string]string `json:"prices" validate:"dive,keys,iso4217,endkeys,price"`
+	Description string            `json:"description" validate:"max=3001"`
+	AuthorBio   string            `json:"author_bio" validate:"max=3000"`
+}
+
+func newBookFromRequest(req *SetValueRequest) newBook {
+	return newBook{
+		Title:       req.Title,
+		AuthorID:    req.AuthorId,
+		Author:      req.Author,
+		Price:       requestPrice(req.PriceDecimal, req.Price),
+		Currency:    req.Currency,
+		Prices:      req.Prices,
+		Description: req.Description,
+		AuthorBio:   req.AuthorBio,
+	}
+}
+
+type bookPatch struct {
+	Title       *string           `json:"title" validate:"omitnil,min=1,max=100"`
+	AuthorID    *int32            `json:"author_id"`
+	Author      *string           `json:"author" validate:"omitnil,min=1,max=100"`
+	Price       *string           `json:"price" validate:"omitnil,price"`
+	Currency    *string           `json:"currency" validate:"omitnil,iso4217"`
+	Prices      map[string]string `json:"prices" validate:"dive,keys,iso4217,endkeys,omitempty,price"` // empty amount removes the price
+	Description *string           `json:"description" validate:"omitnil,max=3001"`
+	AuthorBio   *string           `json:"author_bio" validate:"omitnil,max=3000"`
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	// report proto field names instead of go ones
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.Split(field.Tag.Get("json"), ",")[0]
+	})
+
+	_ = v.RegisterValidation("price", func(fl validator.FieldLevel) bool {
+		_, err := NormalizePrice(fl.Field().String())
+		return err == nil
+	})
+
+	return v
+}
+
+// validateBook returns InvalidArgument status with a violation per bad field
+func validateBook(book interface{}) error {
+	err := validate.Struct(book)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	badRequest := &errdetails.BadRequest{}
+	for _, fe := range fieldErrs {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       fe.Field(),
+			Description: violationDescription(fe),
+		})
+	}
+
+	st, err := status.New(codes.InvalidArgument, "invalid book").WithDetails(badRequest)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "invalid book")
+	}
+
+	return st.Err()
+}
+
+func violationDescription(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "required_without":
+		return "is required without author_id"
+	case "min":
+		return fmt.Sprintf("must be at least %s characters", fe.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s characters", fe.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", fe.Param())
+	case "price":
+		return "must be a non-negative decimal with at most 2 fraction digits"
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	default:
+		return fmt.Sprintf("failed on %s", fe.Tag())
+	}
+}
@@ -0,0 +1,31 @@
+-- name: GetAuthorById :one
+SELECT
+    *
+FROM
+    authors
+WHERE
+    author_id = $1;
+
+-- name: ListAuthors :many
+SELECT
+    *
+FROM
+    authors
+WHERE
+    (sqlc.narg(name_pattern)::text IS NULL OR name ILIKE sqlc.narg(name_pattern))
+    AND (sqlc.narg(after_id)::int IS NULL OR author_id > sqlc.narg(after_id))
+ORDER BY
+    author_id
+LIMIT
+    sqlc.arg(page_limit);
+
+-- name: UpsertAuthor :one
+-- an existing author keeps the bio, it is only filled in if empty
+INSERT INTO authors (name, bio)
+VALUES (sqlc.arg(name), sqlc.narg(bio))
+ON CONFLICT (name) DO UPDATE SET
+    bio = COALESCE(NULLIF(authors.bio, ''), EXCLUDED.bio)
+RETURNING author_id;
+
+-- name: UpdateAuthorBio :exec
+UPDATE authors SET bio = $2 WHERE author_id = $1;
@@ -2,18 +2,149 @@
 SELECT
     *
 FROM
-    books
+    book_details
 WHERE
-    book_id = sqlc.arg(book_id);
+    book_id = sqlc.arg(book_id)
+    AND deleted_at IS NULL;
 
 -- name: InsertBook :one
 INSERT INTO
-    books(title, author, price, description, author_bio)
+    books(title, author_id, price, currency, description)
 VALUES
     (
         sqlc.arg(title),
-        sqlc.arg(author),
+        sqlc.arg(author_id),
         sqlc.arg(price),
-        sqlc.arg(description),
-        sqlc.arg(author_bio)
-    ) RETURNING book_id;
\ No newline at end of file
+        sqlc.arg(currency),
+        sqlc.arg(description)
+    ) RETURNING book_id;
+
+-- name: UpdateBook :one
+UPDATE
+    books
+SET
+    title = COALESCE(sqlc.narg(title), title),
+    author_id = COALESCE(sqlc.narg(author_id), author_id),
+    price = COALESCE(sqlc.narg(price), price),
+    currency = COALESCE(sqlc.narg(currency), currency),
+    description = COALESCE(sqlc.narg(description), description),
+    version = version + 1
+WHERE
+    book_id = sqlc.arg(book_id)
+    AND deleted_at IS NULL
+    AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)) RETURNING *;
+
+-- name: DeleteBook :execrows
+-- books go to trash first, PurgeDeletedBooks removes them for good
+UPDATE
+    books
+SET
+    deleted_at = now(),
+    version = version + 1
+WHERE
+    book_id = sqlc.arg(book_id)
+    AND deleted_at IS NULL
+    AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version));
+
+-- name: ListBooks :many
+SELECT
+    *
+FROM
+    book_details
+WHERE
+    deleted_at IS NULL
+    AND (sqlc.narg(author)::text IS NULL OR author = sqlc.narg(author))
+    AND (sqlc.narg(author_id)::int IS NULL OR author_id = sqlc.narg(author_id))
+    AND (sqlc.narg(title_pattern)::text IS NULL OR title ILIKE sqlc.narg(title_pattern))
+    AND (sqlc.narg(min_price)::numeric IS NULL OR COALESCE(price, 0) >= sqlc.narg(min_price))
+    AND (sqlc.narg(max_price)::numeric IS NULL OR COALESCE(price, 0) <= sqlc.narg(max_price))
+    AND (
+        sqlc.narg(after_id)::int IS NULL
+        OR (sqlc.arg(sort_by)::text = 'id' AND NOT sqlc.arg(sort_desc)::boolean AND book_id > sqlc.narg(after_id))
+        OR (sqlc.arg(sort_by) = 'id' AND sqlc.arg(sort_desc) AND book_id < sqlc.narg(after_id))
+        OR (sqlc.arg(sort_by) = 'title' AND NOT sqlc.arg(sort_desc)
+            AND (title, book_id) > (sqlc.arg(after_title)::text, sqlc.narg(after_id)))
+        OR (sqlc.arg(sort_by) = 'title' AND sqlc.arg(sort_desc)
+            AND (title, book_id) < (sqlc.arg(after_title), sqlc.narg(after_id)))
+        OR (sqlc.arg(sort_by) = 'price' AND NOT sqlc.arg(sort_desc)
+            AND (COALESCE(price, 0), book_id) > (sqlc.narg(after_price)::numeric, sqlc.narg(after_id)))
+        OR (sqlc.arg(sort_by) = 'price' AND sqlc.arg(sort_desc)
+            AND (COALESCE(price, 0), book_id) < (sqlc.narg(after_price), sqlc.narg(after_id)))
+    )
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by) = 'title' AND NOT sqlc.arg(sort_desc) THEN title END ASC,
+    CASE WHEN sqlc.arg(sort_by) = 'title' AND sqlc.arg(sort_desc) THEN title END DESC,
+    CASE WHEN sqlc.arg(sort_by) = 'price' AND NOT sqlc.arg(sort_desc) THEN COALESCE(price, 0) END ASC,
+    CASE WHEN sqlc.arg(sort_by) = 'price' AND sqlc.arg(sort_desc) THEN COALESCE(price, 0) END DESC,
+    CASE WHEN NOT sqlc.arg(sort_desc) THEN book_id END ASC,
+    CASE WHEN sqlc.arg(sort_desc) THEN book_id END DESC
+LIMIT
+    sqlc.arg(page_limit);
+
+-- name: SearchBooks :many
+SELECT
+    book_id,
+    title,
+    author_id,
+    author,
+    price,
+    currency,
+    description,
+    author_bio,
+    ts_rank_cd(search_vector || author_search_vector, query)::real AS rank,
+    -- text is HTML-escaped before <b> tags of matches are added, escaped
+    -- characters are entities for the parser and do not break words
+    ts_headline(
+        'russian',
+        replace(replace(replace(title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        query,
+        'HighlightAll=true'
+    )::text AS title_highlight,
+    ts_headline(
+        'russian',
+        replace(replace(replace(
+            coalesce(description, '') || ' ' || coalesce(author_bio, ''),
+            '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        query,
+        'MaxFragments=2, MinWords=10, MaxWords=30'
+    )::text AS snippet
+FROM
+    book_details,
+    websearch_to_tsquery('russian', sqlc.arg(query)::text) query
+WHERE
+    deleted_at IS NULL
+    AND (search_vector @@ query OR author_search_vector @@ query)
+ORDER BY
+    rank DESC,
+    book_id
+LIMIT
+    sqlc.arg(result_limit);
+
+-- name: RestoreBook :execrows
+UPDATE
+    books
+SET
+    deleted_at = NULL,
+    version = version + 1
+WHERE
+    book_id = sqlc.arg(book_id)
+    AND deleted_at IS NOT NULL;
+
+-- name: ListDeletedBooks :many
+SELECT
+    *
+FROM
+    book_details
+WHERE
+    deleted_at IS NOT NULL
+    AND (sqlc.narg(after_id)::int IS NULL OR book_id > sqlc.narg(after_id))
+ORDER BY
+    book_id
+LIMIT
+    sqlc.arg(page_limit);
+
+-- name: PurgeDeletedBooks :execrows
+DELETE FROM
+    books
+WHERE
+    deleted_at < now() - sqlc.arg(retention_seconds)::int * interval '1 second';
@@ -0,0 +1,22 @@
+-- name: LockIdempotencyKey :exec
+SELECT pg_advisory_xact_lock(hashtext(sqlc.arg(key)::text));
+
+-- name: GetIdempotencyKey :one
+SELECT
+    *
+FROM
+    idempotency_keys
+WHERE
+    key = $1 AND expires_at > now();
+
+-- name: SaveIdempotencyKey :exec
+INSERT INTO idempotency_keys (key, request_hash, book_id, expires_at)
+VALUES (sqlc.arg(key), sqlc.arg(request_hash), sqlc.arg(book_id), now() + sqlc.arg(ttl_seconds)::int * interval '1 second')
+ON CONFLICT (key) DO UPDATE SET
+    request_hash = EXCLUDED.request_hash,
+    book_id = EXCLUDED.book_id,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= now();
@@ -0,0 +1,26 @@
+-- name: ListBookPrices :many
+SELECT
+    *
+FROM
+    book_prices
+WHERE
+    book_id = ANY(sqlc.arg(book_ids)::int[])
+ORDER BY
+    book_id,
+    currency;
+
+-- name: UpsertBookPrice :exec
+INSERT INTO book_prices (book_id, currency, amount)
+VALUES (sqlc.arg(book_id), sqlc.arg(currency), sqlc.arg(amount))
+ON CONFLICT (book_id, currency) DO UPDATE SET
+    amount = EXCLUDED.amount;
+
+-- name: DeleteBookPrice :exec
+DELETE FROM book_prices WHERE book_id = $1 AND currency = $2;
+
+-- name: DeleteOtherBookPrices :exec
+DELETE FROM
+    book_prices
+WHERE
+    book_id = sqlc.arg(book_id)
+    AND NOT (currency = ANY(sqlc.arg(keep)::text[]));
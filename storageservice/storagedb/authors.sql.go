@@ -0,0 +1,115 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.2
+// source: authors.sql
+
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+)
+
+const getAuthorById = `-- name: GetAuthorById :one
+SELECT
+    author_id, name, bio, search_vector
+FROM
+    authors
+WHERE
+    author_id = $1
+`
+
+func (q *Queries) GetAuthorById(ctx context.Context, authorID int32) (Author, error) {
+	row := q.db.QueryRowContext(ctx, getAuthorById, authorID)
+	var i Author
+	err := row.Scan(
+		&i.AuthorID,
+		&i.Name,
+		&i.Bio,
+		&i.SearchVector,
+	)
+	return i, err
+}
+
+const listAuthors = `-- name: ListAuthors :many
+SELECT
+    author_id, name, bio, search_vector
+FROM
+    authors
+WHERE
+    ($1::text IS NULL OR name ILIKE $1)
+    AND ($2::int IS NULL OR author_id > $2)
+ORDER BY
+    author_id
+LIMIT
+    $3
+`
+
+type ListAuthorsParams struct {
+	NamePattern sql.NullString
+	AfterID     sql.NullInt32
+	PageLimit   int32
+}
+
+func (q *Queries) ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Author, error) {
+	rows, err := q.db.QueryContext(ctx, listAuthors, arg.NamePattern, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Author
+	for rows.Next() {
+		var i Author
+		if err := rows.Scan(
+			&i.AuthorID,
+			&i.Name,
+			&i.Bio,
+			&i.SearchVector,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateAuthorBio = `-- name: UpdateAuthorBio :exec
+UPDATE authors SET bio = $2 WHERE author_id = $1
+`
+
+type UpdateAuthorBioParams struct {
+	AuthorID int32
+	Bio      sql.NullString
+}
+
+func (q *Queries) UpdateAuthorBio(ctx context.Context, arg UpdateAuthorBioParams) error {
+	_, err := q.db.ExecContext(ctx, updateAuthorBio, arg.AuthorID, arg.Bio)
+	return err
+}
+
+const upsertAuthor = `-- name: UpsertAuthor :one
+INSERT INTO authors (name, bio)
+VALUES ($1, $2)
+ON CONFLICT (name) DO UPDATE SET
+    bio = COALESCE(NULLIF(authors.bio, ''), EXCLUDED.bio)
+RETURNING author_id
+`
+
+type UpsertAuthorParams struct {
+	Name string
+	Bio  sql.NullString
+}
+
+// an existing author keeps the bio, it is only filled in if empty
+func (q *Queries) UpsertAuthor(ctx context.Context, arg UpsertAuthorParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, upsertAuthor, arg.Name, arg.Bio)
+	var author_id int32
+	err := row.Scan(&author_id)
+	return author_id, err
+}
@@ -10,32 +10,65 @@ import (
 	"database/sql"
 )
 
+const deleteBook = `-- name: DeleteBook :execrows
+UPDATE
+    books
+SET
+    deleted_at = now(),
+    version = version + 1
+WHERE
+    book_id = $1
+    AND deleted_at IS NULL
+    AND ($2::int IS NULL OR version = $2)
+`
+
+type DeleteBookParams struct {
+	BookID          int32
+	ExpectedVersion sql.NullInt32
+}
+
+// books go to trash first, PurgeDeletedBooks removes them for good
+func (q *Queries) DeleteBook(ctx context.Context, arg DeleteBookParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBook, arg.BookID, arg.ExpectedVersion)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getBookById = `-- name: GetBookById :one
 SELECT
-    book_id, title, author, price, description, author_bio
+    book_id, title, author_id, author, price, currency, description, author_bio, version, search_vector, author_search_vector, deleted_at
 FROM
-    books
+    book_details
 WHERE
     book_id = $1
+    AND deleted_at IS NULL
 `
 
-func (q *Queries) GetBookById(ctx context.Context, bookID int32) (Book, error) {
+func (q *Queries) GetBookById(ctx context.Context, bookID int32) (BookDetail, error) {
 	row := q.db.QueryRowContext(ctx, getBookById, bookID)
-	var i Book
+	var i BookDetail
 	err := row.Scan(
 		&i.BookID,
 		&i.Title,
+		&i.AuthorID,
 		&i.Author,
 		&i.Price,
+		&i.Currency,
 		&i.Description,
 		&i.AuthorBio,
+		&i.Version,
+		&i.SearchVector,
+		&i.AuthorSearchVector,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const insertBook = `-- name: InsertBook :one
 INSERT INTO
-    books(title, author, price, description, author_bio)
+    books(title, author_id, price, currency, description)
 VALUES
     (
         $1,
@@ -48,21 +81,353 @@ VALUES
 
 type InsertBookParams struct {
 	Title       string
-	Author      string
-	Price       sql.NullFloat64
+	AuthorID    int32
+	Price       sql.NullString
+	Currency    string
 	Description sql.NullString
-	AuthorBio   sql.NullString
 }
 
 func (q *Queries) InsertBook(ctx context.Context, arg InsertBookParams) (int32, error) {
 	row := q.db.QueryRowContext(ctx, insertBook,
 		arg.Title,
-		arg.Author,
+		arg.AuthorID,
 		arg.Price,
+		arg.Currency,
 		arg.Description,
-		arg.AuthorBio,
 	)
 	var book_id int32
 	err := row.Scan(&book_id)
 	return book_id, err
 }
+
+const listBooks = `-- name: ListBooks :many
+SELECT
+    book_id, title, author_id, author, price, currency, description, author_bio, version, search_vector, author_search_vector, deleted_at
+FROM
+    book_details
+WHERE
+    deleted_at IS NULL
+    AND ($1::text IS NULL OR author = $1)
+    AND ($2::int IS NULL OR author_id = $2)
+    AND ($3::text IS NULL OR title ILIKE $3)
+    AND ($4::numeric IS NULL OR COALESCE(price, 0) >= $4)
+    AND ($5::numeric IS NULL OR COALESCE(price, 0) <= $5)
+    AND (
+        $6::int IS NULL
+        OR ($7::text = 'id' AND NOT $8::boolean AND book_id > $6)
+        OR ($7 = 'id' AND $8 AND book_id < $6)
+        OR ($7 = 'title' AND NOT $8
+            AND (title, book_id) > ($9::text, $6))
+        OR ($7 = 'title' AND $8
+            AND (title, book_id) < ($9, $6))
+        OR ($7 = 'price' AND NOT $8
+            AND (COALESCE(price, 0), book_id) > ($10::numeric, $6))
+        OR ($7 = 'price' AND $8
+            AND (COALESCE(price, 0), book_id) < ($10, $6))
+    )
+ORDER BY
+    CASE WHEN $7 = 'title' AND NOT $8 THEN title END ASC,
+    CASE WHEN $7 = 'title' AND $8 THEN title END DESC,
+    CASE WHEN $7 = 'price' AND NOT $8 THEN COALESCE(price, 0) END ASC,
+    CASE WHEN $7 = 'price' AND $8 THEN COALESCE(price, 0) END DESC,
+    CASE WHEN NOT $8 THEN book_id END ASC,
+    CASE WHEN $8 THEN book_id END DESC
+LIMIT
+    $11
+`
+
+type ListBooksParams struct {
+	Author       sql.NullString
+	AuthorID     sql.NullInt32
+	TitlePattern sql.NullString
+	MinPrice     sql.NullString
+	MaxPrice     sql.NullString
+	AfterID      sql.NullInt32
+	SortBy       string
+	SortDesc     bool
+	AfterTitle   string
+	AfterPrice   sql.NullString
+	PageLimit    int32
+}
+
+func (q *Queries) ListBooks(ctx context.Context, arg ListBooksParams) ([]BookDetail, error) {
+	rows, err := q.db.QueryContext(ctx, listBooks,
+		arg.Author,
+		arg.AuthorID,
+		arg.TitlePattern,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.AfterID,
+		arg.SortBy,
+		arg.SortDesc,
+		arg.AfterTitle,
+		arg.AfterPrice,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookDetail
+	for rows.Next() {
+		var i BookDetail
+		if err := rows.Scan(
+			&i.BookID,
+			&i.Title,
+			&i.AuthorID,
+			&i.Author,
+			&i.Price,
+			&i.Currency,
+			&i.Description,
+			&i.AuthorBio,
+			&i.Version,
+			&i.SearchVector,
+			&i.AuthorSearchVector,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedBooks = `-- name: ListDeletedBooks :many
+SELECT
+    book_id, title, author_id, author, price, currency, description, author_bio, version, search_vector, author_search_vector, deleted_at
+FROM
+    book_details
+WHERE
+    deleted_at IS NOT NULL
+    AND ($1::int IS NULL OR book_id > $1)
+ORDER BY
+    book_id
+LIMIT
+    $2
+`
+
+type ListDeletedBooksParams struct {
+	AfterID   sql.NullInt32
+	PageLimit int32
+}
+
+func (q *Queries) ListDeletedBooks(ctx context.Context, arg ListDeletedBooksParams) ([]BookDetail, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedBooks, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookDetail
+	for rows.Next() {
+		var i BookDetail
+		if err := rows.Scan(
+			&i.BookID,
+			&i.Title,
+			&i.AuthorID,
+			&i.Author,
+			&i.Price,
+			&i.Currency,
+			&i.Description,
+			&i.AuthorBio,
+			&i.Version,
+			&i.SearchVector,
+			&i.AuthorSearchVector,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedBooks = `-- name: PurgeDeletedBooks :execrows
+DELETE FROM
+    books
+WHERE
+    deleted_at < now() - $1::int * interval '1 second'
+`
+
+func (q *Queries) PurgeDeletedBooks(ctx context.Context, retentionSeconds int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedBooks, retentionSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const restoreBook = `-- name: RestoreBook :execrows
+UPDATE
+    books
+SET
+    deleted_at = NULL,
+    version = version + 1
+WHERE
+    book_id = $1
+    AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreBook(ctx context.Context, bookID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, restoreBook, bookID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchBooks = `-- name: SearchBooks :many
+SELECT
+    book_id,
+    title,
+    author_id,
+    author,
+    price,
+    currency,
+    description,
+    author_bio,
+    ts_rank_cd(search_vector || author_search_vector, query)::real AS rank,
+    -- text is HTML-escaped before <b> tags of matches are added, escaped
+    -- characters are entities for the parser and do not break words
+    ts_headline(
+        'russian',
+        replace(replace(replace(title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        query,
+        'HighlightAll=true'
+    )::text AS title_highlight,
+    ts_headline(
+        'russian',
+        replace(replace(replace(
+            coalesce(description, '') || ' ' || coalesce(author_bio, ''),
+            '&', '&amp;'), '<', '&lt;'), '>', '&gt;'),
+        query,
+        'MaxFragments=2, MinWords=10, MaxWords=30'
+    )::text AS snippet
+FROM
+    book_details,
+    websearch_to_tsquery('russian', $1::text) query
+WHERE
+    deleted_at IS NULL
+    AND (search_vector @@ query OR author_search_vector @@ query)
+ORDER BY
+    rank DESC,
+    book_id
+LIMIT
+    $2
+`
+
+type SearchBooksParams struct {
+	Query       string
+	ResultLimit int32
+}
+
+type SearchBooksRow struct {
+	BookID         int32
+	Title          string
+	AuthorID       int32
+	Author         string
+	Price          sql.NullString
+	Currency       string
+	Description    sql.NullString
+	AuthorBio      sql.NullString
+	Rank           float32
+	TitleHighlight string
+	Snippet        string
+}
+
+func (q *Queries) SearchBooks(ctx context.Context, arg SearchBooksParams) ([]SearchBooksRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchBooks, arg.Query, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchBooksRow
+	for rows.Next() {
+		var i SearchBooksRow
+		if err := rows.Scan(
+			&i.BookID,
+			&i.Title,
+			&i.AuthorID,
+			&i.Author,
+			&i.Price,
+			&i.Currency,
+			&i.Description,
+			&i.AuthorBio,
+			&i.Rank,
+			&i.TitleHighlight,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateBook = `-- name: UpdateBook :one
+UPDATE
+    books
+SET
+    title = COALESCE($1, title),
+    author_id = COALESCE($2, author_id),
+    price = COALESCE($3, price),
+    currency = COALESCE($4, currency),
+    description = COALESCE($5, description),
+    version = version + 1
+WHERE
+    book_id = $6
+    AND deleted_at IS NULL
+    AND ($7::int IS NULL OR version = $7) RETURNING book_id, title, price, description, version, currency, author_id, search_vector, deleted_at
+`
+
+type UpdateBookParams struct {
+	Title           sql.NullString
+	AuthorID        sql.NullInt32
+	Price           sql.NullString
+	Currency        sql.NullString
+	Description     sql.NullString
+	BookID          int32
+	ExpectedVersion sql.NullInt32
+}
+
+func (q *Queries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, error) {
+	row := q.db.QueryRowContext(ctx, updateBook,
+		arg.Title,
+		arg.AuthorID,
+		arg.Price,
+		arg.Currency,
+		arg.Description,
+		arg.BookID,
+		arg.ExpectedVersion,
+	)
+	var i Book
+	err := row.Scan(
+		&i.BookID,
+		&i.Title,
+		&i.Price,
+		&i.Description,
+		&i.Version,
+		&i.Currency,
+		&i.AuthorID,
+		&i.SearchVector,
+		&i.DeletedAt,
+	)
+	return i, err
+}
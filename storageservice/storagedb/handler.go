@@ -1,44 +1,97 @@
 package storagedb
 
 import (
+	"context"
 	"database/sql"
-	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/pkg/errors"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
 )
 
+// StorageDbHandler is BookRepository backed by postgres
 type StorageDbHandler struct {
-	Queries *Queries
-	dbConn  *sql.DB
+	countingQuerier
+	dbConn *sql.DB
+	traced *tracedDBTX
 }
 
-func NewStorageDbHandler(connStr string) (*StorageDbHandler, error) {
+var _ BookRepository = (*StorageDbHandler)(nil)
+
+func NewStorageDbHandler(ctx context.Context, connStr string, opts DBOptions) (*StorageDbHandler, error) {
 	dbConn, err := sql.Open("postgres", connStr)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to connect to db with conn string %s", connStr)
 	}
 
-	for i := 0; i < 5; i++ {
-		err = dbConn.Ping()
-		if err != nil {
-			time.Sleep(5 * time.Second)
-			continue
-		}
-	}
+	configurePool(dbConn, opts)
 
-	if err != nil {
-		return nil, errors.Wrap(err, "ping failed")
+	if err := waitForDB(ctx, dbConn, opts); err != nil {
+		_ = dbConn.Close()
+		return nil, err
 	}
 
-	queries := New(dbConn)
+	traced := newTracedDBTX(dbConn, semconv.DBSystemPostgreSQL, opts)
 
 	return &StorageDbHandler{
-		Queries: queries,
-		dbConn:  dbConn,
+		countingQuerier: countingQuerier{New(traced)},
+		dbConn:          dbConn,
+		traced:          traced,
 	}, nil
 }
 
+// Stats are the connection pool statistics
+func (hdl *StorageDbHandler) Stats() sql.DBStats {
+	return hdl.dbConn.Stats()
+}
+
+// InTx runs fn in a transaction which is committed if fn returns nil
+func (hdl *StorageDbHandler) InTx(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := hdl.dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	traced := hdl.traced.withTx(tx)
+	if err := fn(&sqlTx{countingQuerier: countingQuerier{New(traced)}, tx: traced}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Migrator migrates the schema of the repository database
+func (hdl *StorageDbHandler) Migrator() (*Migrator, error) {
+	return newPostgresMigrator(context.Background(), hdl.dbConn)
+}
+
 func (hdl *StorageDbHandler) Close() error {
 	return hdl.dbConn.Close()
 }
+
+type sqlTx struct {
+	countingQuerier
+	tx DBTX
+}
+
+func (t *sqlTx) Savepoint(ctx context.Context, fn func() error) error {
+	return savepoint(ctx, t.tx, fn)
+}
+
+// savepoint is Tx.Savepoint of the sql backends, postgres and sqlite share the syntax
+func savepoint(ctx context.Context, tx DBTX, fn func() error) error {
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT repository_savepoint"); err != nil {
+		return err
+	}
+
+	if err := fn(); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT repository_savepoint"); rbErr != nil {
+			return errors.Wrap(rbErr, "failed to roll back to savepoint")
+		}
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT repository_savepoint")
+	return err
+}
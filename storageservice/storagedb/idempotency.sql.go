@@ -0,0 +1,80 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.2
+// source: idempotency.sql
+
+package storagedb
+
+import (
+	"context"
+)
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT
+    key, request_hash, book_id, created_at, expires_at
+FROM
+    idempotency_keys
+WHERE
+    key = $1 AND expires_at > now()
+`
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.BookID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const lockIdempotencyKey = `-- name: LockIdempotencyKey :exec
+SELECT pg_advisory_xact_lock(hashtext($1::text))
+`
+
+func (q *Queries) LockIdempotencyKey(ctx context.Context, key string) error {
+	_, err := q.db.ExecContext(ctx, lockIdempotencyKey, key)
+	return err
+}
+
+const saveIdempotencyKey = `-- name: SaveIdempotencyKey :exec
+INSERT INTO idempotency_keys (key, request_hash, book_id, expires_at)
+VALUES ($1, $2, $3, now() + $4::int * interval '1 second')
+ON CONFLICT (key) DO UPDATE SET
+    request_hash = EXCLUDED.request_hash,
+    book_id = EXCLUDED.book_id,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at
+`
+
+type SaveIdempotencyKeyParams struct {
+	Key         string
+	RequestHash string
+	BookID      int32
+	TtlSeconds  int32
+}
+
+func (q *Queries) SaveIdempotencyKey(ctx context.Context, arg SaveIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, saveIdempotencyKey,
+		arg.Key,
+		arg.RequestHash,
+		arg.BookID,
+		arg.TtlSeconds,
+	)
+	return err
+}
@@ -0,0 +1,696 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MemoryRepository is BookRepository kept in process memory, it starts empty
+// and is meant for tests and running the service without postgres.
+// Transactions are serialized, each works on a copy of the data which replaces
+// the data on commit
+type MemoryRepository struct {
+	mu   sync.RWMutex
+	data *memData
+}
+
+var _ BookRepository = (*MemoryRepository)(nil)
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{data: newMemData()}
+}
+
+func (repo *MemoryRepository) InTx(ctx context.Context, fn func(tx Tx) error) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tx := &memTx{memData: repo.data.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	repo.data = tx.memData
+	return nil
+}
+
+func (repo *MemoryRepository) Close() error {
+	return nil
+}
+
+func (repo *MemoryRepository) read(ctx context.Context, fn func(d *memData) error) error {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return fn(repo.data)
+}
+
+func (repo *MemoryRepository) write(ctx context.Context, fn func(d *memData) error) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// a failed statement leaves no changes as it would in postgres
+	d := repo.data.clone()
+	if err := fn(d); err != nil {
+		return err
+	}
+
+	repo.data = d
+	return nil
+}
+
+func (repo *MemoryRepository) DeleteBook(ctx context.Context, arg DeleteBookParams) (n int64, err error) {
+	err = repo.write(ctx, func(d *memData) error { n, err = d.DeleteBook(ctx, arg); return err })
+	return n, err
+}
+
+func (repo *MemoryRepository) DeleteBookPrice(ctx context.Context, arg DeleteBookPriceParams) error {
+	return repo.write(ctx, func(d *memData) error { return d.DeleteBookPrice(ctx, arg) })
+}
+
+func (repo *MemoryRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (n int64, err error) {
+	err = repo.write(ctx, func(d *memData) error { n, err = d.DeleteExpiredIdempotencyKeys(ctx); return err })
+	return n, err
+}
+
+func (repo *MemoryRepository) DeleteOtherBookPrices(ctx context.Context, arg DeleteOtherBookPricesParams) error {
+	return repo.write(ctx, func(d *memData) error { return d.DeleteOtherBookPrices(ctx, arg) })
+}
+
+func (repo *MemoryRepository) GetAuthorById(ctx context.Context, authorID int32) (a Author, err error) {
+	err = repo.read(ctx, func(d *memData) error { a, err = d.GetAuthorById(ctx, authorID); return err })
+	return a, err
+}
+
+func (repo *MemoryRepository) GetBookById(ctx context.Context, bookID int32) (b BookDetail, err error) {
+	err = repo.read(ctx, func(d *memData) error { b, err = d.GetBookById(ctx, bookID); return err })
+	return b, err
+}
+
+func (repo *MemoryRepository) GetIdempotencyKey(ctx context.Context, key string) (k IdempotencyKey, err error) {
+	err = repo.read(ctx, func(d *memData) error { k, err = d.GetIdempotencyKey(ctx, key); return err })
+	return k, err
+}
+
+func (repo *MemoryRepository) InsertBook(ctx context.Context, arg InsertBookParams) (id int32, err error) {
+	err = repo.write(ctx, func(d *memData) error { id, err = d.InsertBook(ctx, arg); return err })
+	return id, err
+}
+
+func (repo *MemoryRepository) ListAuthors(ctx context.Context, arg ListAuthorsParams) (a []Author, err error) {
+	err = repo.read(ctx, func(d *memData) error { a, err = d.ListAuthors(ctx, arg); return err })
+	return a, err
+}
+
+func (repo *MemoryRepository) ListBookPrices(ctx context.Context, bookIds []int32) (p []BookPrice, err error) {
+	err = repo.read(ctx, func(d *memData) error { p, err = d.ListBookPrices(ctx, bookIds); return err })
+	return p, err
+}
+
+func (repo *MemoryRepository) ListBooks(ctx context.Context, arg ListBooksParams) (b []BookDetail, err error) {
+	err = repo.read(ctx, func(d *memData) error { b, err = d.ListBooks(ctx, arg); return err })
+	return b, err
+}
+
+func (repo *MemoryRepository) ListDeletedBooks(ctx context.Context, arg ListDeletedBooksParams) (b []BookDetail, err error) {
+	err = repo.read(ctx, func(d *memData) error { b, err = d.ListDeletedBooks(ctx, arg); return err })
+	return b, err
+}
+
+func (repo *MemoryRepository) LockIdempotencyKey(ctx context.Context, key string) error {
+	return repo.read(ctx, func(d *memData) error { return d.LockIdempotencyKey(ctx, key) })
+}
+
+func (repo *MemoryRepository) PurgeDeletedBooks(ctx context.Context, retentionSeconds int32) (n int64, err error) {
+	err = repo.write(ctx, func(d *memData) error { n, err = d.PurgeDeletedBooks(ctx, retentionSeconds); return err })
+	return n, err
+}
+
+func (repo *MemoryRepository) RestoreBook(ctx context.Context, bookID int32) (n int64, err error) {
+	err = repo.write(ctx, func(d *memData) error { n, err = d.RestoreBook(ctx, bookID); return err })
+	return n, err
+}
+
+func (repo *MemoryRepository) SaveIdempotencyKey(ctx context.Context, arg SaveIdempotencyKeyParams) error {
+	return repo.write(ctx, func(d *memData) error { return d.SaveIdempotencyKey(ctx, arg) })
+}
+
+func (repo *MemoryRepository) SearchBooks(ctx context.Context, arg SearchBooksParams) (r []SearchBooksRow, err error) {
+	err = repo.read(ctx, func(d *memData) error { r, err = d.SearchBooks(ctx, arg); return err })
+	return r, err
+}
+
+func (repo *MemoryRepository) UpdateAuthorBio(ctx context.Context, arg UpdateAuthorBioParams) error {
+	return repo.write(ctx, func(d *memData) error { return d.UpdateAuthorBio(ctx, arg) })
+}
+
+func (repo *MemoryRepository) UpdateBook(ctx context.Context, arg UpdateBookParams) (b Book, err error) {
+	err = repo.write(ctx, func(d *memData) error { b, err = d.UpdateBook(ctx, arg); return err })
+	return b, err
+}
+
+func (repo *MemoryRepository) UpsertAuthor(ctx context.Context, arg UpsertAuthorParams) (id int32, err error) {
+	err = repo.write(ctx, func(d *memData) error { id, err = d.UpsertAuthor(ctx, arg); return err })
+	return id, err
+}
+
+func (repo *MemoryRepository) UpsertBookPrice(ctx context.Context, arg UpsertBookPriceParams) error {
+	return repo.write(ctx, func(d *memData) error { return d.UpsertBookPrice(ctx, arg) })
+}
+
+type memTx struct {
+	*memData
+}
+
+func (tx *memTx) Savepoint(_ context.Context, fn func() error) error {
+	saved := tx.memData.clone()
+
+	if err := fn(); err != nil {
+		*tx.memData = *saved
+		return err
+	}
+
+	return nil
+}
+
+// memData is the tables of MemoryRepository, its methods are the queries
+// of the sql files run against them without any locking
+type memData struct {
+	books        map[int32]Book
+	authors      map[int32]Author
+	prices       map[int32]map[string]string
+	keys         map[string]IdempotencyKey
+	lastBookID   int32
+	lastAuthorID int32
+}
+
+var _ Querier = (*memData)(nil)
+
+func newMemData() *memData {
+	return &memData{
+		books:   map[int32]Book{},
+		authors: map[int32]Author{},
+		prices:  map[int32]map[string]string{},
+		keys:    map[string]IdempotencyKey{},
+	}
+}
+
+func (d *memData) clone() *memData {
+	c := &memData{
+		books:        make(map[int32]Book, len(d.books)),
+		authors:      make(map[int32]Author, len(d.authors)),
+		prices:       make(map[int32]map[string]string, len(d.prices)),
+		keys:         make(map[string]IdempotencyKey, len(d.keys)),
+		lastBookID:   d.lastBookID,
+		lastAuthorID: d.lastAuthorID,
+	}
+
+	for id, b := range d.books {
+		c.books[id] = b
+	}
+	for id, a := range d.authors {
+		c.authors[id] = a
+	}
+	for id, prices := range d.prices {
+		c.prices[id] = make(map[string]string, len(prices))
+		for currency, amount := range prices {
+			c.prices[id][currency] = amount
+		}
+	}
+	for key, k := range d.keys {
+		c.keys[key] = k
+	}
+
+	return c
+}
+
+// detail is the book_details view row of b
+func (d *memData) detail(b Book) BookDetail {
+	author := d.authors[b.AuthorID]
+
+	return BookDetail{
+		BookID:      b.BookID,
+		Title:       b.Title,
+		AuthorID:    b.AuthorID,
+		Author:      author.Name,
+		Price:       b.Price,
+		Currency:    b.Currency,
+		Description: b.Description,
+		AuthorBio:   author.Bio,
+		Version:     b.Version,
+		DeletedAt:   b.DeletedAt,
+	}
+}
+
+func (d *memData) checkAuthor(authorID int32) error {
+	if _, ok := d.authors[authorID]; !ok {
+		return fmt.Errorf("author %d does not exist", authorID)
+	}
+
+	return nil
+}
+
+func (d *memData) DeleteBook(_ context.Context, arg DeleteBookParams) (int64, error) {
+	b, ok := d.books[arg.BookID]
+	if !ok || b.DeletedAt.Valid || !versionMatches(b, arg.ExpectedVersion) {
+		return 0, nil
+	}
+
+	b.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	b.Version++
+	d.books[b.BookID] = b
+
+	return 1, nil
+}
+
+func (d *memData) DeleteBookPrice(_ context.Context, arg DeleteBookPriceParams) error {
+	delete(d.prices[arg.BookID], arg.Currency)
+	return nil
+}
+
+func (d *memData) DeleteExpiredIdempotencyKeys(_ context.Context) (int64, error) {
+	var n int64
+
+	now := time.Now()
+	for key, k := range d.keys {
+		if !k.ExpiresAt.After(now) {
+			delete(d.keys, key)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (d *memData) DeleteOtherBookPrices(_ context.Context, arg DeleteOtherBookPricesParams) error {
+	keep := make(map[string]bool, len(arg.Keep))
+	for _, currency := range arg.Keep {
+		keep[currency] = true
+	}
+
+	for currency := range d.prices[arg.BookID] {
+		if !keep[currency] {
+			delete(d.prices[arg.BookID], currency)
+		}
+	}
+
+	return nil
+}
+
+func (d *memData) GetAuthorById(_ context.Context, authorID int32) (Author, error) {
+	a, ok := d.authors[authorID]
+	if !ok {
+		return Author{}, sql.ErrNoRows
+	}
+
+	return a, nil
+}
+
+func (d *memData) GetBookById(_ context.Context, bookID int32) (BookDetail, error) {
+	b, ok := d.books[bookID]
+	if !ok || b.DeletedAt.Valid {
+		return BookDetail{}, sql.ErrNoRows
+	}
+
+	return d.detail(b), nil
+}
+
+func (d *memData) GetIdempotencyKey(_ context.Context, key string) (IdempotencyKey, error) {
+	k, ok := d.keys[key]
+	if !ok || !k.ExpiresAt.After(time.Now()) {
+		return IdempotencyKey{}, sql.ErrNoRows
+	}
+
+	return k, nil
+}
+
+func (d *memData) InsertBook(_ context.Context, arg InsertBookParams) (int32, error) {
+	if err := d.checkAuthor(arg.AuthorID); err != nil {
+		return 0, err
+	}
+
+	d.lastBookID++
+	d.books[d.lastBookID] = Book{
+		BookID:      d.lastBookID,
+		Title:       arg.Title,
+		Price:       arg.Price,
+		Description: arg.Description,
+		Version:     1,
+		Currency:    arg.Currency,
+		AuthorID:    arg.AuthorID,
+	}
+
+	return d.lastBookID, nil
+}
+
+func (d *memData) ListAuthors(_ context.Context, arg ListAuthorsParams) ([]Author, error) {
+	var match func(string) bool
+	if arg.NamePattern.Valid {
+		re, err := ilikeRegexp(arg.NamePattern.String)
+		if err != nil {
+			return nil, err
+		}
+		match = re.MatchString
+	}
+
+	var items []Author
+	for _, a := range d.authors {
+		if arg.AfterID.Valid && a.AuthorID <= arg.AfterID.Int32 {
+			continue
+		}
+		if match != nil && !match(a.Name) {
+			continue
+		}
+		items = append(items, a)
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].AuthorID < items[j].AuthorID })
+
+	return limitRows(items, arg.PageLimit), nil
+}
+
+func (d *memData) ListBookPrices(_ context.Context, bookIds []int32) ([]BookPrice, error) {
+	var items []BookPrice
+	for _, id := range bookIds {
+		for currency, amount := range d.prices[id] {
+			items = append(items, BookPrice{BookID: id, Currency: currency, Amount: amount})
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].BookID != items[j].BookID {
+			return items[i].BookID < items[j].BookID
+		}
+		return items[i].Currency < items[j].Currency
+	})
+
+	return items, nil
+}
+
+func (d *memData) ListBooks(_ context.Context, arg ListBooksParams) ([]BookDetail, error) {
+	var titleMatch func(string) bool
+	if arg.TitlePattern.Valid {
+		re, err := ilikeRegexp(arg.TitlePattern.String)
+		if err != nil {
+			return nil, err
+		}
+		titleMatch = re.MatchString
+	}
+
+	minPrice, err := nullRat(arg.MinPrice)
+	if err != nil {
+		return nil, err
+	}
+	maxPrice, err := nullRat(arg.MaxPrice)
+	if err != nil {
+		return nil, err
+	}
+	afterPrice, err := nullRat(arg.AfterPrice)
+	if err != nil {
+		return nil, err
+	}
+	if afterPrice == nil {
+		afterPrice = new(big.Rat)
+	}
+
+	// compare orders rows as ORDER BY of the query does
+	compare := func(aID int32, aTitle string, aPrice *big.Rat, bID int32, bTitle string, bPrice *big.Rat) int {
+		c := 0
+		switch arg.SortBy {
+		case "title":
+			c = strings.Compare(aTitle, bTitle)
+		case "price":
+			c = aPrice.Cmp(bPrice)
+		}
+		if c == 0 {
+			c = compareInt32(aID, bID)
+		}
+		if arg.SortDesc {
+			c = -c
+		}
+		return c
+	}
+
+	var items []BookDetail
+	for _, b := range d.books {
+		if b.DeletedAt.Valid {
+			continue
+		}
+
+		row := d.detail(b)
+		price := bookPrice(row.Price)
+
+		switch {
+		case arg.Author.Valid && row.Author != arg.Author.String,
+			arg.AuthorID.Valid && row.AuthorID != arg.AuthorID.Int32,
+			titleMatch != nil && !titleMatch(row.Title),
+			minPrice != nil && price.Cmp(minPrice) < 0,
+			maxPrice != nil && price.Cmp(maxPrice) > 0:
+			continue
+		}
+
+		if arg.AfterID.Valid &&
+			compare(row.BookID, row.Title, price, arg.AfterID.Int32, arg.AfterTitle, afterPrice) <= 0 {
+			continue
+		}
+
+		items = append(items, row)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return compare(items[i].BookID, items[i].Title, bookPrice(items[i].Price),
+			items[j].BookID, items[j].Title, bookPrice(items[j].Price)) < 0
+	})
+
+	return limitRows(items, arg.PageLimit), nil
+}
+
+func (d *memData) ListDeletedBooks(_ context.Context, arg ListDeletedBooksParams) ([]BookDetail, error) {
+	var items []BookDetail
+	for _, b := range d.books {
+		if !b.DeletedAt.Valid || (arg.AfterID.Valid && b.BookID <= arg.AfterID.Int32) {
+			continue
+		}
+		items = append(items, d.detail(b))
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].BookID < items[j].BookID })
+
+	return limitRows(items, arg.PageLimit), nil
+}
+
+// LockIdempotencyKey has nothing to do as transactions are serialized
+func (d *memData) LockIdempotencyKey(_ context.Context, _ string) error {
+	return nil
+}
+
+func (d *memData) PurgeDeletedBooks(_ context.Context, retentionSeconds int32) (int64, error) {
+	var n int64
+
+	deadline := time.Now().Add(-time.Duration(retentionSeconds) * time.Second)
+	for id, b := range d.books {
+		if b.DeletedAt.Valid && b.DeletedAt.Time.Before(deadline) {
+			delete(d.books, id)
+			delete(d.prices, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+func (d *memData) RestoreBook(_ context.Context, bookID int32) (int64, error) {
+	b, ok := d.books[bookID]
+	if !ok || !b.DeletedAt.Valid {
+		return 0, nil
+	}
+
+	b.DeletedAt = sql.NullTime{}
+	b.Version++
+	d.books[bookID] = b
+
+	return 1, nil
+}
+
+func (d *memData) SaveIdempotencyKey(_ context.Context, arg SaveIdempotencyKeyParams) error {
+	now := time.Now()
+
+	d.keys[arg.Key] = IdempotencyKey{
+		Key:         arg.Key,
+		RequestHash: arg.RequestHash,
+		BookID:      arg.BookID,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Duration(arg.TtlSeconds) * time.Second),
+	}
+
+	return nil
+}
+
+func (d *memData) UpdateAuthorBio(_ context.Context, arg UpdateAuthorBioParams) error {
+	a, ok := d.authors[arg.AuthorID]
+	if !ok {
+		return nil
+	}
+
+	a.Bio = arg.Bio
+	d.authors[arg.AuthorID] = a
+
+	return nil
+}
+
+func (d *memData) UpdateBook(_ context.Context, arg UpdateBookParams) (Book, error) {
+	b, ok := d.books[arg.BookID]
+	if !ok || b.DeletedAt.Valid || !versionMatches(b, arg.ExpectedVersion) {
+		return Book{}, sql.ErrNoRows
+	}
+
+	if arg.Title.Valid {
+		b.Title = arg.Title.String
+	}
+	if arg.AuthorID.Valid {
+		if err := d.checkAuthor(arg.AuthorID.Int32); err != nil {
+			return Book{}, err
+		}
+		b.AuthorID = arg.AuthorID.Int32
+	}
+	if arg.Price.Valid {
+		b.Price = arg.Price
+	}
+	if arg.Currency.Valid {
+		b.Currency = arg.Currency.String
+	}
+	if arg.Description.Valid {
+		b.Description = arg.Description
+	}
+	b.Version++
+
+	d.books[b.BookID] = b
+
+	return b, nil
+}
+
+func (d *memData) UpsertAuthor(_ context.Context, arg UpsertAuthorParams) (int32, error) {
+	for id, a := range d.authors {
+		if a.Name != arg.Name {
+			continue
+		}
+
+		if !a.Bio.Valid || a.Bio.String == "" {
+			a.Bio = arg.Bio
+			d.authors[id] = a
+		}
+
+		return id, nil
+	}
+
+	d.lastAuthorID++
+	d.authors[d.lastAuthorID] = Author{AuthorID: d.lastAuthorID, Name: arg.Name, Bio: arg.Bio}
+
+	return d.lastAuthorID, nil
+}
+
+func (d *memData) UpsertBookPrice(_ context.Context, arg UpsertBookPriceParams) error {
+	if _, ok := d.books[arg.BookID]; !ok {
+		return fmt.Errorf("book %d does not exist", arg.BookID)
+	}
+
+	if d.prices[arg.BookID] == nil {
+		d.prices[arg.BookID] = map[string]string{}
+	}
+	d.prices[arg.BookID][arg.Currency] = arg.Amount
+
+	return nil
+}
+
+func versionMatches(b Book, expected sql.NullInt32) bool {
+	return !expected.Valid || b.Version == expected.Int32
+}
+
+// bookPrice is COALESCE(price, 0) of a NUMERIC column value
+func bookPrice(price sql.NullString) *big.Rat {
+	r, ok := new(big.Rat).SetString(price.String)
+	if !price.Valid || !ok {
+		return new(big.Rat)
+	}
+
+	return r
+}
+
+func nullRat(s sql.NullString) (*big.Rat, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+
+	r, ok := new(big.Rat).SetString(s.String)
+	if !ok {
+		return nil, errors.Errorf("invalid numeric value %q", s.String)
+	}
+
+	return r, nil
+}
+
+func compareInt32(a, b int32) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// ilikeRegexp translates ILIKE pattern with backslash escapes to regexp
+func ilikeRegexp(pattern string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("(?is)^")
+
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			sb.WriteString(".*")
+		case r == '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+
+	return regexp.Compile(sb.String())
+}
+
+func limitRows[T any](rows []T, limit int32) []T {
+	if limit >= 0 && len(rows) > int(limit) {
+		return rows[:limit]
+	}
+
+	return rows
+}
@@ -0,0 +1,181 @@
+package storagedb
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// weights of the search_vector parts as ts_rank_cd uses them
+const (
+	titleWeight       = 1.0
+	descriptionWeight = 0.4
+	authorWeight      = 0.2
+)
+
+// snippets are cut as ts_headline options of the query ask
+const snippetMaxWords = 30
+
+// SearchBooks approximates the full text search of postgres: query words
+// are AND-ed, a leading minus excludes a word and "or" is ignored. Words match
+// by prefix as there is no stemming, so "книг" finds "книга" but not the reverse
+func (d *memData) SearchBooks(_ context.Context, arg SearchBooksParams) ([]SearchBooksRow, error) {
+	include, exclude := parseSearchQuery(arg.Query)
+	if len(include) == 0 {
+		return nil, nil
+	}
+
+	var items []SearchBooksRow
+	for _, b := range d.books {
+		if b.DeletedAt.Valid {
+			continue
+		}
+
+		row := d.detail(b)
+
+		title := searchWords(row.Title)
+		description := searchWords(row.Description.String)
+		author := append(searchWords(row.Author), searchWords(row.AuthorBio.String)...)
+
+		var rank float32
+		matched := true
+		for _, term := range include {
+			hits := float32(countMatches(title, term))*titleWeight +
+				float32(countMatches(description, term))*descriptionWeight +
+				float32(countMatches(author, term))*authorWeight
+			if hits == 0 {
+				matched = false
+				break
+			}
+			rank += hits
+		}
+
+		for _, term := range exclude {
+			if countMatches(title, term)+countMatches(description, term)+countMatches(author, term) > 0 {
+				matched = false
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		items = append(items, SearchBooksRow{
+			BookID:         row.BookID,
+			Title:          row.Title,
+			AuthorID:       row.AuthorID,
+			Author:         row.Author,
+			Price:          row.Price,
+			Currency:       row.Currency,
+			Description:    row.Description,
+			AuthorBio:      row.AuthorBio,
+			Rank:           rank,
+			TitleHighlight: highlight(strings.Fields(row.Title), include),
+			Snippet:        snippet(row.Description.String+" "+row.AuthorBio.String, include),
+		})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Rank != items[j].Rank {
+			return items[i].Rank > items[j].Rank
+		}
+		return items[i].BookID < items[j].BookID
+	})
+
+	return limitRows(items, arg.ResultLimit), nil
+}
+
+func parseSearchQuery(query string) (include, exclude []string) {
+	for _, field := range strings.Fields(query) {
+		negated := strings.HasPrefix(field, "-")
+
+		words := searchWords(field)
+		if len(words) == 1 && words[0] == "or" {
+			continue
+		}
+
+		if negated {
+			exclude = append(exclude, words...)
+		} else {
+			include = append(include, words...)
+		}
+	}
+
+	return include, exclude
+}
+
+// searchWords splits s into lower case words of letters and digits
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func countMatches(words []string, term string) int {
+	n := 0
+	for _, w := range words {
+		if strings.HasPrefix(w, term) {
+			n++
+		}
+	}
+
+	return n
+}
+
+func matchesAny(field string, terms []string) bool {
+	for _, w := range searchWords(field) {
+		for _, term := range terms {
+			if strings.HasPrefix(w, term) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// highlightEscaper escapes text of highlights as the search query does
+// before ts_headline adds its <b> tags
+var highlightEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// highlight wraps fields matching terms in <b> tags as ts_headline does
+func highlight(fields []string, terms []string) string {
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		matched := matchesAny(f, terms)
+		f = highlightEscaper.Replace(f)
+		if matched {
+			f = "<b>" + f + "</b>"
+		}
+		out = append(out, f)
+	}
+
+	return strings.Join(out, " ")
+}
+
+// snippet is up to snippetMaxWords words of text around the first match
+func snippet(text string, terms []string) string {
+	fields := strings.Fields(text)
+
+	start := 0
+	for i, f := range fields {
+		if matchesAny(f, terms) {
+			start = i
+			break
+		}
+	}
+
+	// a few words of context before the match
+	start -= snippetMaxWords / 6
+	if start < 0 {
+		start = 0
+	}
+
+	end := start + snippetMaxWords
+	if end > len(fields) {
+		end = len(fields)
+	}
+
+	return highlight(fields[start:end], terms)
+}
@@ -0,0 +1,120 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMemoryRepositoryTx(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	errRollback := errors.New("rollback")
+
+	err := repo.InTx(ctx, func(tx Tx) error {
+		authorID, err := tx.UpsertAuthor(ctx, UpsertAuthorParams{Name: "Author"})
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.InsertBook(ctx, InsertBookParams{Title: "kept", AuthorID: authorID, Currency: "RUB"}); err != nil {
+			return err
+		}
+
+		// failed savepoint undoes only the second book
+		err = tx.Savepoint(ctx, func() error {
+			if _, err := tx.InsertBook(ctx, InsertBookParams{Title: "undone", AuthorID: authorID}); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !errors.Is(err, errRollback) {
+			t.Fatalf("Savepoint() error = %v, want %v", err, errRollback)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("InTx() error = %v", err)
+	}
+
+	err = repo.InTx(ctx, func(tx Tx) error {
+		if _, err := tx.InsertBook(ctx, InsertBookParams{Title: "rolled back", AuthorID: 1}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("InTx() error = %v, want %v", err, errRollback)
+	}
+
+	books, err := repo.ListBooks(ctx, ListBooksParams{SortBy: "id", PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListBooks() error = %v", err)
+	}
+
+	if len(books) != 1 || books[0].Title != "kept" || books[0].Author != "Author" {
+		t.Fatalf("ListBooks() = %+v, want only book kept", books)
+	}
+}
+
+func TestMemoryRepositoryListBooksPaging(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryRepository()
+
+	authorID, err := repo.UpsertAuthor(ctx, UpsertAuthorParams{Name: "Author"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, b := range []struct {
+		title string
+		price sql.NullString
+	}{
+		{"a", sql.NullString{String: "10.00", Valid: true}},
+		{"b", sql.NullString{String: "2.50", Valid: true}},
+		{"c", sql.NullString{}},
+		{"d", sql.NullString{String: "10.00", Valid: true}},
+	} {
+		if _, err := repo.InsertBook(ctx, InsertBookParams{Title: b.title, AuthorID: authorID, Price: b.price}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// price DESC, book_id DESC with books without price costing 0
+	want := []string{"d", "a", "b", "c"}
+
+	params := ListBooksParams{SortBy: "price", SortDesc: true, PageLimit: 3}
+
+	var got []string
+	for {
+		page, err := repo.ListBooks(ctx, params)
+		if err != nil {
+			t.Fatalf("ListBooks() error = %v", err)
+		}
+
+		for _, b := range page {
+			got = append(got, b.Title)
+		}
+
+		if len(page) < int(params.PageLimit) {
+			break
+		}
+
+		last := page[len(page)-1]
+		params.AfterID = sql.NullInt32{Int32: last.BookID, Valid: true}
+		params.AfterPrice = last.Price
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ListBooks() pages = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("ListBooks() pages = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/pkg/errors"
+	"github.com/s-vvardenfell/observer/storageservice/migrations"
+	"github.com/s-vvardenfell/observer/storageservice/seed"
+)
+
+// NilVersion is the schema version of a database no migration was applied to
+const NilVersion = -1
+
+// MigrationStatus is the schema version of a database
+type MigrationStatus struct {
+	// Version is the last applied migration or NilVersion
+	Version int64
+	// Dirty means the migration to Version failed half way
+	Dirty bool
+	// Latest is the version of the last migration known to the binary
+	Latest int64
+	// Pending are versions of migrations not applied yet
+	Pending []int64
+}
+
+// Migrator applies the embedded migrations with golang-migrate,
+// the version is kept in schema_migrations as the migrate CLI does
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+	db     *sql.DB
+	// seedDB is the connection Seed runs on, postgres migrations hold one
+	// for the life of the Migrator and the pool may not have another
+	seedDB interface {
+		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+	}
+	// ownsDB is set if Close has to close db
+	ownsDB bool
+}
+
+// NewMigrator connects to the postgres or sqlite:// database of connStr
+func NewMigrator(ctx context.Context, connStr string, opts DBOptions) (*Migrator, error) {
+	if IsSQLiteURL(connStr) {
+		db, err := openSQLite(connStr)
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := newSQLiteMigrator(db)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+		m.ownsDB = true
+
+		return m, nil
+	}
+
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to db with conn string %s", connStr)
+	}
+
+	if err := waitForDB(ctx, db, opts); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	m, err := newPostgresMigrator(ctx, db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	m.ownsDB = true
+
+	return m, nil
+}
+
+// newPostgresMigrator gives the driver a connection of its own,
+// closing it leaves db open for the repository
+func newPostgresMigrator(ctx context.Context, db *sql.DB) (*Migrator, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect for migration")
+	}
+
+	drv, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		_ = conn.Close()
+		return nil, errors.Wrap(err, "failed to prepare migration")
+	}
+
+	m, err := newMigrator(db, migrations.FS, "postgres", drv)
+	if err != nil {
+		return nil, err
+	}
+	m.seedDB = conn
+
+	return m, nil
+}
+
+func newSQLiteMigrator(db *sql.DB) (*Migrator, error) {
+	drv, err := newSQLiteMigrationDriver(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return newMigrator(db, migrations.SQLiteFS, "sqlite", drv)
+}
+
+func newMigrator(db *sql.DB, fsys fs.FS, name string, drv database.Driver) (*Migrator, error) {
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		_ = drv.Close()
+		return nil, errors.Wrap(err, "failed to read migrations")
+	}
+
+	m, err := migrate.NewWithInstance("iofs", src, name, drv)
+	if err != nil {
+		_ = src.Close()
+		_ = drv.Close()
+		return nil, errors.Wrap(err, "failed to prepare migration")
+	}
+
+	return &Migrator{m: m, source: src, db: db, seedDB: db}, nil
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	if m.ownsDB {
+		if err := m.db.Close(); err != nil {
+			return err
+		}
+	}
+
+	if srcErr != nil {
+		return srcErr
+	}
+	return dbErr
+}
+
+// Status reads the schema version
+func (m *Migrator) Status(_ context.Context) (MigrationStatus, error) {
+	st := MigrationStatus{Latest: NilVersion}
+
+	version, dirty, err := m.m.Version()
+	switch {
+	case errors.Is(err, migrate.ErrNilVersion):
+		st.Version = NilVersion
+	case err != nil:
+		return st, errors.Wrap(err, "failed to read schema version")
+	default:
+		st.Version, st.Dirty = int64(version), dirty
+	}
+
+	for v, err := m.source.First(); err == nil; v, err = m.source.Next(v) {
+		st.Latest = int64(v)
+		if st.Latest > st.Version {
+			st.Pending = append(st.Pending, st.Latest)
+		}
+	}
+
+	return st, nil
+}
+
+// Up applies n next migrations, all of them if n is not positive.
+// Nothing to apply is not an error
+func (m *Migrator) Up(ctx context.Context, n int) error {
+	return m.run(ctx, func() error {
+		if n > 0 {
+			return m.m.Steps(n)
+		}
+		return m.m.Up()
+	})
+}
+
+// Down reverts n last migrations, all of them if n is not positive
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.run(ctx, func() error {
+		if n > 0 {
+			return m.m.Steps(-n)
+		}
+		return m.m.Down()
+	})
+}
+
+// Force sets the version and clears the dirty flag without migrating,
+// it is for databases fixed by hand after a failed migration
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version < NilVersion {
+		return errors.Errorf("bad version %d", version)
+	}
+
+	return m.run(ctx, func() error { return m.m.Force(int(version)) })
+}
+
+// Seed puts the seed catalogue into an empty database,
+// books already there are left as they are
+func (m *Migrator) Seed(ctx context.Context) error {
+	entries, err := fs.ReadDir(seed.FS, ".")
+	if err != nil {
+		return errors.Wrap(err, "failed to read seed")
+	}
+
+	tx, err := m.seedDB.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var books int
+	if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM books`).Scan(&books); err != nil {
+		return errors.Wrap(err, "failed to count books")
+	}
+	if books > 0 {
+		return nil
+	}
+
+	for _, entry := range entries {
+		body, err := fs.ReadFile(seed.FS, entry.Name())
+		if err != nil {
+			return errors.Wrapf(err, "failed to read seed %s", entry.Name())
+		}
+
+		if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+			return errors.Wrapf(err, "seed %s failed", entry.Name())
+		}
+	}
+
+	return tx.Commit()
+}
+
+// run stops fn after the migration in progress once ctx is done,
+// having nothing to migrate is not an error
+func (m *Migrator) run(ctx context.Context, fn func() error) error {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			select {
+			case m.m.GracefulStop <- true:
+			default:
+			}
+		case <-done:
+		}
+	}()
+
+	err := fn()
+	if errors.Is(err, migrate.ErrNoChange) {
+		err = nil
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	return err
+}
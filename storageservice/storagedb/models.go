@@ -6,13 +6,53 @@ package storagedb
 
 import (
 	"database/sql"
+	"time"
 )
 
+type Author struct {
+	AuthorID     int32
+	Name         string
+	Bio          sql.NullString
+	SearchVector interface{}
+}
+
 type Book struct {
+	BookID       int32
+	Title        string
+	Price        sql.NullString
+	Description  sql.NullString
+	Version      int32
+	Currency     string
+	AuthorID     int32
+	SearchVector interface{}
+	DeletedAt    sql.NullTime
+}
+
+type BookDetail struct {
+	BookID             int32
+	Title              string
+	AuthorID           int32
+	Author             string
+	Price              sql.NullString
+	Currency           string
+	Description        sql.NullString
+	AuthorBio          sql.NullString
+	Version            int32
+	SearchVector       interface{}
+	AuthorSearchVector interface{}
+	DeletedAt          sql.NullTime
+}
+
+type BookPrice struct {
+	BookID   int32
+	Currency string
+	Amount   string
+}
+
+type IdempotencyKey struct {
+	Key         string
+	RequestHash string
 	BookID      int32
-	Title       string
-	Author      string
-	Price       sql.NullFloat64
-	Description sql.NullString
-	AuthorBio   sql.NullString
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
 }
@@ -0,0 +1,107 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DBOptions configure the connection pool of the postgres backend, how
+// the database is waited for on start and query tracing, zero values keep
+// the defaults. The sqlite backend always has a single connection
+type DBOptions struct {
+	// MaxOpenConns limits connections to the database, unlimited if zero
+	MaxOpenConns int
+	// MaxIdleConns is how many connections are kept open when idle, 2 if zero
+	MaxIdleConns int
+	// ConnMaxLifetime closes connections older than it, they are reused forever if zero
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes connections idle longer than it, never if zero
+	ConnMaxIdleTime time.Duration
+	// ConnectAttempts is how many times the database is pinged on start, DefaultConnectAttempts if zero
+	ConnectAttempts int
+	// ConnectBackoff is the wait after the first failed ping, doubled after
+	// every next one up to ConnectMaxBackoff, DefaultConnectBackoff if zero
+	ConnectBackoff time.Duration
+	// ConnectMaxBackoff is the longest wait between pings, DefaultConnectMaxBackoff if zero
+	ConnectMaxBackoff time.Duration
+	// TracerProvider gets a span for every statement, nothing is traced if nil
+	TracerProvider trace.TracerProvider
+	// TraceParams puts statement parameters into the spans, they are redacted if false
+	TraceParams bool
+}
+
+const (
+	DefaultConnectAttempts   = 5
+	DefaultConnectBackoff    = time.Second
+	DefaultConnectMaxBackoff = 15 * time.Second
+)
+
+func (opts DBOptions) withDefaults() DBOptions {
+	if opts.ConnectAttempts <= 0 {
+		opts.ConnectAttempts = DefaultConnectAttempts
+	}
+
+	if opts.ConnectBackoff <= 0 {
+		opts.ConnectBackoff = DefaultConnectBackoff
+	}
+
+	if opts.ConnectMaxBackoff <= 0 {
+		opts.ConnectMaxBackoff = DefaultConnectMaxBackoff
+	}
+
+	return opts
+}
+
+// configurePool applies the pool limits of opts to db
+func configurePool(db *sql.DB, opts DBOptions) {
+	if opts.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(opts.MaxOpenConns)
+	}
+
+	if opts.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(opts.MaxIdleConns)
+	}
+
+	if opts.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	}
+
+	if opts.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	}
+}
+
+// waitForDB pings db until it answers, waiting longer after every failure.
+// It gives up after opts.ConnectAttempts pings or when ctx is done
+func waitForDB(ctx context.Context, db *sql.DB, opts DBOptions) error {
+	opts = opts.withDefaults()
+	backoff := opts.ConnectBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = db.PingContext(ctx); err == nil {
+			return nil
+		}
+
+		if attempt == opts.ConnectAttempts {
+			return errors.Wrapf(err, "ping failed %d times", attempt)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Wrap(ctx.Err(), "gave up waiting for db")
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > opts.ConnectMaxBackoff {
+			backoff = opts.ConnectMaxBackoff
+		}
+	}
+}
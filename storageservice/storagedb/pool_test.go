@@ -0,0 +1,52 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var errDown = errors.New("database is down")
+
+// downConnector fails every connection attempt
+type downConnector struct {
+	attempts int
+}
+
+func (c *downConnector) Connect(context.Context) (driver.Conn, error) {
+	c.attempts++
+	return nil, errDown
+}
+
+func (c *downConnector) Driver() driver.Driver {
+	return nil
+}
+
+func TestWaitForDB(t *testing.T) {
+	conn := &downConnector{}
+	db := sql.OpenDB(conn)
+	defer db.Close()
+
+	opts := DBOptions{ConnectAttempts: 3, ConnectBackoff: time.Millisecond}
+	err := waitForDB(context.Background(), db, opts)
+	if !errors.Is(err, errDown) {
+		t.Fatalf("waitForDB() error = %v, want %v", err, errDown)
+	}
+
+	// database/sql retries a failed connection once more itself
+	if conn.attempts < 3 || conn.attempts > 6 {
+		t.Fatalf("waitForDB() connected %d times, want 3 pings", conn.attempts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	opts = DBOptions{ConnectAttempts: 3, ConnectBackoff: time.Hour}
+	if err := waitForDB(ctx, db, opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("waitForDB() with canceled context error = %v, want %v", err, context.Canceled)
+	}
+}
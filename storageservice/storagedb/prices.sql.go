@@ -0,0 +1,97 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.2
+// source: prices.sql
+
+package storagedb
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const deleteBookPrice = `-- name: DeleteBookPrice :exec
+DELETE FROM book_prices WHERE book_id = $1 AND currency = $2
+`
+
+type DeleteBookPriceParams struct {
+	BookID   int32
+	Currency string
+}
+
+func (q *Queries) DeleteBookPrice(ctx context.Context, arg DeleteBookPriceParams) error {
+	_, err := q.db.ExecContext(ctx, deleteBookPrice, arg.BookID, arg.Currency)
+	return err
+}
+
+const deleteOtherBookPrices = `-- name: DeleteOtherBookPrices :exec
+DELETE FROM
+    book_prices
+WHERE
+    book_id = $1
+    AND NOT (currency = ANY($2::text[]))
+`
+
+type DeleteOtherBookPricesParams struct {
+	BookID int32
+	Keep   []string
+}
+
+func (q *Queries) DeleteOtherBookPrices(ctx context.Context, arg DeleteOtherBookPricesParams) error {
+	_, err := q.db.ExecContext(ctx, deleteOtherBookPrices, arg.BookID, pq.Array(arg.Keep))
+	return err
+}
+
+const listBookPrices = `-- name: ListBookPrices :many
+SELECT
+    book_id, currency, amount
+FROM
+    book_prices
+WHERE
+    book_id = ANY($1::int[])
+ORDER BY
+    book_id,
+    currency
+`
+
+func (q *Queries) ListBookPrices(ctx context.Context, bookIds []int32) ([]BookPrice, error) {
+	rows, err := q.db.QueryContext(ctx, listBookPrices, pq.Array(bookIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookPrice
+	for rows.Next() {
+		var i BookPrice
+		if err := rows.Scan(&i.BookID, &i.Currency, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertBookPrice = `-- name: UpsertBookPrice :exec
+INSERT INTO book_prices (book_id, currency, amount)
+VALUES ($1, $2, $3)
+ON CONFLICT (book_id, currency) DO UPDATE SET
+    amount = EXCLUDED.amount
+`
+
+type UpsertBookPriceParams struct {
+	BookID   int32
+	Currency string
+	Amount   string
+}
+
+func (q *Queries) UpsertBookPrice(ctx context.Context, arg UpsertBookPriceParams) error {
+	_, err := q.db.ExecContext(ctx, upsertBookPrice, arg.BookID, arg.Currency, arg.Amount)
+	return err
+}
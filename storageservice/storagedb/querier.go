@@ -0,0 +1,37 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.17.2
+
+package storagedb
+
+import (
+	"context"
+)
+
+type Querier interface {
+	// books go to trash first, PurgeDeletedBooks removes them for good
+	DeleteBook(ctx context.Context, arg DeleteBookParams) (int64, error)
+	DeleteBookPrice(ctx context.Context, arg DeleteBookPriceParams) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteOtherBookPrices(ctx context.Context, arg DeleteOtherBookPricesParams) error
+	GetAuthorById(ctx context.Context, authorID int32) (Author, error)
+	GetBookById(ctx context.Context, bookID int32) (BookDetail, error)
+	GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error)
+	InsertBook(ctx context.Context, arg InsertBookParams) (int32, error)
+	ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Author, error)
+	ListBookPrices(ctx context.Context, bookIds []int32) ([]BookPrice, error)
+	ListBooks(ctx context.Context, arg ListBooksParams) ([]BookDetail, error)
+	ListDeletedBooks(ctx context.Context, arg ListDeletedBooksParams) ([]BookDetail, error)
+	LockIdempotencyKey(ctx context.Context, key string) error
+	PurgeDeletedBooks(ctx context.Context, retentionSeconds int32) (int64, error)
+	RestoreBook(ctx context.Context, bookID int32) (int64, error)
+	SaveIdempotencyKey(ctx context.Context, arg SaveIdempotencyKeyParams) error
+	SearchBooks(ctx context.Context, arg SearchBooksParams) ([]SearchBooksRow, error)
+	UpdateAuthorBio(ctx context.Context, arg UpdateAuthorBioParams) error
+	UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, error)
+	// an existing author keeps the bio, it is only filled in if empty
+	UpsertAuthor(ctx context.Context, arg UpsertAuthorParams) (int32, error)
+	UpsertBookPrice(ctx context.Context, arg UpsertBookPriceParams) error
+}
+
+var _ Querier = (*Queries)(nil)
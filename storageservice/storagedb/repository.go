@@ -0,0 +1,21 @@
+package storagedb
+
+import "context"
+
+// BookRepository is the storage of books, authors, prices and idempotency keys,
+// StorageDbHandler keeps them in postgres, SQLiteRepository in an sqlite file
+// and MemoryRepository in process memory
+type BookRepository interface {
+	Querier
+	// InTx runs fn in a transaction which is committed if fn returns nil
+	InTx(ctx context.Context, fn func(tx Tx) error) error
+	Close() error
+}
+
+// Tx is BookRepository queries bound to a transaction
+type Tx interface {
+	Querier
+	// Savepoint runs fn so that only changes made by fn are undone when it fails,
+	// the error of fn is returned and the transaction can go on
+	Savepoint(ctx context.Context, fn func() error) error
+}
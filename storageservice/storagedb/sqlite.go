@@ -0,0 +1,192 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/ncruces/go-sqlite3"
+	_ "github.com/ncruces/go-sqlite3/driver"
+	_ "github.com/ncruces/go-sqlite3/embed"
+	"github.com/ncruces/go-sqlite3/ext/unicode"
+	"github.com/pkg/errors"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+const sqliteScheme = "sqlite://"
+
+// IsSQLiteURL tells whether connStr is for the sqlite backend,
+// e.g. sqlite://books.db, sqlite:///var/lib/books.db or sqlite://:memory:
+func IsSQLiteURL(connStr string) bool {
+	return strings.HasPrefix(connStr, sqliteScheme)
+}
+
+// SQLiteRepository is BookRepository kept in an sqlite database file
+type SQLiteRepository struct {
+	countingQuerier
+	db     *sql.DB
+	traced *tracedDBTX
+}
+
+var _ BookRepository = (*SQLiteRepository)(nil)
+
+// NewSQLiteRepository opens the database of connStr, only the tracing of opts is used.
+// The schema is not migrated, see Migrator
+func NewSQLiteRepository(ctx context.Context, connStr string, opts DBOptions) (*SQLiteRepository, error) {
+	db, err := openSQLite(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the file is created on the first connection, bad paths fail here
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrapf(err, "failed to open sqlite database %s", connStr)
+	}
+
+	traced := newTracedDBTX(db, semconv.DBSystemSqlite, opts)
+
+	return &SQLiteRepository{
+		countingQuerier: countingQuerier{&sqliteQueries{db: traced}},
+		db:              db,
+		traced:          traced,
+	}, nil
+}
+
+func openSQLite(connStr string) (*sql.DB, error) {
+	dsn, err := sqliteDSN(connStr)
+	if err != nil {
+		return nil, err
+	}
+
+	drv, err := sqliteDriver()
+	if err != nil {
+		return nil, err
+	}
+
+	db := sql.OpenDB(&sqliteConnector{driver: drv, dsn: dsn})
+	// sqlite has a single writer anyway, one connection also keeps
+	// transactions from failing with SQLITE_BUSY and :memory: databases whole
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+// InTx runs fn in a transaction which is committed if fn returns nil
+func (repo *SQLiteRepository) InTx(ctx context.Context, fn func(tx Tx) error) error {
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	traced := repo.traced.withTx(tx)
+	if err := fn(&sqliteTx{countingQuerier: countingQuerier{&sqliteQueries{db: traced}}, tx: traced}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Stats are the connection pool statistics
+func (repo *SQLiteRepository) Stats() sql.DBStats {
+	return repo.db.Stats()
+}
+
+// Migrator migrates the schema of the repository database
+func (repo *SQLiteRepository) Migrator() (*Migrator, error) {
+	return newSQLiteMigrator(repo.db)
+}
+
+func (repo *SQLiteRepository) Close() error {
+	return repo.db.Close()
+}
+
+type sqliteTx struct {
+	countingQuerier
+	tx DBTX
+}
+
+func (t *sqliteTx) Savepoint(ctx context.Context, fn func() error) error {
+	return savepoint(ctx, t.tx, fn)
+}
+
+// sqliteDSN turns sqlite://path?params into the file: URI of the driver,
+// foreign keys are always on and writers wait for each other
+func sqliteDSN(connStr string) (string, error) {
+	path, query, _ := strings.Cut(strings.TrimPrefix(connStr, sqliteScheme), "?")
+	if path == "" {
+		return "", errors.Errorf("no database file in sqlite conn string %s", connStr)
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", errors.Wrapf(err, "bad sqlite conn string %s", connStr)
+	}
+
+	// busy_timeout goes first so that the following pragmas wait for locks too
+	params["_pragma"] = append([]string{"busy_timeout(10000)", "foreign_keys(1)"}, params["_pragma"]...)
+
+	return "file:" + path + "?" + params.Encode(), nil
+}
+
+func sqliteDriver() (driver.Driver, error) {
+	// sql.Open does not connect, it is the way to reach the registered driver
+	db, err := sql.Open("sqlite3", "")
+	if err != nil {
+		return nil, errors.Wrap(err, "no sqlite driver")
+	}
+	defer db.Close()
+
+	return db.Driver(), nil
+}
+
+// sqliteConnector opens connections with unicode aware like, lower and upper,
+// the built in ones fold ASCII letters only and titles are mostly in russian
+type sqliteConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c *sqliteConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := rawSQLiteConn(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	unicode.Register(raw)
+
+	return conn, nil
+}
+
+func (c *sqliteConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// rawSQLiteConn gets the connection embedded in the one of the driver,
+// which has no other way to register functions
+func rawSQLiteConn(conn driver.Conn) (*sqlite3.Conn, error) {
+	v := reflect.ValueOf(conn)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName("Conn"); f.IsValid() && f.CanInterface() {
+			if raw, ok := f.Interface().(*sqlite3.Conn); ok {
+				return raw, nil
+			}
+		}
+	}
+
+	return nil, errors.Errorf("unexpected sqlite driver connection %T", conn)
+}
@@ -0,0 +1,137 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"sync/atomic"
+
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/pkg/errors"
+)
+
+// sqliteMigrationDriver is the golang-migrate driver of the ncruces sqlite
+// connection, the sqlite drivers of golang-migrate bring their own engines
+type sqliteMigrationDriver struct {
+	db     *sql.DB
+	locked atomic.Bool
+}
+
+var _ database.Driver = (*sqliteMigrationDriver)(nil)
+
+func newSQLiteMigrationDriver(db *sql.DB) (*sqliteMigrationDriver, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create schema_migrations")
+	}
+
+	return &sqliteMigrationDriver{db: db}, nil
+}
+
+func (d *sqliteMigrationDriver) Open(string) (database.Driver, error) {
+	return nil, errors.New("sqlite migration driver is made from a connection")
+}
+
+// Close leaves the connection open, it belongs to the repository
+func (d *sqliteMigrationDriver) Close() error {
+	return nil
+}
+
+// Lock only guards the migrator, sqlite itself allows a single writer
+func (d *sqliteMigrationDriver) Lock() error {
+	if !d.locked.CompareAndSwap(false, true) {
+		return database.ErrLocked
+	}
+	return nil
+}
+
+func (d *sqliteMigrationDriver) Unlock() error {
+	if !d.locked.CompareAndSwap(true, false) {
+		return database.ErrNotLocked
+	}
+	return nil
+}
+
+// Run applies a migration in a transaction. Some migrations rebuild
+// tables, so foreign keys are checked once it is done instead
+func (d *sqliteMigrationDriver) Run(migration io.Reader) error {
+	body, err := io.ReadAll(migration)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to connect for migration")
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return err
+	}
+	defer conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+		return database.Error{OrigErr: err, Err: "migration failed", Query: body}
+	}
+
+	rows, err := tx.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return err
+	}
+	violated := rows.Next()
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if violated {
+		return errors.New("foreign key constraint failed")
+	}
+
+	return tx.Commit()
+}
+
+// SetVersion keeps a single row as the golang-migrate drivers do
+func (d *sqliteMigrationDriver) SetVersion(version int, dirty bool) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM schema_migrations`); err != nil {
+		return errors.Wrap(err, "failed to set schema version")
+	}
+
+	if version >= 0 || (version == database.NilVersion && dirty) {
+		if _, err := tx.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (?, ?)`, version, dirty); err != nil {
+			return errors.Wrap(err, "failed to set schema version")
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (d *sqliteMigrationDriver) Version() (int, bool, error) {
+	var version int
+	var dirty bool
+	err := d.db.QueryRow(`SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.NilVersion, false, nil
+	}
+	if err != nil {
+		return 0, false, errors.Wrap(err, "failed to read schema version")
+	}
+
+	return version, dirty, nil
+}
+
+func (d *sqliteMigrationDriver) Drop() error {
+	return errors.New("dropping the sqlite database is not supported")
+}
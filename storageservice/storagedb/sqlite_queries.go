@@ -0,0 +1,577 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// sqliteQueries are the queries of sqlc translated to sqlite, kept in the
+// layout of the generated code. Timestamps are passed in as RFC 3339 text
+// and compared with julianday(), prices are text compared as reals and
+// the full text search is on the fts5 table book_search
+type sqliteQueries struct {
+	db DBTX
+}
+
+var _ Querier = (*sqliteQueries)(nil)
+
+func sqliteNow() time.Time {
+	return time.Now().UTC()
+}
+
+const sqliteBookDetailColumns = `book_id, title, author_id, author, price, currency, description, author_bio, version, deleted_at`
+
+func scanSQLiteBookDetails(rows *sql.Rows, err error) ([]BookDetail, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookDetail
+	for rows.Next() {
+		var i BookDetail
+		if err := rows.Scan(
+			&i.BookID,
+			&i.Title,
+			&i.AuthorID,
+			&i.Author,
+			&i.Price,
+			&i.Currency,
+			&i.Description,
+			&i.AuthorBio,
+			&i.Version,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sqliteGetAuthorById = `-- name: GetAuthorById :one
+SELECT author_id, name, bio FROM authors WHERE author_id = ?1
+`
+
+func (q *sqliteQueries) GetAuthorById(ctx context.Context, authorID int32) (Author, error) {
+	row := q.db.QueryRowContext(ctx, sqliteGetAuthorById, authorID)
+	var i Author
+	err := row.Scan(&i.AuthorID, &i.Name, &i.Bio)
+	return i, err
+}
+
+const sqliteListAuthors = `-- name: ListAuthors :many
+SELECT
+    author_id, name, bio
+FROM
+    authors
+WHERE
+    (?1 IS NULL OR name LIKE ?1 ESCAPE '\')
+    AND (?2 IS NULL OR author_id > ?2)
+ORDER BY
+    author_id
+LIMIT
+    ?3
+`
+
+func (q *sqliteQueries) ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Author, error) {
+	rows, err := q.db.QueryContext(ctx, sqliteListAuthors, arg.NamePattern, arg.AfterID, arg.PageLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Author
+	for rows.Next() {
+		var i Author
+		if err := rows.Scan(&i.AuthorID, &i.Name, &i.Bio); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sqliteUpsertAuthor = `-- name: UpsertAuthor :one
+INSERT INTO authors (name, bio)
+VALUES (?1, ?2)
+ON CONFLICT (name) DO UPDATE SET
+    bio = COALESCE(NULLIF(authors.bio, ''), excluded.bio)
+RETURNING author_id
+`
+
+func (q *sqliteQueries) UpsertAuthor(ctx context.Context, arg UpsertAuthorParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, sqliteUpsertAuthor, arg.Name, arg.Bio)
+	var author_id int32
+	err := row.Scan(&author_id)
+	return author_id, err
+}
+
+const sqliteUpdateAuthorBio = `-- name: UpdateAuthorBio :exec
+UPDATE authors SET bio = ?2 WHERE author_id = ?1
+`
+
+func (q *sqliteQueries) UpdateAuthorBio(ctx context.Context, arg UpdateAuthorBioParams) error {
+	_, err := q.db.ExecContext(ctx, sqliteUpdateAuthorBio, arg.AuthorID, arg.Bio)
+	return err
+}
+
+const sqliteGetBookById = `-- name: GetBookById :one
+SELECT
+    ` + sqliteBookDetailColumns + `
+FROM
+    book_details
+WHERE
+    book_id = ?1
+    AND deleted_at IS NULL
+`
+
+func (q *sqliteQueries) GetBookById(ctx context.Context, bookID int32) (BookDetail, error) {
+	items, err := scanSQLiteBookDetails(q.db.QueryContext(ctx, sqliteGetBookById, bookID))
+	if err != nil {
+		return BookDetail{}, err
+	}
+	if len(items) == 0 {
+		return BookDetail{}, sql.ErrNoRows
+	}
+	return items[0], nil
+}
+
+const sqliteInsertBook = `-- name: InsertBook :one
+INSERT INTO
+    books(title, author_id, price, currency, description)
+VALUES
+    (?1, ?2, ?3, ?4, ?5) RETURNING book_id
+`
+
+func (q *sqliteQueries) InsertBook(ctx context.Context, arg InsertBookParams) (int32, error) {
+	row := q.db.QueryRowContext(ctx, sqliteInsertBook,
+		arg.Title,
+		arg.AuthorID,
+		arg.Price,
+		arg.Currency,
+		arg.Description,
+	)
+	var book_id int32
+	err := row.Scan(&book_id)
+	return book_id, err
+}
+
+const sqliteUpdateBook = `-- name: UpdateBook :one
+UPDATE
+    books
+SET
+    title = COALESCE(?1, title),
+    author_id = COALESCE(?2, author_id),
+    price = COALESCE(?3, price),
+    currency = COALESCE(?4, currency),
+    description = COALESCE(?5, description),
+    version = version + 1
+WHERE
+    book_id = ?6
+    AND deleted_at IS NULL
+    AND (?7 IS NULL OR version = ?7) RETURNING book_id, title, price, description, version, currency, author_id, deleted_at
+`
+
+func (q *sqliteQueries) UpdateBook(ctx context.Context, arg UpdateBookParams) (Book, error) {
+	row := q.db.QueryRowContext(ctx, sqliteUpdateBook,
+		arg.Title,
+		arg.AuthorID,
+		arg.Price,
+		arg.Currency,
+		arg.Description,
+		arg.BookID,
+		arg.ExpectedVersion,
+	)
+	var i Book
+	err := row.Scan(
+		&i.BookID,
+		&i.Title,
+		&i.Price,
+		&i.Description,
+		&i.Version,
+		&i.Currency,
+		&i.AuthorID,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const sqliteDeleteBook = `-- name: DeleteBook :execrows
+UPDATE
+    books
+SET
+    deleted_at = ?3,
+    version = version + 1
+WHERE
+    book_id = ?1
+    AND deleted_at IS NULL
+    AND (?2 IS NULL OR version = ?2)
+`
+
+func (q *sqliteQueries) DeleteBook(ctx context.Context, arg DeleteBookParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, sqliteDeleteBook, arg.BookID, arg.ExpectedVersion, sqliteNow())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const sqliteListBooks = `-- name: ListBooks :many
+SELECT
+    ` + sqliteBookDetailColumns + `
+FROM
+    book_details
+WHERE
+    deleted_at IS NULL
+    AND (?1 IS NULL OR author = ?1)
+    AND (?2 IS NULL OR author_id = ?2)
+    AND (?3 IS NULL OR title LIKE ?3 ESCAPE '\')
+    AND (?4 IS NULL OR CAST(COALESCE(price, 0) AS REAL) >= CAST(?4 AS REAL))
+    AND (?5 IS NULL OR CAST(COALESCE(price, 0) AS REAL) <= CAST(?5 AS REAL))
+    AND (
+        ?6 IS NULL
+        OR (?7 = 'id' AND NOT ?8 AND book_id > ?6)
+        OR (?7 = 'id' AND ?8 AND book_id < ?6)
+        OR (?7 = 'title' AND NOT ?8
+            AND (title, book_id) > (?9, ?6))
+        OR (?7 = 'title' AND ?8
+            AND (title, book_id) < (?9, ?6))
+        OR (?7 = 'price' AND NOT ?8
+            AND (CAST(COALESCE(price, 0) AS REAL), book_id) > (CAST(COALESCE(?10, 0) AS REAL), ?6))
+        OR (?7 = 'price' AND ?8
+            AND (CAST(COALESCE(price, 0) AS REAL), book_id) < (CAST(COALESCE(?10, 0) AS REAL), ?6))
+    )
+ORDER BY
+    CASE WHEN ?7 = 'title' AND NOT ?8 THEN title END ASC,
+    CASE WHEN ?7 = 'title' AND ?8 THEN title END DESC,
+    CASE WHEN ?7 = 'price' AND NOT ?8 THEN CAST(COALESCE(price, 0) AS REAL) END ASC,
+    CASE WHEN ?7 = 'price' AND ?8 THEN CAST(COALESCE(price, 0) AS REAL) END DESC,
+    CASE WHEN NOT ?8 THEN book_id END ASC,
+    CASE WHEN ?8 THEN book_id END DESC
+LIMIT
+    ?11
+`
+
+func (q *sqliteQueries) ListBooks(ctx context.Context, arg ListBooksParams) ([]BookDetail, error) {
+	return scanSQLiteBookDetails(q.db.QueryContext(ctx, sqliteListBooks,
+		arg.Author,
+		arg.AuthorID,
+		arg.TitlePattern,
+		arg.MinPrice,
+		arg.MaxPrice,
+		arg.AfterID,
+		arg.SortBy,
+		arg.SortDesc,
+		arg.AfterTitle,
+		arg.AfterPrice,
+		arg.PageLimit,
+	))
+}
+
+const sqliteSearchBooks = `-- name: SearchBooks :many
+SELECT
+    book_details.book_id,
+    book_details.title,
+    book_details.author_id,
+    book_details.author,
+    book_details.price,
+    book_details.currency,
+    book_details.description,
+    book_details.author_bio,
+    -bm25(book_search, 1.0, 0.4, 0.2) AS rank,
+    highlight(book_search, 0, char(2), char(3)) AS title_highlight,
+    snippet(book_search, -1, char(2), char(3), '...', 30) AS snippet
+FROM
+    book_search
+    JOIN book_details ON book_details.book_id = book_search.rowid
+WHERE
+    book_search MATCH ?1
+    AND book_details.deleted_at IS NULL
+ORDER BY
+    rank DESC,
+    book_details.book_id
+LIMIT
+    ?2
+`
+
+// SearchBooks takes the query as the memory backend does: words are AND-ed,
+// a leading minus excludes a word and "or" is ignored. Words match by prefix
+// as there is no stemming
+func (q *sqliteQueries) SearchBooks(ctx context.Context, arg SearchBooksParams) ([]SearchBooksRow, error) {
+	match := sqliteMatchQuery(arg.Query)
+	if match == "" {
+		return nil, nil
+	}
+
+	rows, err := q.db.QueryContext(ctx, sqliteSearchBooks, match, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchBooksRow
+	for rows.Next() {
+		var i SearchBooksRow
+		if err := rows.Scan(
+			&i.BookID,
+			&i.Title,
+			&i.AuthorID,
+			&i.Author,
+			&i.Price,
+			&i.Currency,
+			&i.Description,
+			&i.AuthorBio,
+			&i.Rank,
+			&i.TitleHighlight,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		i.TitleHighlight = sqliteHighlight(i.TitleHighlight)
+		i.Snippet = sqliteHighlight(i.Snippet)
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// fts5 marks matches with these characters instead of tags so that
+// the text around them can be escaped
+var sqliteHighlightTags = strings.NewReplacer("\x02", "<b>", "\x03", "</b>")
+
+// sqliteHighlight turns fts5 highlight into the HTML ts_headline returns
+func sqliteHighlight(s string) string {
+	return sqliteHighlightTags.Replace(highlightEscaper.Replace(s))
+}
+
+// sqliteMatchQuery is the fts5 query of prefix terms, e.g. "книг"* NOT "кинг"*
+func sqliteMatchQuery(query string) string {
+	include, exclude := parseSearchQuery(query)
+	if len(include) == 0 {
+		return ""
+	}
+
+	terms := make([]string, 0, len(include))
+	for _, term := range include {
+		terms = append(terms, `"`+term+`"*`)
+	}
+
+	match := strings.Join(terms, " AND ")
+	for _, term := range exclude {
+		match += ` NOT "` + term + `"*`
+	}
+
+	return match
+}
+
+const sqliteRestoreBook = `-- name: RestoreBook :execrows
+UPDATE
+    books
+SET
+    deleted_at = NULL,
+    version = version + 1
+WHERE
+    book_id = ?1
+    AND deleted_at IS NOT NULL
+`
+
+func (q *sqliteQueries) RestoreBook(ctx context.Context, bookID int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, sqliteRestoreBook, bookID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const sqliteListDeletedBooks = `-- name: ListDeletedBooks :many
+SELECT
+    ` + sqliteBookDetailColumns + `
+FROM
+    book_details
+WHERE
+    deleted_at IS NOT NULL
+    AND (?1 IS NULL OR book_id > ?1)
+ORDER BY
+    book_id
+LIMIT
+    ?2
+`
+
+func (q *sqliteQueries) ListDeletedBooks(ctx context.Context, arg ListDeletedBooksParams) ([]BookDetail, error) {
+	return scanSQLiteBookDetails(q.db.QueryContext(ctx, sqliteListDeletedBooks, arg.AfterID, arg.PageLimit))
+}
+
+const sqlitePurgeDeletedBooks = `-- name: PurgeDeletedBooks :execrows
+DELETE FROM
+    books
+WHERE
+    julianday(deleted_at) < julianday(?1)
+`
+
+func (q *sqliteQueries) PurgeDeletedBooks(ctx context.Context, retentionSeconds int32) (int64, error) {
+	deletedBefore := sqliteNow().Add(-time.Duration(retentionSeconds) * time.Second)
+	result, err := q.db.ExecContext(ctx, sqlitePurgeDeletedBooks, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// LockIdempotencyKey does nothing, the repository has a single connection
+// so its transactions do not overlap
+func (q *sqliteQueries) LockIdempotencyKey(context.Context, string) error {
+	return nil
+}
+
+const sqliteGetIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT
+    key, request_hash, book_id, created_at, expires_at
+FROM
+    idempotency_keys
+WHERE
+    key = ?1 AND julianday(expires_at) > julianday(?2)
+`
+
+func (q *sqliteQueries) GetIdempotencyKey(ctx context.Context, key string) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, sqliteGetIdempotencyKey, key, sqliteNow())
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.Key,
+		&i.RequestHash,
+		&i.BookID,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const sqliteSaveIdempotencyKey = `-- name: SaveIdempotencyKey :exec
+INSERT INTO idempotency_keys (key, request_hash, book_id, created_at, expires_at)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT (key) DO UPDATE SET
+    request_hash = excluded.request_hash,
+    book_id = excluded.book_id,
+    created_at = excluded.created_at,
+    expires_at = excluded.expires_at
+`
+
+func (q *sqliteQueries) SaveIdempotencyKey(ctx context.Context, arg SaveIdempotencyKeyParams) error {
+	now := sqliteNow()
+	_, err := q.db.ExecContext(ctx, sqliteSaveIdempotencyKey,
+		arg.Key,
+		arg.RequestHash,
+		arg.BookID,
+		now,
+		now.Add(time.Duration(arg.TtlSeconds)*time.Second),
+	)
+	return err
+}
+
+const sqliteDeleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE julianday(expires_at) <= julianday(?1)
+`
+
+func (q *sqliteQueries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, sqliteDeleteExpiredIdempotencyKeys, sqliteNow())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const sqliteListBookPrices = `-- name: ListBookPrices :many
+SELECT
+    book_id, currency, amount
+FROM
+    book_prices
+WHERE
+    book_id IN (SELECT value FROM json_each(?1))
+ORDER BY
+    book_id,
+    currency
+`
+
+func (q *sqliteQueries) ListBookPrices(ctx context.Context, bookIds []int32) ([]BookPrice, error) {
+	ids, err := json.Marshal(bookIds)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.db.QueryContext(ctx, sqliteListBookPrices, string(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BookPrice
+	for rows.Next() {
+		var i BookPrice
+		if err := rows.Scan(&i.BookID, &i.Currency, &i.Amount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sqliteUpsertBookPrice = `-- name: UpsertBookPrice :exec
+INSERT INTO book_prices (book_id, currency, amount)
+VALUES (?1, ?2, ?3)
+ON CONFLICT (book_id, currency) DO UPDATE SET
+    amount = excluded.amount
+`
+
+func (q *sqliteQueries) UpsertBookPrice(ctx context.Context, arg UpsertBookPriceParams) error {
+	_, err := q.db.ExecContext(ctx, sqliteUpsertBookPrice, arg.BookID, arg.Currency, arg.Amount)
+	return err
+}
+
+const sqliteDeleteBookPrice = `-- name: DeleteBookPrice :exec
+DELETE FROM book_prices WHERE book_id = ?1 AND currency = ?2
+`
+
+func (q *sqliteQueries) DeleteBookPrice(ctx context.Context, arg DeleteBookPriceParams) error {
+	_, err := q.db.ExecContext(ctx, sqliteDeleteBookPrice, arg.BookID, arg.Currency)
+	return err
+}
+
+const sqliteDeleteOtherBookPrices = `-- name: DeleteOtherBookPrices :exec
+DELETE FROM
+    book_prices
+WHERE
+    book_id = ?1
+    AND currency NOT IN (SELECT value FROM json_each(?2))
+`
+
+func (q *sqliteQueries) DeleteOtherBookPrices(ctx context.Context, arg DeleteOtherBookPricesParams) error {
+	keep, err := json.Marshal(arg.Keep)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.db.ExecContext(ctx, sqliteDeleteOtherBookPrices, arg.BookID, string(keep))
+	return err
+}
@@ -0,0 +1,201 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newSQLiteRepository(t *testing.T) *SQLiteRepository {
+	t.Helper()
+
+	repo, err := NewSQLiteRepository(context.Background(), "sqlite://"+filepath.Join(t.TempDir(), "books.db"), DBOptions{})
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	t.Cleanup(func() { _ = repo.Close() })
+
+	migrateSQLite(t, repo)
+
+	return repo
+}
+
+func migrateSQLite(t *testing.T, repo *SQLiteRepository) *Migrator {
+	t.Helper()
+
+	m, err := repo.Migrator()
+	if err != nil {
+		t.Fatalf("Migrator() error = %v", err)
+	}
+
+	if err := m.Up(context.Background(), 0); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+
+	return m
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepository(t)
+
+	m := migrateSQLite(t, repo)
+
+	// migrations leave the catalogue to Seed
+	var count int
+	if err := repo.db.QueryRowContext(ctx, `SELECT count(*) FROM books`).Scan(&count); err != nil || count != 0 {
+		t.Fatalf("books after Up() = %d, %v, want none", count, err)
+	}
+
+	if err := m.Seed(ctx); err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+	// seeding again keeps the catalogue as it is
+	if err := m.Seed(ctx); err != nil {
+		t.Fatalf("Seed() again error = %v", err)
+	}
+
+	// the seed catalogue is searchable, ignoring case of cyrillic words
+	found, err := repo.SearchBooks(ctx, SearchBooksParams{Query: "стивен -бойцовский", ResultLimit: 10})
+	if err != nil {
+		t.Fatalf("SearchBooks() error = %v", err)
+	}
+	if len(found) == 0 || found[0].Author != "Стивен Кинг" {
+		t.Fatalf("SearchBooks() = %+v, want books of Стивен Кинг", found)
+	}
+
+	if err := m.Down(ctx, 0); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+
+	st, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if st.Version != NilVersion || st.Dirty || len(st.Pending) == 0 || st.Pending[len(st.Pending)-1] != st.Latest {
+		t.Fatalf("Status() after Down() = %+v, want every migration pending", st)
+	}
+
+	if err := m.Up(ctx, 0); err != nil {
+		t.Fatalf("Up() after Down() error = %v", err)
+	}
+	if err := m.Seed(ctx); err != nil {
+		t.Fatalf("Seed() after Down() error = %v", err)
+	}
+
+	books, err := repo.ListBooks(ctx, ListBooksParams{TitlePattern: sql.NullString{String: "%БАШНЯ%", Valid: true}, SortBy: "id", PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListBooks() error = %v", err)
+	}
+	if len(books) != 1 || books[0].Price.String != "680.00" {
+		t.Fatalf("ListBooks() = %+v, want Темная башня for 680.00", books)
+	}
+}
+
+func TestSQLiteRepositoryTrash(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteRepository(t)
+
+	var bookID int32
+	err := repo.InTx(ctx, func(tx Tx) error {
+		authorID, err := tx.UpsertAuthor(ctx, UpsertAuthorParams{Name: "Author"})
+		if err != nil {
+			return err
+		}
+
+		bookID, err = tx.InsertBook(ctx, InsertBookParams{Title: "Book", AuthorID: authorID, Currency: "EUR"})
+		if err != nil {
+			return err
+		}
+
+		return tx.UpsertBookPrice(ctx, UpsertBookPriceParams{BookID: bookID, Currency: "USD", Amount: "1.50"})
+	})
+	if err != nil {
+		t.Fatalf("InTx() error = %v", err)
+	}
+
+	if n, err := repo.DeleteBook(ctx, DeleteBookParams{BookID: bookID}); err != nil || n != 1 {
+		t.Fatalf("DeleteBook() = %d, %v", n, err)
+	}
+
+	deleted, err := repo.ListDeletedBooks(ctx, ListDeletedBooksParams{PageLimit: 10})
+	if err != nil {
+		t.Fatalf("ListDeletedBooks() error = %v", err)
+	}
+	if len(deleted) != 1 || !deleted[0].DeletedAt.Valid || deleted[0].Version != 2 {
+		t.Fatalf("ListDeletedBooks() = %+v, want the deleted book", deleted)
+	}
+
+	// retention is not over yet
+	if n, err := repo.PurgeDeletedBooks(ctx, 3600); err != nil || n != 0 {
+		t.Fatalf("PurgeDeletedBooks(3600) = %d, %v", n, err)
+	}
+	if n, err := repo.PurgeDeletedBooks(ctx, -1); err != nil || n != 1 {
+		t.Fatalf("PurgeDeletedBooks(-1) = %d, %v", n, err)
+	}
+
+	prices, err := repo.ListBookPrices(ctx, []int32{bookID})
+	if err != nil {
+		t.Fatalf("ListBookPrices() error = %v", err)
+	}
+	if len(prices) != 0 {
+		t.Fatalf("ListBookPrices() = %+v, want prices purged with the book", prices)
+	}
+}
+
+func TestSearchBooksHighlightEscaped(t *testing.T) {
+	repos := map[string]BookRepository{
+		"memory": NewMemoryRepository(),
+		"sqlite": newSQLiteRepository(t),
+	}
+
+	for name, repo := range repos {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			err := repo.InTx(ctx, func(tx Tx) error {
+				authorID, err := tx.UpsertAuthor(ctx, UpsertAuthorParams{Name: "Author"})
+				if err != nil {
+					return err
+				}
+
+				_, err = tx.InsertBook(ctx, InsertBookParams{
+					Title:       `<script>alert(1)</script> Книга & co`,
+					AuthorID:    authorID,
+					Description: sql.NullString{String: `<img src=x onerror=alert(1)> описание`, Valid: true},
+				})
+				return err
+			})
+			if err != nil {
+				t.Fatalf("InTx() error = %v", err)
+			}
+
+			found, err := repo.SearchBooks(ctx, SearchBooksParams{Query: "книга", ResultLimit: 10})
+			if err != nil {
+				t.Fatalf("SearchBooks() error = %v", err)
+			}
+			if len(found) != 1 {
+				t.Fatalf("SearchBooks() = %+v, want the book", found)
+			}
+
+			title := found[0].TitleHighlight
+			if !strings.Contains(title, "&lt;script&gt;") || !strings.Contains(title, "<b>Книга</b>") ||
+				!strings.Contains(title, "&amp; co") || strings.Contains(title, "<script") {
+				t.Fatalf("TitleHighlight = %q, want escaped title with the match in <b>", title)
+			}
+
+			// a word of the description only, so that sqlite snippet comes from it
+			found, err = repo.SearchBooks(ctx, SearchBooksParams{Query: "описание", ResultLimit: 10})
+			if err != nil || len(found) != 1 {
+				t.Fatalf("SearchBooks() = %+v, %v, want the book", found, err)
+			}
+
+			snippet := found[0].Snippet
+			if !strings.Contains(snippet, "&lt;img") || !strings.Contains(snippet, "<b>описание</b>") || strings.Contains(snippet, "<img") {
+				t.Fatalf("Snippet = %q, want escaped description with the match in <b>", snippet)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package storagedb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// span attributes not covered by semantic conventions
+const (
+	rowsAffectedKey  = attribute.Key("db.rows_affected")
+	rowsReturnedKey  = attribute.Key("db.rows_returned")
+	statementArgsKey = attribute.Key("db.statement.args")
+)
+
+// redactedParam stands for every parameter unless DBOptions.TraceParams is set
+const redactedParam = "?"
+
+// sqlc puts the query name into the first line, e.g. -- name: ListBooks :many
+var queryName = regexp.MustCompile(`^--\s*name:\s*(\w+)`)
+
+// tracedDBTX makes a child span of the context one for every statement,
+// the queries are wrapped around it instead of the connection or transaction
+type tracedDBTX struct {
+	db           DBTX
+	tracer       trace.Tracer
+	system       attribute.KeyValue
+	redactParams bool
+}
+
+func newTracedDBTX(db DBTX, system attribute.KeyValue, opts DBOptions) *tracedDBTX {
+	provider := opts.TracerProvider
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+
+	return &tracedDBTX{
+		db:           db,
+		tracer:       provider.Tracer("storagedb"),
+		system:       system,
+		redactParams: !opts.TraceParams,
+	}
+}
+
+// withTx is the same tracing around a transaction
+func (t *tracedDBTX) withTx(tx *sql.Tx) *tracedDBTX {
+	return &tracedDBTX{
+		db:           tx,
+		tracer:       t.tracer,
+		system:       t.system,
+		redactParams: t.redactParams,
+	}
+}
+
+func (t *tracedDBTX) start(ctx context.Context, query string, args []interface{}) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		t.system,
+		semconv.DBStatement(query),
+	}
+
+	if len(args) > 0 {
+		values := make([]string, len(args))
+		for i, arg := range args {
+			values[i] = redactedParam
+			if !t.redactParams {
+				values[i] = paramString(arg)
+			}
+		}
+		attrs = append(attrs, statementArgsKey.StringSlice(values))
+	}
+
+	return t.tracer.Start(ctx, spanName(query),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+}
+
+// paramString formats arg as it is sent, e.g. sql.NullString as its string or NULL
+func paramString(arg interface{}) string {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return redactedParam
+		}
+		arg = v
+	}
+
+	if arg == nil {
+		return "NULL"
+	}
+
+	return fmt.Sprint(arg)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+	}
+	span.End()
+}
+
+// spanName is the sqlc query name or the first word of the statement
+func spanName(query string) string {
+	query = strings.TrimSpace(query)
+
+	if m := queryName.FindStringSubmatch(query); m != nil {
+		return m[1]
+	}
+
+	if fields := strings.Fields(query); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+
+	return "sql"
+}
+
+func (t *tracedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := t.start(ctx, query, args)
+
+	result, err := t.db.ExecContext(ctx, query, args...)
+	if err == nil {
+		if n, rowsErr := result.RowsAffected(); rowsErr == nil {
+			span.SetAttributes(rowsAffectedKey.Int64(n))
+		}
+	}
+
+	endSpan(span, err)
+	return result, err
+}
+
+func (t *tracedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := t.start(ctx, query, nil)
+
+	stmt, err := t.db.PrepareContext(ctx, query)
+
+	endSpan(span, err)
+	return stmt, err
+}
+
+// QueryContext spans of queries run by countRows are ended by it when the rows
+// are read, others end when the query is sent
+func (t *tracedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := t.start(ctx, query, args)
+
+	rows, err := t.db.QueryContext(ctx, query, args...)
+
+	if pending, ok := ctx.Value(pendingSpanKey{}).(*trace.Span); ok && err == nil && *pending == nil {
+		*pending = span
+		return rows, nil
+	}
+
+	endSpan(span, err)
+	return rows, err
+}
+
+func (t *tracedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := t.start(ctx, query, args)
+
+	row := t.db.QueryRowContext(ctx, query, args...)
+
+	endSpan(span, row.Err())
+	return row
+}
+
+// pendingSpanKey holds the span of the statement sent by the query countRows runs
+type pendingSpanKey struct{}
+
+// countRows runs a :many query keeping its statement span open until the rows
+// are read, the span ends with their number
+func countRows[T any](ctx context.Context, query func(ctx context.Context) ([]T, error)) ([]T, error) {
+	var span trace.Span
+
+	items, err := query(context.WithValue(ctx, pendingSpanKey{}, &span))
+
+	if span != nil {
+		if err == nil {
+			span.SetAttributes(rowsReturnedKey.Int(len(items)))
+		}
+		endSpan(span, err)
+	}
+
+	return items, err
+}
+
+// countingQuerier is Querier of the sql backends with the rows returned
+// by :many queries put into their statement spans
+type countingQuerier struct {
+	Querier
+}
+
+func (q countingQuerier) ListAuthors(ctx context.Context, arg ListAuthorsParams) ([]Author, error) {
+	return countRows(ctx, func(ctx context.Context) ([]Author, error) {
+		return q.Querier.ListAuthors(ctx, arg)
+	})
+}
+
+func (q countingQuerier) ListBooks(ctx context.Context, arg ListBooksParams) ([]BookDetail, error) {
+	return countRows(ctx, func(ctx context.Context) ([]BookDetail, error) {
+		return q.Querier.ListBooks(ctx, arg)
+	})
+}
+
+func (q countingQuerier) ListDeletedBooks(ctx context.Context, arg ListDeletedBooksParams) ([]BookDetail, error) {
+	return countRows(ctx, func(ctx context.Context) ([]BookDetail, error) {
+		return q.Querier.ListDeletedBooks(ctx, arg)
+	})
+}
+
+func (q countingQuerier) SearchBooks(ctx context.Context, arg SearchBooksParams) ([]SearchBooksRow, error) {
+	return countRows(ctx, func(ctx context.Context) ([]SearchBooksRow, error) {
+		return q.Querier.SearchBooks(ctx, arg)
+	})
+}
+
+func (q countingQuerier) ListBookPrices(ctx context.Context, bookIds []int32) ([]BookPrice, error) {
+	return countRows(ctx, func(ctx context.Context) ([]BookPrice, error) {
+		return q.Querier.ListBookPrices(ctx, bookIds)
+	})
+}
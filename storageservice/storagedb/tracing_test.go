@@ -0,0 +1,140 @@
+package storagedb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedQueries(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name        string
+		traceParams bool
+		wantArgs    []string
+	}{
+		{"redacted", false, []string{"?", "?"}},
+		{"with params", true, []string{"Author", "NULL"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			opts := DBOptions{
+				TracerProvider: tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder)),
+				TraceParams:    tt.traceParams,
+			}
+
+			repo, err := NewSQLiteRepository(ctx, "sqlite://"+filepath.Join(t.TempDir(), "books.db"), opts)
+			if err != nil {
+				t.Fatalf("NewSQLiteRepository() error = %v", err)
+			}
+			defer repo.Close()
+
+			migrateSQLite(t, repo)
+
+			err = repo.InTx(ctx, func(tx Tx) error {
+				return tx.Savepoint(ctx, func() error {
+					_, err := tx.UpsertAuthor(ctx, UpsertAuthorParams{Name: "Author"})
+					return err
+				})
+			})
+			if err != nil {
+				t.Fatalf("InTx() error = %v", err)
+			}
+
+			var names []string
+			var upsert tracesdk.ReadOnlySpan
+			for _, span := range recorder.Ended() {
+				names = append(names, span.Name())
+				if span.Name() == "UpsertAuthor" {
+					upsert = span
+				}
+			}
+
+			want := []string{"SAVEPOINT", "UpsertAuthor", "RELEASE"}
+			if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] || names[2] != want[2] {
+				t.Fatalf("spans = %v, want %v", names, want)
+			}
+
+			attrs := attribute.NewSet(upsert.Attributes()...)
+			if v, _ := attrs.Value("db.system"); v.AsString() != "sqlite" {
+				t.Errorf("db.system = %q, want sqlite", v.AsString())
+			}
+			if v, _ := attrs.Value("db.statement"); v.AsString() != sqliteUpsertAuthor {
+				t.Errorf("db.statement = %q, want %q", v.AsString(), sqliteUpsertAuthor)
+			}
+
+			v, _ := attrs.Value("db.statement.args")
+			args := v.AsStringSlice()
+			if len(args) != len(tt.wantArgs) || args[0] != tt.wantArgs[0] || args[1] != tt.wantArgs[1] {
+				t.Errorf("db.statement.args = %v, want %v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestTracedQueriesCountRows(t *testing.T) {
+	ctx := context.Background()
+
+	recorder := tracetest.NewSpanRecorder()
+	opts := DBOptions{TracerProvider: tracesdk.NewTracerProvider(tracesdk.WithSpanProcessor(recorder))}
+
+	repo, err := NewSQLiteRepository(ctx, "sqlite://"+filepath.Join(t.TempDir(), "books.db"), opts)
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository() error = %v", err)
+	}
+	defer repo.Close()
+
+	migrateSQLite(t, repo)
+
+	err = repo.InTx(ctx, func(tx Tx) error {
+		authorID, err := tx.UpsertAuthor(ctx, UpsertAuthorParams{Name: "Author"})
+		if err != nil {
+			return err
+		}
+
+		for _, title := range []string{"First", "Second", "Third"} {
+			if _, err := tx.InsertBook(ctx, InsertBookParams{Title: title, AuthorID: authorID, Currency: "EUR"}); err != nil {
+				return err
+			}
+		}
+
+		// queries of transactions are counted as well
+		_, err = tx.ListBooks(ctx, ListBooksParams{SortBy: "id", PageLimit: 2})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("InTx() error = %v", err)
+	}
+
+	if _, err := repo.ListBooks(ctx, ListBooksParams{SortBy: "id", PageLimit: 10}); err != nil {
+		t.Fatalf("ListBooks() error = %v", err)
+	}
+	if _, err := repo.SearchBooks(ctx, SearchBooksParams{Query: "nothing", ResultLimit: 10}); err != nil {
+		t.Fatalf("SearchBooks() error = %v", err)
+	}
+
+	var got []int64
+	for _, span := range recorder.Ended() {
+		if span.Name() != "ListBooks" && span.Name() != "SearchBooks" {
+			continue
+		}
+
+		attrs := attribute.NewSet(span.Attributes()...)
+		v, ok := attrs.Value("db.rows_returned")
+		if !ok {
+			t.Fatalf("%s span has no db.rows_returned, attributes %v", span.Name(), span.Attributes())
+		}
+		got = append(got, v.AsInt64())
+	}
+
+	// the page of the transaction, every book and no search results
+	want := []int64{2, 3, 0}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("db.rows_returned = %v, want %v", got, want)
+	}
+}
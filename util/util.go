@@ -1,6 +1,10 @@
 package util
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"time"
+)
 
 func CheckEnv(variableName, defaultValue string) string {
 	if cn, ok := os.LookupEnv(variableName); ok {
@@ -9,3 +13,18 @@ func CheckEnv(variableName, defaultValue string) string {
 
 	return defaultValue
 }
+
+// CheckEnvDuration parses variable as time.Duration, e.g. 24h or 90s
+func CheckEnvDuration(variableName string, defaultValue time.Duration) (time.Duration, error) {
+	v, ok := os.LookupEnv(variableName)
+	if !ok {
+		return defaultValue, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("wrong %s format: %w", variableName, err)
+	}
+
+	return d, nil
+}